@@ -3,10 +3,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/horos/holow-mcp/internal/database"
 	"github.com/horos/holow-mcp/internal/initcli"
@@ -18,49 +20,59 @@ func main() {
 	// Flags
 	initDB := flag.Bool("init", false, "Initialize databases with schemas")
 	initInteractive := flag.Bool("setup", false, "Run interactive setup wizard")
+	dryRun := flag.Bool("dry-run", false, "With -setup: show what setup would do without writing anything")
 	basePath := flag.String("path", "", "Base path for databases")
 	schemasPath := flag.String("schemas", "", "Path to schema SQL files")
 	showConfig := flag.Bool("config", false, "Show current configuration")
 	listCreds := flag.Bool("list-creds", false, "List configured credentials")
+	updateCred := flag.String("update-cred", "", "Update one provider's API key (from its env var, else prompted on stdin without echo)")
 	mcpStatus := flag.Bool("mcp-status", false, "Show MCP configuration status for AI clients")
 	sqlQuery := flag.String("sql", "", "Execute SQL query or start interactive shell (use -sql \"query\" or -sql alone)")
 	sqlDB := flag.String("db", "lifecycle-tools", "Database to query with -sql")
+	sqlWrite := flag.Bool("sql-write", false, "Allow write statements in the SQL shell (read-only by default)")
+	safeMode := flag.Bool("safe-mode", false, "Disable the browser tool and LLM generation actions (SQL and read tools only)")
+	idleShutdown := flag.Int("idle-shutdown-seconds", 0, "Shut down gracefully after this many seconds without a request (0 = never; default: server.idle_shutdown_seconds)")
+	exportToolsSchema := flag.Bool("export-tools-schema", false, "Print the browser, brainloop and SQL tool schemas as JSON")
+	migrationsStatus := flag.Bool("migrations-status", false, "Show each database's schema version and available, applied and pending migrations")
+	jsonOutput := flag.Bool("json", false, "With -config, -list-creds, -mcp-status or -migrations-status: print JSON instead of text")
+	healthcheck := flag.Bool("healthcheck", false, "Check databases and server heartbeat, print one status line and exit 0 (healthy) or 1")
+	exportBundle := flag.String("export-bundle", "", "Export the install (databases, config.json, manifest) to a portable .tar.gz bundle")
+	importBundle := flag.String("import-bundle", "", "Restore a bundle into -path and re-point MCP client configs to it")
 	flag.Parse()
 
-	// Déterminer le chemin de base
-	if *basePath == "" {
-		// Essayer de charger depuis config existante
-		home, _ := os.UserHomeDir()
-		defaultPath := filepath.Join(home, ".holow-mcp")
-
-		if initcli.ConfigExists(defaultPath) {
-			cfg, _ := initcli.LoadAppConfig(defaultPath)
-			if cfg != nil {
-				*basePath = cfg.BasePath
-			}
-		}
-
-		// Fallback
-		if *basePath == "" {
-			*basePath = defaultPath
+	// Mode statut MCP (indépendant du chemin de base)
+	if *mcpStatus {
+		if *jsonOutput {
+			printJSON(map[string]interface{}{"clients": initcli.MCPConfigStatus()})
+			return
 		}
+		initcli.PrintMCPConfigStatus()
+		return
 	}
 
 	// Mode setup interactif
 	if *initInteractive {
-		cfg, err := initcli.Run()
+		cfg, err := initcli.RunWithOptions(initcli.Options{DryRun: *dryRun})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Erreur setup: %v\n", err)
 			os.Exit(1)
 		}
 
+		if *dryRun {
+			fmt.Printf("    [DRY-RUN] would: écriture de %s\n", filepath.Join(cfg.BasePath, "config.json"))
+			fmt.Printf("    [DRY-RUN] would: initialisation des 6 bases HOLOW dans %s\n", cfg.BasePath)
+			fmt.Println("\n[DRY-RUN] Aperçu terminé: rien n'a été écrit")
+			return
+		}
+
 		// Sauvegarder la config
 		appCfg := &initcli.AppConfig{
-			BasePath:       cfg.BasePath,
-			CredentialsDB:  cfg.CredentialsDB,
-			BackupEnabled:  true,
-			BackupMaxCount: 5,
-			DebugPort:      9222,
+			BasePath:          cfg.BasePath,
+			CredentialsDB:     cfg.CredentialsDB,
+			CredentialBackend: cfg.CredentialBackend,
+			BackupEnabled:     true,
+			BackupMaxCount:    5,
+			DebugPort:         9222,
 		}
 		if err := initcli.SaveAppConfig(appCfg); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: impossible de sauvegarder config.json: %v\n", err)
@@ -71,6 +83,22 @@ func main() {
 		*initDB = true // Continuer vers l'init des schémas
 	}
 
+	// Déterminer le chemin de base (commun à tous les modes, créé uniquement en mode init)
+	// Les modes de consultation n'écrivent rien dans le dossier, pas même la sonde d'écriture
+	pathMode := initcli.BasePathWrite
+	switch {
+	case *initDB || *importBundle != "":
+		pathMode = initcli.BasePathCreate
+	case *showConfig || *listCreds || *healthcheck || *migrationsStatus:
+		pathMode = initcli.BasePathReadOnly
+	}
+	resolved, err := initcli.ResolveBasePath(*basePath, pathMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Chemin de base invalide: %v\n", err)
+		os.Exit(1)
+	}
+	*basePath = resolved
+
 	// Mode affichage config
 	if *showConfig {
 		cfg, err := initcli.LoadAppConfig(*basePath)
@@ -79,14 +107,32 @@ func main() {
 			os.Exit(1)
 		}
 
+		if *jsonOutput {
+			out := struct {
+				*initcli.AppConfig
+				KeyFingerprint string `json:"key_fingerprint,omitempty"`
+			}{AppConfig: cfg}
+			if cfg.CredentialBackend == "" {
+				cfg.CredentialBackend = initcli.BackendSQLite
+			}
+			if cfg.CredentialsAvailable() && cfg.CredentialBackend != initcli.BackendKeychain {
+				out.KeyFingerprint = initcli.KeyFingerprint(cfg.BasePath, cfg.CredentialsDB)
+			}
+			printJSON(out)
+			return
+		}
+
 		fmt.Printf("Configuration HOLOW-MCP:\n")
 		fmt.Printf("  Chemin: %s\n", cfg.BasePath)
 		fmt.Printf("  Base credentials: %s\n", cfg.CredentialsDB)
+		if cfg.CredentialBackend == initcli.BackendKeychain {
+			fmt.Printf("  Clés API: trousseau du système\n")
+		}
 		fmt.Printf("  Backup activé: %v\n", cfg.BackupEnabled)
 		fmt.Printf("  Backups max: %d\n", cfg.BackupMaxCount)
 		fmt.Printf("  Port CDP: %d\n", cfg.DebugPort)
 
-		if cfg.CredentialsAvailable() {
+		if cfg.CredentialsAvailable() && cfg.CredentialBackend != initcli.BackendKeychain {
 			fmt.Printf("  Fingerprint clé: %s\n", initcli.KeyFingerprint(cfg.BasePath, cfg.CredentialsDB))
 		}
 		return
@@ -106,6 +152,18 @@ func main() {
 			os.Exit(1)
 		}
 
+		if *jsonOutput {
+			creds := make([]map[string]string, 0, len(providers))
+			for _, p := range providers {
+				creds = append(creds, map[string]string{
+					"provider": p,
+					"hint":     initcli.CredentialHint(cfg.BasePath, cfg.CredentialsDB, p),
+				})
+			}
+			printJSON(map[string]interface{}{"credentials": creds})
+			return
+		}
+
 		fmt.Println("Credentials configurés:")
 		for _, p := range providers {
 			hint := initcli.CredentialHint(cfg.BasePath, cfg.CredentialsDB, p)
@@ -114,15 +172,89 @@ func main() {
 		return
 	}
 
-	// Mode statut MCP
-	if *mcpStatus {
-		initcli.PrintMCPConfigStatus()
+	// Mode mise à jour d'un credential
+	if *updateCred != "" {
+		cfg, err := initcli.LoadAppConfig(*basePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erreur chargement config: %v\n", err)
+			os.Exit(1)
+		}
+		if !cfg.CredentialsAvailable() {
+			fmt.Fprintf(os.Stderr, "Base credentials introuvable: %s (lancez d'abord: holow-mcp -setup)\n", cfg.CredentialsDBPath())
+			os.Exit(1)
+		}
+
+		key, source, err := initcli.ReadAPIKey(*updateCred)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		hint, err := initcli.UpdateCredential(cfg.BasePath, cfg.CredentialsDB, *updateCred, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erreur mise à jour credential: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Credential %s mis à jour depuis %s (%s)\n", *updateCred, source, hint)
+		return
+	}
+
+	// Mode export d'un bundle portable (utilisable serveur démarré)
+	if *exportBundle != "" {
+		manifest, err := initcli.ExportBundle(*basePath, *exportBundle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erreur export: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Bundle créé: %s (%d fichiers depuis %s)\n", *exportBundle, len(manifest.Files), manifest.SourcePath)
+		return
+	}
+
+	// Mode import d'un bundle dans -path
+	if *importBundle != "" {
+		result, err := initcli.ImportBundle(*importBundle, *basePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erreur import: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Bundle restauré dans %s (%d fichiers, exporté depuis %s)\n",
+			*basePath, len(result.Manifest.Files), result.Manifest.SourcePath)
+		if result.Credentials > 0 {
+			fmt.Printf("  Credentials migrés: %d\n", result.Credentials)
+		}
+		for _, path := range result.ClientConfigs {
+			fmt.Printf("  Config MCP repointée: %s\n", path)
+		}
+		return
+	}
+
+	// Mode export du catalogue de tools
+	if *exportToolsSchema {
+		if err := server.ExportToolsSchema(*basePath, *safeMode, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Export error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Mode sonde de santé (probes Docker/k8s/systemd, sans handshake MCP)
+	if *healthcheck {
+		if !initcli.ConfigExists(*basePath) {
+			fmt.Println("unhealthy: not initialized (run holow-mcp -setup)")
+			os.Exit(1)
+		}
+		status, err := server.HealthCheck(*basePath)
+		if err != nil {
+			fmt.Printf("unhealthy: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("healthy: %s\n", status)
 		return
 	}
 
 	// Mode SQL shell
 	if *sqlQuery != "" || isFlagPassed("sql") {
 		shell := sqlshell.New(*basePath)
+		shell.SetReadOnly(!*sqlWrite)
 		if *sqlQuery != "" {
 			// Exécuter une requête unique
 			if err := shell.Run(*sqlDB, *sqlQuery); err != nil {
@@ -155,6 +287,37 @@ func main() {
 		}
 	}
 
+	// Mode état des migrations (bases ouvertes en lecture seule, rien n'est appliqué)
+	if *migrationsStatus {
+		statuses := database.MigrationsStatusAt(*basePath, *schemasPath)
+		if *jsonOutput {
+			printJSON(map[string]interface{}{
+				"schema_version": database.SchemaVersion,
+				"schemas_path":   *schemasPath,
+				"databases":      statuses,
+			})
+			return
+		}
+
+		fmt.Printf("Migrations (version cible %d, schémas: %s):\n", database.SchemaVersion, *schemasPath)
+		for _, s := range statuses {
+			if s.Error != "" {
+				fmt.Printf("  %-20s erreur: %s\n", s.Database, s.Error)
+				continue
+			}
+			state := "à jour"
+			if !s.UpToDate {
+				state = "migration au prochain démarrage"
+			}
+			fmt.Printf("  %-20s version %d, %d disponible(s), %d en attente (%s)\n",
+				s.Database, s.Version, len(s.Available), len(s.Pending), state)
+			for _, name := range s.Pending {
+				fmt.Printf("    - %s\n", name)
+			}
+		}
+		return
+	}
+
 	// Mode init: créer les bases et initialiser les schémas
 	if *initDB {
 		dbManager, err := database.NewManager(*basePath, nil)
@@ -194,6 +357,18 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error creating server: %v\n", err)
 		os.Exit(1)
 	}
+	if *safeMode {
+		srv.SetSafeMode(true)
+	}
+	if srv.SafeMode() {
+		fmt.Fprintln(os.Stderr, "Safe mode: browser tool and LLM generation actions disabled")
+	}
+	if isFlagPassed("idle-shutdown-seconds") {
+		srv.SetIdleShutdown(time.Duration(*idleShutdown) * time.Second)
+	}
+	if idle := srv.IdleShutdown(); idle > 0 {
+		fmt.Fprintf(os.Stderr, "Idle shutdown: server stops after %s without a request\n", idle)
+	}
 
 	fmt.Fprintln(os.Stderr, "HOLOW-MCP server starting...")
 
@@ -206,6 +381,16 @@ func main() {
 	fmt.Fprintln(os.Stderr, "HOLOW-MCP server stopped")
 }
 
+// printJSON écrit v en JSON indenté sur stdout (modes -json)
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erreur JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
 // isFlagPassed vérifie si un flag a été passé (même sans valeur)
 func isFlagPassed(name string) bool {
 	found := false
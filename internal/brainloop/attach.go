@@ -0,0 +1,172 @@
+// Package brainloop - Actions d'administration de la whitelist ATTACH
+package brainloop
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// sqliteMagic en-tête de 16 octets de tout fichier SQLite 3
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// validDBTypes types de base acceptés dans allowed_attach_paths
+var validDBTypes = map[string]bool{
+	"input":     true,
+	"output":    true,
+	"lifecycle": true,
+	"metadata":  true,
+}
+
+// checkSQLiteFile vérifie qu'un chemin désigne un fichier SQLite existant
+// Retourne le chemin absolu nettoyé
+func checkSQLiteFile(path string) (string, error) {
+	absPath, err := filepath.Abs(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("database not found: %s", absPath)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("not a regular file: %s", absPath)
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open database: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteMagic))
+	if _, err := io.ReadFull(f, header); err != nil || !bytes.Equal(header, sqliteMagic) {
+		return "", fmt.Errorf("not a SQLite database: %s", absPath)
+	}
+
+	return absPath, nil
+}
+
+// attachList liste les chemins de la whitelist ATTACH
+func (m *ToolsManager) attachList() (interface{}, error) {
+	if m.coreDB == nil {
+		return nil, fmt.Errorf("core database not configured")
+	}
+
+	rows, err := m.coreDB.Query(`
+		SELECT worker_name, db_path, db_type, allowed, COALESCE(description, ''), added_at
+		FROM allowed_attach_paths ORDER BY worker_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attach paths: %w", err)
+	}
+	defer rows.Close()
+
+	paths := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var name, dbPath, dbType, desc string
+		var allowed int
+		var addedAt int64
+		if err := rows.Scan(&name, &dbPath, &dbType, &allowed, &desc, &addedAt); err != nil {
+			return nil, err
+		}
+		paths = append(paths, map[string]interface{}{
+			"name":        name,
+			"path":        dbPath,
+			"db_type":     dbType,
+			"allowed":     allowed == 1,
+			"description": desc,
+			"added_at":    addedAt,
+		})
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"action":  "attach_list",
+		"paths":   paths,
+		"count":   len(paths),
+	}, rows.Err()
+}
+
+// attachAllow ajoute ou réactive un chemin dans la whitelist ATTACH
+func (m *ToolsManager) attachAllow(args map[string]interface{}) (interface{}, error) {
+	if m.attach == nil {
+		return nil, fmt.Errorf("attach whitelist not configured")
+	}
+
+	name, _ := args["name"].(string)
+	path, _ := args["path"].(string)
+	if name == "" || path == "" {
+		return nil, fmt.Errorf("name and path are required for attach_allow")
+	}
+
+	dbType, _ := args["db_type"].(string)
+	if dbType == "" {
+		dbType = "output"
+	}
+	if !validDBTypes[dbType] {
+		return nil, fmt.Errorf("invalid db_type: %s (expected input, output, lifecycle or metadata)", dbType)
+	}
+
+	absPath, err := checkSQLiteFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, _ := args["description"].(string)
+
+	if err := m.attach.AddAllowedAttachPath(name, absPath, dbType, desc); err != nil {
+		return nil, fmt.Errorf("failed to allow attach path: %w", err)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"action":  "attach_allow",
+		"name":    name,
+		"path":    absPath,
+		"db_type": dbType,
+	}, nil
+}
+
+// attachDeny désactive un chemin de la whitelist ATTACH (par nom ou par chemin)
+// L'entrée est conservée avec allowed = 0 pour garder la trace de la décision
+func (m *ToolsManager) attachDeny(args map[string]interface{}) (interface{}, error) {
+	if m.coreDB == nil {
+		return nil, fmt.Errorf("core database not configured")
+	}
+
+	name, _ := args["name"].(string)
+	path, _ := args["path"].(string)
+
+	var res sql.Result
+	var err error
+	switch {
+	case name != "":
+		res, err = m.coreDB.Exec(`UPDATE allowed_attach_paths SET allowed = 0 WHERE worker_name = ?`, name)
+	case path != "":
+		absPath, absErr := filepath.Abs(filepath.Clean(path))
+		if absErr != nil {
+			return nil, fmt.Errorf("invalid path: %w", absErr)
+		}
+		res, err = m.coreDB.Exec(`UPDATE allowed_attach_paths SET allowed = 0 WHERE db_path = ?`, absPath)
+	default:
+		return nil, fmt.Errorf("name or path is required for attach_deny")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to deny attach path: %w", err)
+	}
+
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return nil, fmt.Errorf("attach path not found in whitelist")
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"action":  "attach_deny",
+		"denied":  affected,
+	}, nil
+}
@@ -0,0 +1,141 @@
+// Package brainloop - Métriques de code agrégées par langage (count_lines)
+package brainloop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// codeExcludeDirs dossiers ignorés par search_code et count_lines (en plus des dossiers cachés)
+var codeExcludeDirs = map[string]bool{
+	"bin": true, ".git": true, "node_modules": true, "vendor": true,
+	"dist": true, "build": true, "__pycache__": true,
+}
+
+// maxCodeFileSize taille au-delà de laquelle un fichier n'est pas lu (search_code, count_lines)
+const maxCodeFileSize = 1024 * 1024
+
+// isBinary détecte un octet nul dans les 512 premiers octets
+func isBinary(content []byte) bool {
+	if len(content) > 512 {
+		content = content[:512]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// langStats compteurs d'un langage
+type langStats struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Lines    int    `json:"lines"`
+	Blank    int    `json:"blank"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// countLines parcourt un dossier et totalise fichiers, lignes et octets par langage
+func (m *ToolsManager) countLines(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	basePath := "."
+	if bp, ok := args["path"].(string); ok && bp != "" {
+		basePath = bp
+	}
+	validBasePath, err := validatePath(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base path: %w", err)
+	}
+	basePath = validBasePath
+
+	filePattern := "*"
+	if fp, ok := args["pattern"].(string); ok && fp != "" {
+		filePattern = fp
+	}
+
+	byLang := make(map[string]*langStats)
+	var total langStats
+	skipped := 0
+
+	walkErr := walkContext(ctx, basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != basePath && (codeExcludeDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if matched, _ := filepath.Match(filePattern, info.Name()); !matched {
+			return nil
+		}
+		if info.Size() > maxCodeFileSize {
+			skipped++
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil || isBinary(content) {
+			skipped++
+			return nil
+		}
+
+		lines, blank := 0, 0
+		if len(content) > 0 {
+			for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+				lines++
+				if strings.TrimSpace(line) == "" {
+					blank++
+				}
+			}
+		}
+
+		lang := detectLanguage(strings.ToLower(filepath.Ext(path)))
+		stats, ok := byLang[lang]
+		if !ok {
+			stats = &langStats{Language: lang}
+			byLang[lang] = stats
+		}
+		for _, s := range []*langStats{stats, &total} {
+			s.Files++
+			s.Lines += lines
+			s.Blank += blank
+			s.Bytes += int64(len(content))
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	// Langages par nombre de lignes décroissant
+	languages := make([]*langStats, 0, len(byLang))
+	for _, stats := range byLang {
+		languages = append(languages, stats)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i].Lines != languages[j].Lines {
+			return languages[i].Lines > languages[j].Lines
+		}
+		return languages[i].Language < languages[j].Language
+	})
+
+	return map[string]interface{}{
+		"success":   true,
+		"action":    "count_lines",
+		"base_path": basePath,
+		"totals": map[string]interface{}{
+			"files": total.Files,
+			"lines": total.Lines,
+			"blank": total.Blank,
+			"bytes": total.Bytes,
+		},
+		"languages": languages,
+		"skipped":   skipped,
+	}, nil
+}
@@ -0,0 +1,158 @@
+// Package brainloop - Taille des bases SQLite et de leurs tables (db_stats)
+package brainloop
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/horos/holow-mcp/internal/database"
+)
+
+// dbStats rapporte la taille d'une base (path, ouverte en lecture seule) ou des 6 bases holow:
+// fichier, WAL, pages libres et, par table, lignes et octets (dbstat, index compris), triés par taille
+func (m *ToolsManager) dbStats(args map[string]interface{}) (interface{}, error) {
+	var dbs []database.NamedDB
+	if path, _ := args["path"].(string); path != "" {
+		validPath, err := validatePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		db, err := database.OpenReadOnly(validPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+		dbs = []database.NamedDB{{Name: validPath, DB: db}}
+	} else {
+		dbs = m.holowDBs
+		if len(dbs) == 0 {
+			return nil, fmt.Errorf("holow databases not configured, path is required for db_stats")
+		}
+	}
+
+	var reports []map[string]interface{}
+	var totalBytes int64
+	for _, named := range dbs {
+		report, size, err := sqliteStats(named.DB)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", named.Name, err)
+		}
+		report["name"] = named.Name
+		reports = append(reports, report)
+		totalBytes += size
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i]["total_bytes"].(int64) > reports[j]["total_bytes"].(int64)
+	})
+
+	return map[string]interface{}{
+		"success":     true,
+		"action":      "db_stats",
+		"databases":   reports,
+		"total_bytes": totalBytes,
+	}, nil
+}
+
+// sqliteStats mesure une base ouverte; size = fichier + WAL (pages si la base est en mémoire)
+func sqliteStats(db *sql.DB) (map[string]interface{}, int64, error) {
+	var file string
+	if err := db.QueryRow(`SELECT file FROM pragma_database_list WHERE name = 'main'`).Scan(&file); err != nil {
+		return nil, 0, err
+	}
+	var pageSize, pageCount, freePages int64
+	db.QueryRow(`PRAGMA page_size`).Scan(&pageSize)
+	db.QueryRow(`PRAGMA page_count`).Scan(&pageCount)
+	db.QueryRow(`PRAGMA freelist_count`).Scan(&freePages)
+
+	report := map[string]interface{}{
+		"page_size":  pageSize,
+		"pages":      pageCount,
+		"free_bytes": freePages * pageSize,
+	}
+
+	size := pageCount * pageSize
+	if file != "" {
+		report["file"] = file
+		if info, err := os.Stat(file); err == nil {
+			size = info.Size()
+		}
+		var walSize int64
+		if info, err := os.Stat(file + "-wal"); err == nil {
+			walSize = info.Size()
+		}
+		report["wal_bytes"] = walSize
+		size += walSize
+	} else {
+		report["in_memory"] = true
+	}
+	report["total_bytes"] = size
+
+	tables, dbstat, err := tableStats(db)
+	if err != nil {
+		return nil, 0, err
+	}
+	report["tables"] = tables
+	report["dbstat"] = dbstat
+	return report, size, nil
+}
+
+// tableStats compte les lignes de chaque table et, si le module dbstat est disponible,
+// les octets occupés par la table et ses index; triées par octets (sinon par lignes) décroissants
+func tableStats(db *sql.DB) ([]map[string]interface{}, bool, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, false, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+
+	sizes := map[string]int64{}
+	dbstat := true
+	sizeRows, err := db.Query(`
+		SELECT m.tbl_name, SUM(s.pgsize)
+		FROM dbstat s JOIN sqlite_master m ON m.name = s.name
+		GROUP BY m.tbl_name`)
+	if err != nil {
+		dbstat = false // Module dbstat absent de cette build SQLite
+	} else {
+		for sizeRows.Next() {
+			var name string
+			var bytes int64
+			if err := sizeRows.Scan(&name, &bytes); err == nil {
+				sizes[name] = bytes
+			}
+		}
+		sizeRows.Close()
+	}
+
+	tables := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		table := map[string]interface{}{"name": name}
+		var count int64
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + quoteIdent(name)).Scan(&count); err != nil {
+			table["error"] = err.Error() // Table virtuelle dont le module est absent
+		}
+		table["rows"] = count
+		if dbstat {
+			table["bytes"] = sizes[name]
+		}
+		tables = append(tables, table)
+	}
+
+	key := "rows"
+	if dbstat {
+		key = "bytes"
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i][key].(int64) > tables[j][key].(int64)
+	})
+	return tables, dbstat, nil
+}
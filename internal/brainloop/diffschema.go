@@ -0,0 +1,374 @@
+// Package brainloop - Comparaison des schémas de deux bases SQLite (diff_schema)
+package brainloop
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/horos/holow-mcp/internal/chromium"
+	"github.com/horos/holow-mcp/internal/database"
+)
+
+// schemaObject table, index, vue ou trigger de sqlite_master
+type schemaObject struct {
+	Type    string
+	Name    string
+	Table   string
+	SQL     string
+	Columns []string // Tables: définitions de colonnes; index: colonnes indexées
+	names   []string // Tables: noms des colonnes, dans l'ordre de Columns
+}
+
+// diffSchema compare le schéma de path à celui de other_path (base SQLite ou script .sql):
+// added = objets présents seulement dans other_path, removed = seulement dans path,
+// changed = colonnes ou définition différentes
+func (m *ToolsManager) diffSchema(args map[string]interface{}) (interface{}, error) {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("path is required for diff_schema")
+	}
+	otherPath, ok := args["other_path"].(string)
+	if !ok || otherPath == "" {
+		return nil, fmt.Errorf("other_path is required for diff_schema")
+	}
+
+	from, fromPath, err := loadSchema(path)
+	if err != nil {
+		return nil, err
+	}
+	to, toPath, err := loadSchema(otherPath)
+	if err != nil {
+		return nil, err
+	}
+
+	added := []map[string]interface{}{}
+	removed := []map[string]interface{}{}
+	changed := []map[string]interface{}{}
+	for _, key := range sortedKeys(from, to) {
+		a, inFrom := from[key]
+		b, inTo := to[key]
+		switch {
+		case !inFrom:
+			added = append(added, b.summary())
+		case !inTo:
+			removed = append(removed, a.summary())
+		default:
+			if diff := diffObjects(a, b); diff != nil {
+				changed = append(changed, diff)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"success":    true,
+		"action":     "diff_schema",
+		"path":       fromPath,
+		"other_path": toPath,
+		"identical":  len(added)+len(removed)+len(changed) == 0,
+		"added":      added,
+		"removed":    removed,
+		"changed":    changed,
+		"summary": map[string]interface{}{
+			"added":   len(added),
+			"removed": len(removed),
+			"changed": len(changed),
+		},
+	}, nil
+}
+
+// loadSchema lit le schéma d'une base (ouverte en lecture seule) ou d'un script .sql
+// dont seules les instructions CREATE, ALTER et DROP sont exécutées dans une base en mémoire
+func loadSchema(path string) (map[string]*schemaObject, string, error) {
+	validPath, err := validatePath(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	var db *sql.DB
+	if strings.EqualFold(filepath.Ext(validPath), ".sql") {
+		script, err := os.ReadFile(validPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read schema file: %w", err)
+		}
+		statements, err := schemaStatements(string(script))
+		if err != nil {
+			return nil, "", fmt.Errorf("schema file %s rejected: %w", validPath, err)
+		}
+		db, err = sql.Open("sqlite", ":memory:")
+		if err != nil {
+			return nil, "", err
+		}
+		// Une seule connexion: chaque connexion :memory: est une base distincte
+		db.SetMaxOpenConns(1)
+		for _, stmt := range statements {
+			if _, err := db.Exec(stmt); err != nil {
+				db.Close()
+				return nil, "", fmt.Errorf("failed to execute schema file %s: %w", validPath, err)
+			}
+		}
+	} else {
+		db, err = database.OpenReadOnly(validPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open database: %w", err)
+		}
+	}
+	defer db.Close()
+
+	objects, err := schemaObjects(db)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read schema of %s: %w", validPath, err)
+	}
+	return objects, validPath, nil
+}
+
+// schemaKeywords instructions d'un script .sql exécutées par diff_schema
+// Les autres (PRAGMA, INSERT, SELECT...) n'ont pas d'effet sur le schéma et sont ignorées
+var schemaKeywords = map[string]bool{"CREATE": true, "ALTER": true, "DROP": true}
+
+// schemaStatements retient les instructions CREATE, ALTER et DROP d'un script
+// ATTACH/DETACH (création de fichiers) et les fonctions CDP (CREATE TABLE ... AS SELECT cdp_call(...)) sont refusés
+func schemaStatements(script string) ([]string, error) {
+	var statements []string
+	for _, stmt := range database.SplitStatements(script) {
+		code := stripSQLLiterals(stmt)
+		keyword := ""
+		if fields := strings.Fields(code); len(fields) > 0 {
+			keyword = strings.ToUpper(fields[0])
+		}
+		switch {
+		case keyword == "ATTACH" || keyword == "DETACH":
+			return nil, fmt.Errorf("%s is not allowed", keyword)
+		case !schemaKeywords[keyword]:
+			continue
+		case chromium.CallsCDP(code):
+			return nil, fmt.Errorf("CDP functions are not allowed in %s statements", keyword)
+		}
+		statements = append(statements, stmt)
+	}
+	return statements, nil
+}
+
+// stripSQLLiterals remplace chaînes, identifiants quotés et commentaires par des espaces
+// pour n'analyser que le code SQL d'une instruction
+func stripSQLLiterals(stmt string) string {
+	var sb strings.Builder
+	sb.Grow(len(stmt))
+	for i := 0; i < len(stmt); i++ {
+		if end := database.SkipLiteral(stmt, i); end > i {
+			sb.WriteByte(' ')
+			i = end - 1
+			continue
+		}
+		sb.WriteByte(stmt[i])
+	}
+	return sb.String()
+}
+
+// schemaObjects indexe les objets du schéma par "type:nom"
+// Les objets internes (sqlite_*) et les index implicites (UNIQUE, PRIMARY KEY) sont ignorés:
+// ils découlent de la définition de leur table
+func schemaObjects(db *sql.DB) (map[string]*schemaObject, error) {
+	rows, err := db.Query(`
+		SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE name NOT LIKE 'sqlite_%' AND sql IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	var list []*schemaObject
+	for rows.Next() {
+		o := &schemaObject{}
+		if err := rows.Scan(&o.Type, &o.Name, &o.Table, &o.SQL); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]*schemaObject, len(list))
+	for _, o := range list {
+		switch o.Type {
+		case "table":
+			// Table virtuelle dont le module est absent: comparée sur sa seule définition SQL
+			o.names, o.Columns, _ = tableColumns(db, o.Name)
+		case "index":
+			if o.Columns, err = indexColumns(db, o.Name); err != nil {
+				return nil, err
+			}
+		}
+		objects[o.Type+":"+o.Name] = o
+	}
+	return objects, nil
+}
+
+// tableColumns retourne les noms des colonnes et leurs définitions comme dans un CREATE TABLE:
+// nom, type, NOT NULL, DEFAULT, PRIMARY KEY
+func tableColumns(db *sql.DB, table string) (names, defs []string, err error) {
+	rows, err := db.Query(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_xinfo(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, colType string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, nil, err
+		}
+		def := name
+		if colType != "" {
+			def += " " + colType
+		}
+		if notNull != 0 {
+			def += " NOT NULL"
+		}
+		if dflt.Valid {
+			def += " DEFAULT " + dflt.String
+		}
+		if pk > 0 {
+			def += " PRIMARY KEY"
+		}
+		names = append(names, name)
+		defs = append(defs, def)
+	}
+	return names, defs, rows.Err()
+}
+
+// indexColumns liste les colonnes (ou expressions) d'un index, dans l'ordre
+func indexColumns(db *sql.DB, index string) ([]string, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_index_info(?) ORDER BY seqno`, index)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name sql.NullString
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if name.Valid {
+			columns = append(columns, name.String)
+		} else {
+			columns = append(columns, "<expression>")
+		}
+	}
+	return columns, rows.Err()
+}
+
+// summary identifie un objet dans added/removed
+func (o *schemaObject) summary() map[string]interface{} {
+	s := map[string]interface{}{"type": o.Type, "name": o.Name}
+	if o.Type != "table" && o.Type != "view" {
+		s["table"] = o.Table
+	}
+	if len(o.Columns) > 0 {
+		s["columns"] = o.Columns
+	}
+	return s
+}
+
+// diffObjects décrit les différences entre deux versions d'un objet (nil si identiques)
+// Tables: colonnes ajoutées, supprimées ou modifiées, puis définition (contraintes) à colonnes égales
+func diffObjects(a, b *schemaObject) map[string]interface{} {
+	diff := map[string]interface{}{"type": a.Type, "name": a.Name}
+	differs := false
+
+	if a.Type == "table" {
+		fromCols, toCols := a.columnsByName(), b.columnsByName()
+		var colsAdded, colsRemoved []string
+		var colsChanged []map[string]interface{}
+		for i, name := range b.names {
+			def := b.Columns[i]
+			if prev, ok := fromCols[name]; !ok {
+				colsAdded = append(colsAdded, def)
+			} else if prev != def {
+				colsChanged = append(colsChanged, map[string]interface{}{"name": name, "from": prev, "to": def})
+			}
+		}
+		for i, name := range a.names {
+			if _, ok := toCols[name]; !ok {
+				colsRemoved = append(colsRemoved, a.Columns[i])
+			}
+		}
+		if colsAdded != nil {
+			diff["columns_added"] = colsAdded
+		}
+		if colsRemoved != nil {
+			diff["columns_removed"] = colsRemoved
+		}
+		if colsChanged != nil {
+			diff["columns_changed"] = colsChanged
+		}
+		differs = colsAdded != nil || colsRemoved != nil || colsChanged != nil
+	}
+
+	if a.Type == "index" && strings.Join(a.Columns, ",") != strings.Join(b.Columns, ",") {
+		diff["columns_from"], diff["columns_to"] = a.Columns, b.Columns
+		differs = true
+	}
+	if a.Table != b.Table {
+		diff["table_from"], diff["table_to"] = a.Table, b.Table
+		differs = true
+	}
+
+	// Définition SQL: seul indicateur pour vues et triggers, contraintes et clauses pour tables et index
+	if !differs && normalizeSchemaSQL(a.SQL) != normalizeSchemaSQL(b.SQL) {
+		differs = true
+	}
+	if !differs {
+		return nil
+	}
+	diff["sql_from"], diff["sql_to"] = a.SQL, b.SQL
+	return diff
+}
+
+// columnsByName indexe les définitions de colonnes d'une table par nom
+func (o *schemaObject) columnsByName() map[string]string {
+	byName := make(map[string]string, len(o.names))
+	for i, name := range o.names {
+		byName[name] = o.Columns[i]
+	}
+	return byName
+}
+
+var (
+	schemaSpaceRegex       = regexp.MustCompile(`\s+`)
+	schemaIfNotExistsRegex = regexp.MustCompile(`(?i)\s+IF\s+NOT\s+EXISTS`)
+	schemaPunctSpaceRegex  = regexp.MustCompile(`\s*([(),])\s*`)
+)
+
+// normalizeSchemaSQL ignore les écarts de mise en forme entre deux définitions équivalentes:
+// espaces, retours à la ligne, IF NOT EXISTS et casse
+func normalizeSchemaSQL(def string) string {
+	def = schemaIfNotExistsRegex.ReplaceAllString(def, "")
+	def = schemaSpaceRegex.ReplaceAllString(strings.TrimSpace(def), " ")
+	def = schemaPunctSpaceRegex.ReplaceAllString(def, "$1")
+	return strings.ToLower(strings.TrimRight(def, "; "))
+}
+
+// sortedKeys retourne l'union triée des clés de deux schémas
+func sortedKeys(a, b map[string]*schemaObject) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,95 @@
+package brainloop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    int // Instructions retenues
+		wantErr string
+	}{
+		{
+			name: "ddl kept, other statements skipped",
+			script: `PRAGMA journal_mode = WAL;
+				-- table principale
+				CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT);
+				INSERT INTO t (v) VALUES ('x');
+				CREATE INDEX idx_t ON t(v);
+				ALTER TABLE t ADD COLUMN w TEXT;
+				SELECT 1;
+				DROP INDEX idx_t;`,
+			want: 4,
+		},
+		{
+			name:   "trigger body is not split",
+			script: `CREATE TABLE t (v TEXT); CREATE TRIGGER tr AFTER INSERT ON t BEGIN INSERT INTO t VALUES ('a'); END;`,
+			want:   2,
+		},
+		{
+			name:   "cdp call inside a string literal",
+			script: `CREATE TABLE t (v TEXT DEFAULT 'SELECT cdp_call(1, 2)');`,
+			want:   1,
+		},
+		{
+			name:    "attach rejected",
+			script:  `CREATE TABLE t (v TEXT); ATTACH DATABASE '/tmp/x.db' AS x;`,
+			wantErr: "ATTACH is not allowed",
+		},
+		{
+			name:    "attach after comment rejected",
+			script:  `/* setup */ attach '/tmp/x.db' as x;`,
+			wantErr: "ATTACH is not allowed",
+		},
+		{
+			name:    "detach rejected",
+			script:  `DETACH x;`,
+			wantErr: "DETACH is not allowed",
+		},
+		{
+			name:    "cdp call in create as select",
+			script:  `CREATE TABLE t AS SELECT CDP_CALL('Page.navigate', '{}') AS r;`,
+			wantErr: "CDP functions are not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schemaStatements(tt.script)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("kept %d statements, want %d: %q", len(got), tt.want, got)
+			}
+		})
+	}
+}
+
+// Les schémas du dépôt (PRAGMA, INSERT de valeurs initiales, tools CDP en littéraux) restent comparables
+func TestSchemaStatementsRepoSchemas(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "schemas", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no schema files: %v", err)
+	}
+	for _, file := range files {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := schemaStatements(string(script)); err != nil {
+			t.Errorf("%s: %v", filepath.Base(file), err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package brainloop - Action explain (EXPLAIN QUERY PLAN en lecture seule)
+package brainloop
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// explainKeywords premiers mots-clés acceptés par explain (lectures uniquement)
+var explainKeywords = map[string]bool{
+	"SELECT": true,
+	"WITH":   true,
+	"VALUES": true,
+}
+
+// explainPrefixRegex retire un EXPLAIN [QUERY PLAN] déjà présent dans la requête
+var explainPrefixRegex = regexp.MustCompile(`(?i)^\s*EXPLAIN(\s+QUERY\s+PLAN)?\s+`)
+
+// writeKeywordRegex détecte une écriture cachée dans une CTE (WITH ... DELETE)
+var writeKeywordRegex = regexp.MustCompile(`(?i)\b(?:INSERT|UPDATE|DELETE)\b|\bREPLACE\s+INTO\b`)
+
+// fullScanRegex reconnaît un parcours de table complet ("SCAN t" ou "SCAN TABLE t")
+var fullScanRegex = regexp.MustCompile(`^SCAN (?:TABLE )?(\S+)`)
+
+// explainDB résout le nom de base passé à explain (lifecycle-tools par défaut)
+func (m *ToolsManager) explainDB(name string) (*sql.DB, string, error) {
+	var db *sql.DB
+	switch name {
+	case "", "lifecycle-tools":
+		db, name = m.toolsDB, "lifecycle-tools"
+	case "lifecycle-execution":
+		db = m.execDB
+	case "lifecycle-core":
+		db = m.coreDB
+	default:
+		return nil, "", fmt.Errorf("unknown db: %s (expected lifecycle-tools, lifecycle-execution or lifecycle-core)", name)
+	}
+	if db == nil {
+		return nil, "", fmt.Errorf("%s database not configured", name)
+	}
+	return db, name, nil
+}
+
+// checkExplainSQL n'accepte qu'une seule requête de lecture
+func checkExplainSQL(query string) error {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	if trimmed == "" {
+		return fmt.Errorf("sql is required for explain")
+	}
+	if strings.Contains(trimmed, ";") {
+		return fmt.Errorf("only a single statement can be explained")
+	}
+
+	keyword := strings.ToUpper(strings.TrimRight(strings.Fields(trimmed)[0], "("))
+	if !explainKeywords[keyword] {
+		return fmt.Errorf("explain is read-only: %s statements are not allowed", keyword)
+	}
+	if keyword == "WITH" {
+		if w := writeKeywordRegex.FindString(trimmed); w != "" {
+			return fmt.Errorf("explain is read-only: %s statements are not allowed", strings.ToUpper(strings.Fields(w)[0]))
+		}
+	}
+	return nil
+}
+
+// explain exécute EXPLAIN QUERY PLAN et signale les parcours complets de table
+func (m *ToolsManager) explain(args map[string]interface{}) (interface{}, error) {
+	query, _ := args["sql"].(string)
+	query = explainPrefixRegex.ReplaceAllString(query, "")
+	if err := checkExplainSQL(query); err != nil {
+		return nil, err
+	}
+
+	dbName, _ := args["db"].(string)
+	db, dbName, err := m.explainDB(dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("EXPLAIN QUERY PLAN " + query)
+	if err != nil {
+		return nil, fmt.Errorf("explain failed: %w", err)
+	}
+	defer rows.Close()
+
+	plan := []map[string]interface{}{}
+	fullScans := []string{}
+	tempBTrees := 0
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			return nil, err
+		}
+		plan = append(plan, map[string]interface{}{
+			"id":     id,
+			"parent": parent,
+			"detail": detail,
+		})
+
+		if match := fullScanRegex.FindStringSubmatch(detail); match != nil && !strings.Contains(detail, "INDEX") {
+			fullScans = append(fullScans, match[1])
+		}
+		if strings.Contains(detail, "USE TEMP B-TREE") {
+			tempBTrees++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	hints := []string{}
+	for _, table := range fullScans {
+		hints = append(hints, fmt.Sprintf("Full scan of %s: consider an index on the filtered/joined columns", table))
+	}
+	if tempBTrees > 0 {
+		hints = append(hints, "Temporary B-tree used for ORDER BY/GROUP BY/DISTINCT: an index matching the sort order avoids it")
+	}
+
+	return map[string]interface{}{
+		"success":          true,
+		"action":           "explain",
+		"db":               dbName,
+		"sql":              query,
+		"plan":             plan,
+		"full_table_scans": fullScans,
+		"hints":            hints,
+	}, nil
+}
@@ -0,0 +1,148 @@
+// Package brainloop - Empreintes d'arborescences pour la détection de changements
+package brainloop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxHashTreeFiles limite le nombre de fichiers hachés par appel
+const maxHashTreeFiles = 10000
+
+// hashFile calcule le SHA-256 d'un fichier en streaming
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashTree calcule une empreinte par fichier et une empreinte globale de l'arborescence
+// Avec snapshot (résultat files d'un appel précédent), liste les fichiers ajoutés/supprimés/modifiés
+func (m *ToolsManager) hashTree(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	basePath, ok := args["path"].(string)
+	if !ok || basePath == "" {
+		return nil, fmt.Errorf("path is required for hash_tree")
+	}
+
+	validBasePath, err := validatePath(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base path: %w", err)
+	}
+	basePath = validBasePath
+
+	filePattern := "*"
+	if fp, ok := args["pattern"].(string); ok && fp != "" {
+		filePattern = fp
+	}
+
+	files := make(map[string]string)
+	truncated := false
+
+	walkErr := walkContext(ctx, basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			// Mêmes règles d'exclusion que list_files
+			base := filepath.Base(path)
+			if path != basePath && (strings.HasPrefix(base, ".") || base == "node_modules" || base == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		if matched, _ := filepath.Match(filePattern, filepath.Base(path)); !matched {
+			return nil
+		}
+
+		if len(files) >= maxHashTreeFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+
+		sum, err := hashFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(basePath, path)
+		if err != nil {
+			rel = path
+		}
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	// Empreinte globale: chemins triés pour un résultat déterministe
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var manifest strings.Builder
+	for _, p := range paths {
+		manifest.WriteString(p)
+		manifest.WriteString(":")
+		manifest.WriteString(files[p])
+		manifest.WriteString("\n")
+	}
+
+	result := map[string]interface{}{
+		"success":    true,
+		"action":     "hash_tree",
+		"base_path":  basePath,
+		"tree_hash":  hashContent(manifest.String()),
+		"file_count": len(files),
+		"files":      files,
+		"truncated":  truncated,
+	}
+
+	if snapshot, ok := args["snapshot"].(map[string]interface{}); ok {
+		added, removed, changed := []string{}, []string{}, []string{}
+		for _, p := range paths {
+			prev, existed := snapshot[p].(string)
+			switch {
+			case !existed:
+				added = append(added, p)
+			case prev != files[p]:
+				changed = append(changed, p)
+			}
+		}
+		for p := range snapshot {
+			if _, exists := files[p]; !exists {
+				removed = append(removed, p)
+			}
+		}
+		sort.Strings(removed)
+
+		result["diff"] = map[string]interface{}{
+			"added":     added,
+			"removed":   removed,
+			"changed":   changed,
+			"unchanged": len(added) == 0 && len(removed) == 0 && len(changed) == 0,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,33 @@
+// Package brainloop - État des migrations de schéma des bases holow (migrations_status)
+package brainloop
+
+import (
+	"fmt"
+
+	"github.com/horos/holow-mcp/internal/database"
+)
+
+// migrationsStatus rapporte, par base, user_version et les migrations disponibles, appliquées et en attente
+func (m *ToolsManager) migrationsStatus() (interface{}, error) {
+	if len(m.holowDBs) == 0 {
+		return nil, fmt.Errorf("holow databases not configured")
+	}
+	if m.schemas == "" {
+		return nil, fmt.Errorf("schemas path not configured")
+	}
+
+	statuses := database.MigrationsStatus(m.holowDBs, m.schemas)
+	pending := 0
+	for _, s := range statuses {
+		pending += len(s.Pending)
+	}
+
+	return map[string]interface{}{
+		"success":        true,
+		"action":         "migrations_status",
+		"schema_version": database.SchemaVersion,
+		"schemas_path":   m.schemas,
+		"databases":      statuses,
+		"pending":        pending,
+	}, nil
+}
@@ -0,0 +1,58 @@
+// Package brainloop - Mode restreint: actions de génération (appels LLM sortants) désactivées
+package brainloop
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// generationActions actions qui appellent un provider LLM
+var generationActions = map[string]bool{
+	"generate_file": true,
+	"generate_sql":  true,
+	"explore":       true,
+	"loop":          true,
+}
+
+// generationSummary segment de la description du tool listant les actions de génération
+const generationSummary = "generate_file, generate_sql, explore, loop (generation); "
+
+// SetSafeMode masque et refuse les actions de génération
+// Les actions système, de lecture et de discovery restent disponibles
+func (m *ToolsManager) SetSafeMode(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&m.safeMode, v)
+}
+
+// SafeMode indique si les actions de génération sont désactivées
+func (m *ToolsManager) SafeMode() bool {
+	return atomic.LoadInt32(&m.safeMode) == 1
+}
+
+// checkSafeMode refuse une action de génération en mode restreint
+func (m *ToolsManager) checkSafeMode(action string) error {
+	if generationActions[action] && m.SafeMode() {
+		return fmt.Errorf("action %s is disabled in safe mode", action)
+	}
+	return nil
+}
+
+// withoutGeneration retire les actions de génération de la définition du tool brainloop
+func withoutGeneration(def map[string]interface{}) {
+	if desc, ok := def["description"].(string); ok {
+		def["description"] = strings.Replace(desc, generationSummary, "", 1)
+	}
+	action := def["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{})["action"].(map[string]interface{})
+	enum := action["enum"].([]string)
+	kept := make([]string, 0, len(enum))
+	for _, name := range enum {
+		if !generationActions[name] {
+			kept = append(kept, name)
+		}
+	}
+	action["enum"] = kept
+}
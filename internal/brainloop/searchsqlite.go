@@ -0,0 +1,195 @@
+// Package brainloop - Recherche d'une valeur dans les données d'une base SQLite (search_sqlite)
+package brainloop
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/horos/holow-mcp/internal/database"
+)
+
+const (
+	defaultSearchMatches = 50
+	maxSearchMatches     = 500
+	maxSearchRowsScanned = 100000 // Lignes lues par colonne au plus
+	maxSearchColumns     = 500    // Colonnes texte parcourues au plus
+	searchSnippetRadius  = 40     // Caractères conservés de part et d'autre de la correspondance
+)
+
+// searchSQLite cherche pattern (sous-chaîne, casse ASCII ignorée) dans les colonnes texte
+// de toutes les tables d'une base ouverte en lecture seule; retourne table, colonne, rowid et extrait
+func (m *ToolsManager) searchSQLite(args map[string]interface{}) (interface{}, error) {
+	dbPath, ok := args["path"].(string)
+	if !ok || dbPath == "" {
+		return nil, fmt.Errorf("path is required for search_sqlite")
+	}
+	pattern, ok := args["pattern"].(string)
+	if !ok || pattern == "" {
+		return nil, fmt.Errorf("pattern is required for search_sqlite")
+	}
+
+	validPath, err := validatePath(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	maxMatches := defaultSearchMatches
+	if n, ok := args["max_matches"].(float64); ok && n > 0 {
+		maxMatches = int(n)
+	}
+	if maxMatches > maxSearchMatches {
+		maxMatches = maxSearchMatches
+	}
+
+	db, err := database.OpenReadOnly(validPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	columns, err := textColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	truncated := false
+	if len(columns) > maxSearchColumns {
+		columns = columns[:maxSearchColumns]
+		truncated = true
+	}
+
+	matches := []map[string]interface{}{}
+	var skipped []map[string]interface{}
+	for _, col := range columns {
+		if len(matches) >= maxMatches {
+			truncated = true
+			break
+		}
+		found, err := searchColumn(db, col, pattern, maxMatches-len(matches))
+		if err != nil {
+			skipped = append(skipped, map[string]interface{}{"table": col.table, "column": col.name, "error": err.Error()})
+			continue
+		}
+		matches = append(matches, found...)
+	}
+
+	result := map[string]interface{}{
+		"success":         true,
+		"action":          "search_sqlite",
+		"path":            validPath,
+		"pattern":         pattern,
+		"columns_scanned": len(columns),
+		"match_count":     len(matches),
+		"matches":         matches,
+		"truncated":       truncated,
+	}
+	if len(skipped) > 0 {
+		result["skipped"] = skipped
+	}
+	return result, nil
+}
+
+// sqliteColumn colonne candidate à la recherche
+type sqliteColumn struct {
+	table, name string
+}
+
+// textColumns liste les colonnes d'affinité texte (TEXT, CHAR, CLOB) ou sans type déclaré
+func textColumns(db *sql.DB) ([]sqliteColumn, error) {
+	rows, err := db.Query(`
+		SELECT m.name, p.name, UPPER(p.type)
+		FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		ORDER BY m.name, p.cid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []sqliteColumn
+	for rows.Next() {
+		var col sqliteColumn
+		var colType string
+		if err := rows.Scan(&col.table, &col.name, &colType); err != nil {
+			return nil, err
+		}
+		if colType == "" || strings.Contains(colType, "TEXT") || strings.Contains(colType, "CHAR") || strings.Contains(colType, "CLOB") {
+			columns = append(columns, col)
+		}
+	}
+	return columns, rows.Err()
+}
+
+// searchColumn retourne au plus limit correspondances de pattern dans une colonne
+// (lecture bornée à maxSearchRowsScanned lignes; rowid nul pour une table WITHOUT ROWID)
+func searchColumn(db *sql.DB, col sqliteColumn, pattern string, limit int) ([]map[string]interface{}, error) {
+	query := func(rowid string) string {
+		return fmt.Sprintf(`
+			SELECT rid, v FROM (SELECT %s AS rid, CAST(%s AS TEXT) AS v FROM %s LIMIT %d)
+			WHERE instr(lower(v), lower(?)) > 0 LIMIT %d`,
+			rowid, quoteIdent(col.name), quoteIdent(col.table), maxSearchRowsScanned, limit)
+	}
+
+	rows, err := db.Query(query("rowid"), pattern)
+	if err != nil && strings.Contains(err.Error(), "no such column: rowid") {
+		rows, err = db.Query(query("NULL"), pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []map[string]interface{}
+	for rows.Next() {
+		var rowid sql.NullInt64
+		var value string
+		if err := rows.Scan(&rowid, &value); err != nil {
+			return nil, err
+		}
+		match := map[string]interface{}{
+			"table":   col.table,
+			"column":  col.name,
+			"snippet": snippetAround(value, pattern),
+		}
+		if rowid.Valid {
+			match["rowid"] = rowid.Int64
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// snippetAround extrait searchSnippetRadius caractères autour de la première occurrence de pattern
+func snippetAround(value, pattern string) string {
+	runes := []rune(value)
+	lowerRunes := []rune(strings.ToLower(value))
+	start := 0
+	if len(lowerRunes) == len(runes) {
+		if i := strings.Index(string(lowerRunes), strings.ToLower(pattern)); i >= 0 {
+			start = len([]rune(string(lowerRunes)[:i]))
+		}
+	}
+
+	from := start - searchSnippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := start + len([]rune(pattern)) + searchSnippetRadius
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	snippet := string(runes[from:to])
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// quoteIdent protège un identifiant SQLite (table, colonne) entre guillemets doubles
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,101 @@
+// Package brainloop - Extraction des symboles avec positions pour read_code
+package brainloop
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Symbol représente une définition localisée dans un fichier source
+type Symbol struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind,omitempty"`     // func, method, struct, interface, type, class
+	Receiver string `json:"receiver,omitempty"` // Type récepteur (méthodes Go)
+	Line     int    `json:"line"`
+	EndLine  int    `json:"end_line,omitempty"`
+}
+
+// extractGoSymbols extrait fonctions et types Go via go/ast (lignes de début et de fin)
+// Si le fichier ne parse pas, repli sur les expressions régulières (ligne de début seule)
+func extractGoSymbols(code string) (functions, types []Symbol) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return extractGoFunctions(code), extractGoTypes(code)
+	}
+
+	functions = []Symbol{}
+	types = []Symbol{}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			sym := Symbol{
+				Name:    d.Name.Name,
+				Kind:    "func",
+				Line:    fset.Position(d.Pos()).Line,
+				EndLine: fset.Position(d.End()).Line,
+			}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				sym.Kind = "method"
+				sym.Receiver = receiverName(d.Recv.List[0].Type)
+			}
+			functions = append(functions, sym)
+
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				kind := "type"
+				switch ts.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				types = append(types, Symbol{
+					Name:    ts.Name.Name,
+					Kind:    kind,
+					Line:    fset.Position(ts.Pos()).Line,
+					EndLine: fset.Position(ts.End()).Line,
+				})
+			}
+		}
+	}
+	return functions, types
+}
+
+// receiverName retourne le nom du type récepteur (sans pointeur ni paramètres génériques)
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// matchSymbols localise chaque correspondance de re (groupe 1 = nom) par numéro de ligne
+func matchSymbols(code string, re *regexp.Regexp, kind string) []Symbol {
+	symbols := []Symbol{}
+	for _, loc := range re.FindAllStringSubmatchIndex(code, -1) {
+		symbols = append(symbols, Symbol{
+			Name: code[loc[2]:loc[3]],
+			Kind: kind,
+			Line: strings.Count(code[:loc[0]], "\n") + 1,
+		})
+	}
+	return symbols
+}
@@ -0,0 +1,23 @@
+// Package brainloop - Action discovery (environnement hôte détecté au démarrage)
+package brainloop
+
+import (
+	"fmt"
+
+	"github.com/horos/holow-mcp/internal/discovery"
+)
+
+// systemDiscovery expose les résultats de la découverte stockés dans lifecycle-core.config
+func (m *ToolsManager) systemDiscovery() (interface{}, error) {
+	if m.coreDB == nil {
+		return nil, fmt.Errorf("lifecycle-core database not configured")
+	}
+
+	info := discovery.New(m.coreDB).Info()
+	return map[string]interface{}{
+		"success":    true,
+		"action":     "discovery",
+		"discovered": info.DiscoveredAt != "",
+		"system":     info,
+	}, nil
+}
@@ -3,6 +3,7 @@
 package brainloop
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
@@ -11,8 +12,16 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/config"
+	"github.com/horos/holow-mcp/internal/database"
+	"github.com/horos/holow-mcp/internal/llm"
+	"github.com/horos/holow-mcp/internal/tools"
 )
 
 // allowedBasePaths définit les répertoires de base autorisés pour la lecture de fichiers
@@ -101,9 +110,52 @@ func validatePath(path string) (string, error) {
 
 // ToolsManager gère les outils brainloop
 type ToolsManager struct {
-	mu      sync.Mutex
-	toolsDB *sql.DB // Base lifecycle-tools pour actions système
-	execDB  *sql.DB // Base lifecycle-execution pour statistiques
+	mu       sync.Mutex
+	toolsDB  *sql.DB            // Base lifecycle-tools pour actions système
+	execDB   *sql.DB            // Base lifecycle-execution pour statistiques
+	coreDB   *sql.DB            // Base lifecycle-core pour la whitelist ATTACH
+	outputDB *sql.DB            // Base output pour la consommation LLM
+	holowDBs []database.NamedDB // Les 6 bases holow (pour db_stats, migrations_status)
+	schemas  string             // Dossier des schémas et de leurs migrations (pour migrations_status)
+	metrics  MetricsFlusher
+	catalog  ToolCatalog
+	requests RequestRegistry
+	recovery ToolRecoverer
+	validate ToolValidator
+	attach   AttachWhitelist
+	llm      llm.Client // Client LLM des actions de génération (nil = non configuré)
+	safeMode int32      // 1 si les actions de génération sont désactivées (SetSafeMode)
+}
+
+// MetricsFlusher persiste à la demande la fenêtre de métriques courante
+type MetricsFlusher interface {
+	Flush() (int, error)
+}
+
+// ToolCatalog fournit les schémas MCP de tous les tools exposés (browser, brainloop, SQL)
+type ToolCatalog interface {
+	ToolCatalog() []map[string]interface{}
+}
+
+// RequestRegistry expose les requêtes JSON-RPC en cours du serveur
+type RequestRegistry interface {
+	ListInflight() []map[string]interface{}
+	CancelRequest(id string) int
+}
+
+// ToolValidator compile les steps SQL d'un tool sans les exécuter
+type ToolValidator interface {
+	ValidateToolSteps(name string, steps []tools.StepDef, args map[string]interface{}, secretPatterns []string) (map[string]interface{}, error)
+}
+
+// AttachWhitelist ajoute un chemin à la whitelist ATTACH (database.Manager)
+type AttachWhitelist interface {
+	AddAllowedAttachPath(workerName, dbPath, dbType, description string) error
+}
+
+// ToolRecoverer remet en service un tool en échec (circuit breaker, retries, DLQ, activation)
+type ToolRecoverer interface {
+	RecoverTool(name string, requeue bool) (map[string]interface{}, error)
 }
 
 // NewToolsManager crée un nouveau gestionnaire
@@ -121,13 +173,68 @@ func (m *ToolsManager) SetExecDB(db *sql.DB) {
 	m.execDB = db
 }
 
+// SetCoreDB configure la base lifecycle-core (whitelist ATTACH)
+func (m *ToolsManager) SetCoreDB(db *sql.DB) {
+	m.coreDB = db
+}
+
+// SetOutputDB configure la base output (table llm_usage)
+func (m *ToolsManager) SetOutputDB(db *sql.DB) {
+	m.outputDB = db
+}
+
+// SetDatabases configure les 6 bases holow analysées par db_stats sans path
+func (m *ToolsManager) SetDatabases(dbs []database.NamedDB) {
+	m.holowDBs = dbs
+}
+
+// SetMigrationsPath configure le dossier des schémas dont migrations_status lit les migrations
+func (m *ToolsManager) SetMigrationsPath(path string) {
+	m.schemas = path
+}
+
+// SetMetrics configure le collecteur de métriques (pour flush_metrics)
+func (m *ToolsManager) SetMetrics(f MetricsFlusher) {
+	m.metrics = f
+}
+
+// SetToolCatalog configure la source du catalogue de tools (pour export_tools_schema)
+func (m *ToolsManager) SetToolCatalog(c ToolCatalog) {
+	m.catalog = c
+}
+
+// SetRequestRegistry configure le registre des requêtes (pour list_inflight, cancel_request)
+func (m *ToolsManager) SetRequestRegistry(r RequestRegistry) {
+	m.requests = r
+}
+
+// SetToolRecoverer configure la remise en service des tools (pour recover_tool)
+func (m *ToolsManager) SetToolRecoverer(r ToolRecoverer) {
+	m.recovery = r
+}
+
+// SetToolValidator configure la compilation des steps de tools (pour validate_tool)
+func (m *ToolsManager) SetToolValidator(v ToolValidator) {
+	m.validate = v
+}
+
+// SetAttachWhitelist configure l'écriture de la whitelist ATTACH (pour attach_allow)
+func (m *ToolsManager) SetAttachWhitelist(w AttachWhitelist) {
+	m.attach = w
+}
+
+// SetLLM configure le client LLM utilisé par les actions de génération
+func (m *ToolsManager) SetLLM(c llm.Client) {
+	m.llm = c
+}
+
 // ToolDefinitions retourne la définition du tool maître brainloop
 // Pattern Progressive Disclosure : 1 tool au lieu de 11 = 83% économie tokens contexte
 func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
-	return []map[string]interface{}{
+	defs := []map[string]interface{}{
 		{
 			"name":        "brainloop",
-			"description": "Smart analysis, generation, and system tool. Actions: create_tool, list_tools, get_tool, audit_system, get_metrics (system); generate_file, generate_sql, explore, loop (generation); read_sqlite, read_code, read_markdown, read_config (reading); list_actions, get_schema, get_stats (discovery)",
+			"description": "Smart analysis, generation, and system tool. Actions: create_tool, upsert_tool, list_tools, get_tool, audit_system, get_metrics, flush_metrics, attach_list, attach_allow, attach_deny, list_inflight, cancel_request, recover_tool, validate_tool, llm_usage, db_stats, migrations_status (system); generate_file, generate_sql, explore, loop (generation); read_sqlite, search_sqlite, diff_schema, read_code, read_markdown, read_config, explain, list_files, search_code, hash_tree, count_lines (reading); list_actions, get_schema, get_stats, export_tools_schema, discovery (discovery)",
 			"inputSchema": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -137,10 +244,22 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 						"enum": []string{
 							// Système
 							"create_tool",
+							"upsert_tool",
 							"list_tools",
 							"get_tool",
 							"audit_system",
 							"get_metrics",
+							"flush_metrics",
+							"attach_list",
+							"attach_allow",
+							"attach_deny",
+							"list_inflight",
+							"cancel_request",
+							"recover_tool",
+							"validate_tool",
+							"llm_usage",
+							"db_stats",
+							"migrations_status",
 							// Génération
 							"generate_file",
 							"generate_sql",
@@ -148,30 +267,59 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 							"loop",
 							// Lecture
 							"read_sqlite",
+							"search_sqlite",
+							"diff_schema",
 							"read_code",
 							"read_markdown",
 							"read_config",
+							"explain",
 							"list_files",
 							"search_code",
+							"hash_tree",
+							"count_lines",
 							// Discovery
 							"list_actions",
 							"get_schema",
 							"get_stats",
+							"export_tools_schema",
+							"discovery",
 						},
 					},
 					"path": map[string]interface{}{
 						"type":        "string",
 						"description": "File or directory path",
 					},
+					"other_path": map[string]interface{}{
+						"type":        "string",
+						"description": "SQLite database or .sql schema script compared against path (for diff_schema)",
+					},
 					"pattern": map[string]interface{}{
 						"type":        "string",
-						"description": "Search/glob pattern",
+						"description": "Search/glob pattern (for search_sqlite: literal substring, ASCII case-insensitive)",
+					},
+					"key_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Dotted path with [index] to extract a value (for read_config, e.g. services.db.port)",
+					},
+					"secret_patterns": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Extra secret key patterns merged with defaults (for read_config; for validate_tool, argument names whose values are redacted)",
+					},
+					"snapshot": map[string]interface{}{
+						"type":        "object",
+						"description": "Previous hash_tree files map to diff against (for hash_tree)",
 					},
 					"max_rows": map[string]interface{}{
 						"type":        "integer",
 						"default":     3,
 						"description": "Max sample rows (for read_sqlite)",
 					},
+					"max_matches": map[string]interface{}{
+						"type":        "integer",
+						"default":     50,
+						"description": "Max matches returned, capped at 500 (for search_sqlite)",
+					},
 					"action_name": map[string]interface{}{
 						"type":        "string",
 						"description": "Action name (for get_schema)",
@@ -183,16 +331,21 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 					},
 					"sql": map[string]interface{}{
 						"type":        "string",
-						"description": "SQL to execute (for generate_sql)",
+						"description": "SQL to execute (for generate_sql), to explain (for explain) or to validate as a single step (for validate_tool)",
+					},
+					"db": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"lifecycle-tools", "lifecycle-execution", "lifecycle-core"},
+						"description": "Database to explain against (for explain, default: lifecycle-tools)",
 					},
 					"context": map[string]interface{}{
 						"type":        "object",
-						"description": "Additional context for generation",
+						"description": "Additional context for generation; keys provider, model, max_tokens, temperature override the LLM call",
 					},
 					// Paramètres système
 					"name": map[string]interface{}{
 						"type":        "string",
-						"description": "Tool name (for create_tool, get_tool)",
+						"description": "Tool name (for create_tool, get_tool, recover_tool, validate_tool)",
 					},
 					"tool_description": map[string]interface{}{
 						"type":        "string",
@@ -206,15 +359,83 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 						"type":        "string",
 						"description": "Tool category (for create_tool, list_tools)",
 					},
+					"steps": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"name": map[string]interface{}{"type": "string"},
+								"type": map[string]interface{}{"type": "string"},
+								"sql":  map[string]interface{}{"type": "string"},
+							},
+						},
+						"description": "Ordered tool steps (for upsert_tool, validate_tool, alternative to sql)",
+					},
+					"arguments": map[string]interface{}{
+						"type":        "object",
+						"description": "Sample tool arguments substituted into the steps' {{param}} placeholders (for validate_tool)",
+					},
+					"request_id": map[string]interface{}{
+						"type":        []string{"string", "integer"},
+						"description": "JSON-RPC id of the in-flight request to cancel (for cancel_request)",
+					},
+					"requeue": map[string]interface{}{
+						"type":        "boolean",
+						"default":     true,
+						"description": "Requeue dead letters and exhausted retries instead of clearing them (for recover_tool)",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"default":     30,
+						"description": "Number of days to summarize (for llm_usage)",
+					},
+					"db_type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"input", "output", "lifecycle", "metadata"},
+						"description": "Database type (for attach_allow)",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Free-form description (for attach_allow)",
+					},
 				},
 				"required": []string{"action"},
 			},
 		},
 	}
+	if m.SafeMode() {
+		withoutGeneration(defs[0])
+	}
+	return defs
+}
+
+// ProgressFunc reçoit le contenu partiel d'une action longue (génération LLM en streaming)
+type ProgressFunc func(message string)
+
+// progressKey clé de contexte portant la ProgressFunc de l'appel
+type progressKey struct{}
+
+// WithProgress attache une ProgressFunc au contexte d'exécution
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom retourne la ProgressFunc du contexte (nil si absente)
+func progressFrom(ctx context.Context) llm.DeltaFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	if fn == nil {
+		return nil
+	}
+	return llm.DeltaFunc(fn)
 }
 
 // Execute exécute le tool maître brainloop avec dispatch sur action
 func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (interface{}, error) {
+	return m.ExecuteContext(context.Background(), toolName, args)
+}
+
+// ExecuteContext exécute le tool brainloop; ctx borne les appels LLM et porte la ProgressFunc
+func (m *ToolsManager) ExecuteContext(ctx context.Context, toolName string, args map[string]interface{}) (interface{}, error) {
 	// Le tool maître s'appelle "brainloop"
 	if toolName != "brainloop" {
 		return nil, fmt.Errorf("unknown tool: %s (expected 'brainloop')", toolName)
@@ -224,14 +445,32 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 	if !ok {
 		return nil, fmt.Errorf("action parameter is required")
 	}
+	if err := m.checkSafeMode(action); err != nil {
+		return nil, err
+	}
+
+	// Hors verrou: doivent répondre même si une autre action brainloop est bloquée
+	switch action {
+	case "list_inflight":
+		return m.listInflight()
+	case "cancel_request":
+		return m.cancelRequest(args)
+	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Requête annulée pendant l'attente du verrou
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	switch action {
 	// Système
 	case "create_tool":
 		return m.createTool(args)
+	case "upsert_tool":
+		return m.upsertTool(args)
 	case "list_tools":
 		return m.listTools(args)
 	case "get_tool":
@@ -240,28 +479,56 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 		return m.auditSystem()
 	case "get_metrics":
 		return m.getMetrics()
+	case "flush_metrics":
+		return m.flushMetrics()
+	case "attach_list":
+		return m.attachList()
+	case "attach_allow":
+		return m.attachAllow(args)
+	case "attach_deny":
+		return m.attachDeny(args)
+	case "recover_tool":
+		return m.recoverTool(args)
+	case "validate_tool":
+		return m.validateTool(args)
+	case "llm_usage":
+		return m.llmUsage(args)
+	case "db_stats":
+		return m.dbStats(args)
+	case "migrations_status":
+		return m.migrationsStatus()
 	// Génération
 	case "generate_file":
-		return m.generateFile(args)
+		return m.generateFile(ctx, args)
 	case "generate_sql":
-		return m.generateSQL(args)
+		return m.generateSQL(ctx, args)
 	case "explore":
-		return m.explore(args)
+		return m.explore(ctx, args)
 	case "loop":
-		return m.loop(args)
+		return m.loop(ctx, args)
 	// Lecture
 	case "read_sqlite":
 		return m.readSQLite(args)
+	case "search_sqlite":
+		return m.searchSQLite(args)
+	case "diff_schema":
+		return m.diffSchema(args)
 	case "read_code":
 		return m.readCode(args)
 	case "read_markdown":
 		return m.readMarkdown(args)
 	case "read_config":
 		return m.readConfig(args)
+	case "explain":
+		return m.explain(args)
 	case "list_files":
-		return m.listFiles(args)
+		return m.listFiles(ctx, args)
 	case "search_code":
-		return m.searchCode(args)
+		return m.searchCode(ctx, args)
+	case "hash_tree":
+		return m.hashTree(ctx, args)
+	case "count_lines":
+		return m.countLines(ctx, args)
 	// Discovery
 	case "list_actions":
 		return m.listActions()
@@ -269,45 +536,127 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 		return m.getSchema(args)
 	case "get_stats":
 		return m.getStats()
+	case "export_tools_schema":
+		return m.exportToolsSchema()
+	case "discovery":
+		return m.systemDiscovery()
 	default:
-		return nil, fmt.Errorf("unknown action: %s", action)
+		return nil, unknownActionError(action, m.actionNames())
+	}
+}
+
+// actionNames retourne les actions déclarées dans l'enum de ToolDefinitions
+func (m *ToolsManager) actionNames() []string {
+	props := m.ToolDefinitions()[0]["inputSchema"].(map[string]interface{})["properties"].(map[string]interface{})
+	return props["action"].(map[string]interface{})["enum"].([]string)
+}
+
+// unknownActionError construit une erreur listant les actions valides
+// et suggérant la plus proche (distance d'édition) pour permettre l'auto-correction
+func unknownActionError(action string, valid []string) error {
+	suggestion := ""
+	best := -1
+	for _, name := range valid {
+		d := levenshtein(strings.ToLower(action), name)
+		if best == -1 || d < best {
+			best, suggestion = d, name
+		}
+	}
+
+	// Ne suggérer que si la correction est plausible
+	if suggestion != "" && best <= len(suggestion)/2 {
+		return fmt.Errorf("unknown action: %s (did you mean %q?) - valid actions: %s",
+			action, suggestion, strings.Join(valid, ", "))
+	}
+	return fmt.Errorf("unknown action: %s - valid actions: %s", action, strings.Join(valid, ", "))
+}
+
+// levenshtein calcule la distance d'édition entre deux chaînes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
 	}
+	return prev[len(rb)]
 }
 
 // listActions retourne la liste des actions disponibles
 func (m *ToolsManager) listActions() (interface{}, error) {
+	actions := []map[string]interface{}{
+		// Système (17)
+		{"name": "create_tool", "description": "Create a new MCP tool", "requires": []string{"name", "tool_description", "sql"}, "category": "system"},
+		{"name": "upsert_tool", "description": "Create or replace a tool and its steps (idempotent)", "requires": []string{"name", "tool_description", "sql|steps"}, "category": "system"},
+		{"name": "list_tools", "description": "List available tools", "requires": []string{}, "category": "system"},
+		{"name": "get_tool", "description": "Get tool details", "requires": []string{"name"}, "category": "system"},
+		{"name": "audit_system", "description": "Audit system status", "requires": []string{}, "category": "system"},
+		{"name": "get_metrics", "description": "Get system metrics", "requires": []string{}, "category": "system"},
+		{"name": "flush_metrics", "description": "Persist the current metrics window now", "requires": []string{}, "category": "system"},
+		{"name": "attach_list", "description": "List ATTACH whitelist entries", "requires": []string{}, "category": "system"},
+		{"name": "attach_allow", "description": "Allow a SQLite database for ATTACH", "requires": []string{"name", "path"}, "category": "system"},
+		{"name": "attach_deny", "description": "Disable an ATTACH whitelist entry", "requires": []string{"name|path"}, "category": "system"},
+		{"name": "list_inflight", "description": "List requests currently being processed", "requires": []string{}, "category": "system"},
+		{"name": "cancel_request", "description": "Cancel an in-flight request by its JSON-RPC id", "requires": []string{"request_id"}, "category": "system"},
+		{"name": "recover_tool", "description": "Reset a failing tool's circuit breaker, requeue or clear its retries and dead letters, and re-enable it", "requires": []string{"name"}, "category": "system"},
+		{"name": "validate_tool", "description": "Compile each step's substituted SQL against its target database without executing it", "requires": []string{"name|sql|steps"}, "category": "system"},
+		{"name": "llm_usage", "description": "Summarize LLM token usage and estimated cost by provider and day", "requires": []string{}, "category": "system"},
+		{"name": "db_stats", "description": "Report file, WAL and per-table sizes and row counts of the holow databases or of a SQLite file", "requires": []string{}, "category": "system"},
+		{"name": "migrations_status", "description": "Report each holow database's user_version and its available, applied and pending schema migrations", "requires": []string{}, "category": "system"},
+		// Génération (4)
+		{"name": "generate_file", "description": "Generate file from prompt with pattern extraction", "requires": []string{"prompt", "path"}, "category": "generation"},
+		{"name": "generate_sql", "description": "Generate and execute SQL from prompt", "requires": []string{"prompt"}, "category": "generation"},
+		{"name": "explore", "description": "Creative exploration of codebase", "requires": []string{"prompt"}, "category": "generation"},
+		{"name": "loop", "description": "Iterative workflow: propose/audit/refine/commit", "requires": []string{"prompt"}, "category": "generation"},
+		// Lecture (7)
+		{"name": "read_sqlite", "description": "Analyze SQLite database structure (opened read-only)", "requires": []string{"path"}, "category": "reading"},
+		{"name": "search_sqlite", "description": "Find a value in the text columns of every table of a SQLite database (opened read-only)", "requires": []string{"path", "pattern"}, "category": "reading"},
+		{"name": "diff_schema", "description": "Compare the tables, columns, indexes, views and triggers of two SQLite databases, or of a database and a .sql schema", "requires": []string{"path", "other_path"}, "category": "reading"},
+		{"name": "read_code", "description": "Analyze code file with pattern detection", "requires": []string{"path"}, "category": "reading"},
+		{"name": "read_markdown", "description": "Analyze markdown document structure", "requires": []string{"path"}, "category": "reading"},
+		{"name": "read_config", "description": "Analyze config file (JSON/YAML/TOML)", "requires": []string{"path"}, "category": "reading"},
+		{"name": "explain", "description": "Show the EXPLAIN QUERY PLAN of a read-only query and flag full table scans", "requires": []string{"sql"}, "category": "reading"},
+		// Utilitaires
+		{"name": "list_files", "description": "List files matching glob pattern", "requires": []string{"pattern"}, "category": "utility"},
+		{"name": "search_code", "description": "Search pattern in code files", "requires": []string{"pattern"}, "category": "utility"},
+		{"name": "hash_tree", "description": "Hash a directory tree and diff against a previous snapshot", "requires": []string{"path"}, "category": "utility"},
+		{"name": "count_lines", "description": "Count files, lines and bytes per language across a directory", "requires": []string{}, "category": "utility"},
+		// Discovery (5)
+		{"name": "list_actions", "description": "List all available actions", "requires": []string{}, "category": "discovery"},
+		{"name": "get_schema", "description": "Get detailed schema for an action", "requires": []string{"action_name"}, "category": "discovery"},
+		{"name": "get_stats", "description": "Get usage statistics", "requires": []string{}, "category": "discovery"},
+		{"name": "export_tools_schema", "description": "Export every tool's name, description and input schema", "requires": []string{}, "category": "discovery"},
+		{"name": "discovery", "description": "Report the host environment detected at startup (platform, Chromium, sqlite3/git, temp dir, disk)", "requires": []string{}, "category": "discovery"},
+	}
+	if m.SafeMode() {
+		kept := actions[:0]
+		for _, a := range actions {
+			if !generationActions[a["name"].(string)] {
+				kept = append(kept, a)
+			}
+		}
+		actions = kept
+	}
+
 	return map[string]interface{}{
-		"actions": []map[string]interface{}{
-			// Système (5)
-			{"name": "create_tool", "description": "Create a new MCP tool", "requires": []string{"name", "tool_description", "sql"}, "category": "system"},
-			{"name": "list_tools", "description": "List available tools", "requires": []string{}, "category": "system"},
-			{"name": "get_tool", "description": "Get tool details", "requires": []string{"name"}, "category": "system"},
-			{"name": "audit_system", "description": "Audit system status", "requires": []string{}, "category": "system"},
-			{"name": "get_metrics", "description": "Get system metrics", "requires": []string{}, "category": "system"},
-			// Génération (4)
-			{"name": "generate_file", "description": "Generate file from prompt with pattern extraction", "requires": []string{"prompt", "path"}, "category": "generation"},
-			{"name": "generate_sql", "description": "Generate and execute SQL from prompt", "requires": []string{"prompt"}, "category": "generation"},
-			{"name": "explore", "description": "Creative exploration of codebase", "requires": []string{"prompt"}, "category": "generation"},
-			{"name": "loop", "description": "Iterative workflow: propose/audit/refine/commit", "requires": []string{"prompt"}, "category": "generation"},
-			// Lecture (4)
-			{"name": "read_sqlite", "description": "Analyze SQLite database structure", "requires": []string{"path"}, "category": "reading"},
-			{"name": "read_code", "description": "Analyze code file with pattern detection", "requires": []string{"path"}, "category": "reading"},
-			{"name": "read_markdown", "description": "Analyze markdown document structure", "requires": []string{"path"}, "category": "reading"},
-			{"name": "read_config", "description": "Analyze config file (JSON/YAML/TOML)", "requires": []string{"path"}, "category": "reading"},
-			// Utilitaires
-			{"name": "list_files", "description": "List files matching glob pattern", "requires": []string{"pattern"}, "category": "utility"},
-			{"name": "search_code", "description": "Search pattern in code files", "requires": []string{"pattern"}, "category": "utility"},
-			// Discovery (3)
-			{"name": "list_actions", "description": "List all available actions", "requires": []string{}, "category": "discovery"},
-			{"name": "get_schema", "description": "Get detailed schema for an action", "requires": []string{"action_name"}, "category": "discovery"},
-			{"name": "get_stats", "description": "Get usage statistics", "requires": []string{}, "category": "discovery"},
-		},
-		"total": 18,
+		"actions": actions,
+		"total":   len(actions),
 	}, nil
 }
 
 // generateFile génère un fichier à partir d'un prompt
-func (m *ToolsManager) generateFile(args map[string]interface{}) (interface{}, error) {
+func (m *ToolsManager) generateFile(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	prompt, ok := args["prompt"].(string)
 	if !ok {
 		return nil, fmt.Errorf("prompt is required for generate_file")
@@ -318,20 +667,132 @@ func (m *ToolsManager) generateFile(args map[string]interface{}) (interface{}, e
 		return nil, fmt.Errorf("path is required for generate_file")
 	}
 
-	// TODO: Intégrer avec LLM (Cerebras) pour génération
-	// Pour l'instant, retourner un placeholder
+	if m.llm == nil {
+		return map[string]interface{}{
+			"success": false,
+			"action":  "generate_file",
+			"prompt":  prompt,
+			"path":    path,
+			"message": "Generation requires an LLM provider (claude, gemini or cerebras credentials). Use MCP to generate content and write to path.",
+			"hint":    "Extract patterns from codebase first with read_code, then generate conformant code",
+		}, nil
+	}
+
+	validPath, err := validatePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	opts, extra, err := generationOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	opts.System = fmt.Sprintf("Write the complete content of the file %s. Reply with the file content only, without explanations or markdown fences.", filepath.Base(validPath))
+
+	fullPrompt := prompt
+	if extra != nil {
+		contextJSON, _ := json.MarshalIndent(extra, "", "  ")
+		fullPrompt += "\n\nContext:\n" + string(contextJSON)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, generationTimeout)
+	defer cancel()
+	resp, err := llm.CompleteStream(ctx, m.llm, fullPrompt, opts, progressFrom(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("generation failed: %w", err)
+	}
+
+	// Pas d'écriture si la requête a été annulée après la réponse du LLM
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content := stripCodeFence(resp.Text)
+	if err := os.MkdirAll(filepath.Dir(validPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(validPath, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+
 	return map[string]interface{}{
-		"success": false,
-		"action":  "generate_file",
-		"prompt":  prompt,
-		"path":    path,
-		"message": "Generation requires LLM integration (Cerebras). Use MCP to generate content and write to path.",
-		"hint":    "Extract patterns from codebase first with read_code, then generate conformant code",
+		"success":           true,
+		"action":            "generate_file",
+		"path":              validPath,
+		"bytes":             len(content),
+		"provider":          resp.Provider,
+		"model":             resp.Model,
+		"prompt_tokens":     resp.PromptTokens,
+		"completion_tokens": resp.CompletionTokens,
 	}, nil
 }
 
+// generationOptions extrait de args["context"] les surcharges d'appel LLM (provider, model, max_tokens, temperature)
+// Le reste du contexte est retourné pour être joint au prompt
+// base_url reste réservé à provider_config: une URL fournie par l'appelant recevrait la clé API
+func generationOptions(args map[string]interface{}) (llm.Options, interface{}, error) {
+	var opts llm.Options
+	raw, ok := args["context"]
+	if !ok {
+		return opts, nil, nil
+	}
+	ctxMap, ok := raw.(map[string]interface{})
+	if !ok {
+		return opts, raw, nil
+	}
+
+	extra := make(map[string]interface{}, len(ctxMap))
+	for key, value := range ctxMap {
+		switch key {
+		case "provider", "model":
+			s, ok := value.(string)
+			if !ok {
+				return opts, nil, fmt.Errorf("context.%s must be a string", key)
+			}
+			if key == "provider" {
+				opts.Provider = s
+			} else {
+				opts.Model = s
+			}
+		case "max_tokens":
+			n, ok := value.(float64)
+			if !ok || n <= 0 {
+				return opts, nil, fmt.Errorf("context.max_tokens must be a positive integer")
+			}
+			opts.MaxTokens = int(n)
+		case "temperature":
+			t, ok := value.(float64)
+			if !ok || t < 0 {
+				return opts, nil, fmt.Errorf("context.temperature must be a non-negative number")
+			}
+			opts.Temperature = t
+		case "base_url":
+			return opts, nil, fmt.Errorf("context.base_url is not accepted: set base_url in provider_config")
+		default:
+			extra[key] = value
+		}
+	}
+	if len(extra) == 0 {
+		return opts, nil, nil
+	}
+	return opts, extra, nil
+}
+
+// generationTimeout budget d'un appel LLM de génération (retries compris)
+const generationTimeout = 5 * time.Minute
+
+// codeFenceRegex reconnaît une réponse entièrement entourée d'un bloc ```lang ... ```
+var codeFenceRegex = regexp.MustCompile("(?s)^\\s*```[\\w+-]*\\n(.*?)\\n?```\\s*$")
+
+// stripCodeFence retire le bloc markdown englobant que certains modèles ajoutent malgré la consigne
+func stripCodeFence(text string) string {
+	if match := codeFenceRegex.FindStringSubmatch(text); match != nil {
+		return match[1] + "\n"
+	}
+	return text
+}
+
 // generateSQL génère et exécute du SQL
-func (m *ToolsManager) generateSQL(args map[string]interface{}) (interface{}, error) {
+func (m *ToolsManager) generateSQL(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	prompt, ok := args["prompt"].(string)
 	if !ok {
 		return nil, fmt.Errorf("prompt is required for generate_sql")
@@ -350,7 +811,7 @@ func (m *ToolsManager) generateSQL(args map[string]interface{}) (interface{}, er
 		}
 		defer db.Close()
 
-		result, err := db.Exec(sqlQuery)
+		result, err := db.ExecContext(ctx, sqlQuery)
 		if err != nil {
 			return nil, fmt.Errorf("SQL execution failed: %w", err)
 		}
@@ -378,7 +839,7 @@ rowsAffected, _ := result.RowsAffected()
 }
 
 // explore fait une exploration créative du codebase
-func (m *ToolsManager) explore(args map[string]interface{}) (interface{}, error) {
+func (m *ToolsManager) explore(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	prompt, ok := args["prompt"].(string)
 	if !ok {
 		return nil, fmt.Errorf("prompt is required for explore")
@@ -397,13 +858,19 @@ func (m *ToolsManager) explore(args map[string]interface{}) (interface{}, error)
 		totalFiles int
 		totalSize  int64
 	}
+	var files []string
 
-	filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
+	walkErr := walkContext(ctx, basePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
 		stats.totalFiles++
 		stats.totalSize += info.Size()
+		if len(files) < exploreMaxFiles {
+			if rel, err := filepath.Rel(basePath, path); err == nil {
+				files = append(files, rel)
+			}
+		}
 		switch filepath.Ext(path) {
 		case ".go":
 			stats.goFiles++
@@ -414,29 +881,75 @@ func (m *ToolsManager) explore(args map[string]interface{}) (interface{}, error)
 		}
 		return nil
 	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
 
-	return map[string]interface{}{
-		"success": true,
-		"action":  "explore",
-		"prompt":  prompt,
-		"path":    basePath,
-		"codebase_stats": map[string]interface{}{
-			"total_files": stats.totalFiles,
-			"total_size":  stats.totalSize,
-			"go_files":    stats.goFiles,
-			"sql_files":   stats.sqlFiles,
-			"md_files":    stats.mdFiles,
-		},
-		"message": "Use this context with LLM to explore creatively based on prompt",
-	}, nil
+	codebaseStats := map[string]interface{}{
+		"total_files": stats.totalFiles,
+		"total_size":  stats.totalSize,
+		"go_files":    stats.goFiles,
+		"sql_files":   stats.sqlFiles,
+		"md_files":    stats.mdFiles,
+	}
+	result := map[string]interface{}{
+		"success":        true,
+		"action":         "explore",
+		"prompt":         prompt,
+		"path":           basePath,
+		"codebase_stats": codebaseStats,
+		"message":        "Use this context with LLM to explore creatively based on prompt",
+	}
+	if m.llm == nil {
+		return result, nil
+	}
+
+	// Avec un LLM configuré: analyse en streaming à partir des statistiques et de l'arborescence
+	opts, _, err := generationOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	opts.System = "You explore a codebase from its file tree and statistics. Answer the request concisely, pointing to concrete files."
+	statsJSON, _ := json.Marshal(codebaseStats)
+	llmPrompt := fmt.Sprintf("%s\n\nCodebase at %s\nStats: %s\nFiles:\n%s",
+		prompt, basePath, statsJSON, strings.Join(files, "\n"))
+
+	ctx, cancel := context.WithTimeout(ctx, generationTimeout)
+	defer cancel()
+	resp, err := llm.CompleteStream(ctx, m.llm, llmPrompt, opts, progressFrom(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("exploration failed: %w", err)
+	}
+
+	delete(result, "message")
+	result["analysis"] = resp.Text
+	result["provider"] = resp.Provider
+	result["model"] = resp.Model
+	return result, nil
+}
+
+// exploreMaxFiles nombre max de chemins transmis au LLM par explore
+const exploreMaxFiles = 200
+
+// walkContext parcourt root comme filepath.Walk mais s'arrête dès que ctx est annulé
+func walkContext(ctx context.Context, root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fn(path, info, err)
+	})
 }
 
 // loop exécute un workflow itératif propose/audit/refine/commit
-func (m *ToolsManager) loop(args map[string]interface{}) (interface{}, error) {
+func (m *ToolsManager) loop(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	prompt, ok := args["prompt"].(string)
 	if !ok {
 		return nil, fmt.Errorf("prompt is required for loop")
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// TODO: Implémenter le workflow itératif complet
 	return map[string]interface{}{
@@ -466,7 +979,7 @@ func (m *ToolsManager) getSchema(args map[string]interface{}) (interface{}, erro
 			"action":   "generate_file",
 			"required": []string{"prompt", "path"},
 			"optional": map[string]interface{}{
-				"context": "object - Additional context for generation",
+				"context": "object - Additional context for generation; provider, model, max_tokens, temperature override the LLM call",
 			},
 			"example": map[string]interface{}{
 				"action": "generate_file",
@@ -491,7 +1004,8 @@ func (m *ToolsManager) getSchema(args map[string]interface{}) (interface{}, erro
 			"action":   "explore",
 			"required": []string{"prompt"},
 			"optional": map[string]interface{}{
-				"path": "string - Base directory to explore",
+				"path":    "string - Base directory to explore",
+				"context": "object - LLM call overrides: provider, model, max_tokens, temperature",
 			},
 			"example": map[string]interface{}{
 				"action": "explore",
@@ -521,6 +1035,38 @@ func (m *ToolsManager) getSchema(args map[string]interface{}) (interface{}, erro
 				"max_rows": 5,
 			},
 		},
+		"search_sqlite": map[string]interface{}{
+			"action":   "search_sqlite",
+			"required": []string{"path", "pattern"},
+			"optional": map[string]interface{}{
+				"max_matches": "integer (default: 50, max: 500) - Maximum matches returned",
+			},
+			"returns": map[string]interface{}{
+				"matches":   "array - table, column, rowid (absent for WITHOUT ROWID tables) and snippet around the match",
+				"truncated": "bool - Match or column cap reached before the whole database was searched",
+				"skipped":   "array - Columns that could not be read (e.g. virtual tables without their module)",
+			},
+			"example": map[string]interface{}{
+				"action":  "search_sqlite",
+				"path":    "/path/to/database.db",
+				"pattern": "fetch_prices",
+			},
+		},
+		"diff_schema": map[string]interface{}{
+			"action":   "diff_schema",
+			"required": []string{"path", "other_path"},
+			"returns": map[string]interface{}{
+				"added":     "array - Objects only in other_path (type, name, table, columns)",
+				"removed":   "array - Objects only in path",
+				"changed":   "array - Objects in both that differ: columns_added, columns_removed, columns_changed (from/to), sql_from, sql_to",
+				"identical": "bool - No difference (formatting and IF NOT EXISTS are ignored)",
+			},
+			"example": map[string]interface{}{
+				"action":     "diff_schema",
+				"path":       "/home/user/.holow-mcp/holow-mcp.lifecycle-core.db",
+				"other_path": "/home/user/holow-mcp/schemas/lifecycle-core.sql",
+			},
+		},
 		"read_code": map[string]interface{}{
 			"action":   "read_code",
 			"required": []string{"path"},
@@ -540,11 +1086,31 @@ func (m *ToolsManager) getSchema(args map[string]interface{}) (interface{}, erro
 		"read_config": map[string]interface{}{
 			"action":   "read_config",
 			"required": []string{"path"},
+			"optional": map[string]interface{}{
+				"secret_patterns": "array|string - Extra secret key patterns, merged with defaults and config brainloop.secret_patterns",
+				"key_path":        "string - Dotted path with [index] (e.g. services.db.port) whose value is returned ([REDACTED] when any key of the path matches a secret pattern, also inside objects)",
+			},
 			"example": map[string]interface{}{
 				"action": "read_config",
 				"path":   "/path/to/config.json",
 			},
 		},
+		"explain": map[string]interface{}{
+			"action":   "explain",
+			"required": []string{"sql"},
+			"optional": map[string]interface{}{
+				"db": "string (default: lifecycle-tools) - lifecycle-tools, lifecycle-execution or lifecycle-core",
+			},
+			"returns": map[string]interface{}{
+				"plan":             "array - Query plan rows (id, parent, detail)",
+				"full_table_scans": "array - Tables scanned without an index",
+				"hints":            "array - Indexing suggestions",
+			},
+			"example": map[string]interface{}{
+				"action": "explain",
+				"sql":    "SELECT * FROM tool_definitions WHERE category = 'system'",
+			},
+		},
 		"list_files": map[string]interface{}{
 			"action":   "list_files",
 			"required": []string{"pattern"},
@@ -569,6 +1135,161 @@ func (m *ToolsManager) getSchema(args map[string]interface{}) (interface{}, erro
 				"path":    "/workspace",
 			},
 		},
+		// Système
+		"attach_allow": map[string]interface{}{
+			"action":   "attach_allow",
+			"required": []string{"name", "path"},
+			"optional": map[string]interface{}{
+				"db_type":     "string (default: output) - input, output, lifecycle or metadata",
+				"description": "string - Free-form description",
+			},
+			"example": map[string]interface{}{
+				"action":  "attach_allow",
+				"name":    "my-worker",
+				"path":    "/workspace/projets/my-worker/output.db",
+				"db_type": "output",
+			},
+		},
+		"attach_deny": map[string]interface{}{
+			"action":   "attach_deny",
+			"required": []string{"name or path"},
+			"example": map[string]interface{}{
+				"action": "attach_deny",
+				"name":   "my-worker",
+			},
+		},
+		"list_inflight": map[string]interface{}{
+			"action":   "list_inflight",
+			"required": []string{},
+			"returns": map[string]interface{}{
+				"requests": "array - In-flight requests, oldest first: id (JSON-RPC request id), method, tool (for tools/call), trace_id, started_at (RFC 3339, UTC), elapsed_ms",
+				"count":    "int - Number of in-flight requests",
+			},
+			"example": map[string]interface{}{
+				"action": "list_inflight",
+			},
+		},
+		"cancel_request": map[string]interface{}{
+			"action":   "cancel_request",
+			"required": []string{"request_id"},
+			"returns": map[string]interface{}{
+				"cancelled": "int - Number of in-flight requests cancelled",
+			},
+			"example": map[string]interface{}{
+				"action":     "cancel_request",
+				"request_id": 42,
+			},
+		},
+		"recover_tool": map[string]interface{}{
+			"action":   "recover_tool",
+			"required": []string{"name"},
+			"optional": map[string]interface{}{
+				"requeue": "bool (default: true) - Requeue dead letters and exhausted retries; false clears them",
+			},
+			"returns": map[string]interface{}{
+				"circuit_previous_state": "string - Breaker state before the reset",
+				"retries":                "int - retry_queue entries rescheduled (or deleted)",
+				"dead_letters":           "int - dead_letter_queue entries resolved",
+				"re_enabled":             "bool - Whether the tool was disabled and has been re-enabled",
+			},
+			"example": map[string]interface{}{
+				"action": "recover_tool",
+				"name":   "fetch_prices",
+			},
+		},
+		"validate_tool": map[string]interface{}{
+			"action":   "validate_tool",
+			"required": []string{"name|sql|steps"},
+			"optional": map[string]interface{}{
+				"name":            "string - Registered tool whose steps are validated (when sql/steps are omitted)",
+				"steps":           "array - Steps to validate before upsert_tool ({name, type, sql})",
+				"sql":             "string - Single sql step to validate",
+				"arguments":       "object - Sample arguments substituted into {{param}} placeholders",
+				"secret_patterns": "array|string - Extra secret name patterns, merged with defaults and config brainloop.secret_patterns",
+			},
+			"returns": map[string]interface{}{
+				"valid":    "bool - Every step compiles on its target database",
+				"steps":    "array - Per step: valid, error, target_db, statements, valid_in (databases where the SQL compiles), sql_template and sql (substituted, secret values replaced by [REDACTED])",
+				"redacted": "array - Arguments whose name matches a secret pattern, masked in sql and error",
+			},
+			"example": map[string]interface{}{
+				"action":    "validate_tool",
+				"sql":       "SELECT name FROM tool_definitions WHERE category = '{{category}}'",
+				"arguments": map[string]interface{}{"category": "custom"},
+			},
+		},
+		"llm_usage": map[string]interface{}{
+			"action":   "llm_usage",
+			"required": []string{},
+			"optional": map[string]interface{}{
+				"days": "int (default: 30) - Number of days to summarize",
+			},
+			"returns": map[string]interface{}{
+				"usage":  "array - calls, prompt_tokens, completion_tokens, cost_usd per provider and day",
+				"totals": "object - Same counters summed over the period",
+			},
+			"example": map[string]interface{}{
+				"action": "llm_usage",
+				"days":   7,
+			},
+		},
+		"db_stats": map[string]interface{}{
+			"action":   "db_stats",
+			"required": []string{},
+			"optional": map[string]interface{}{
+				"path": "string - SQLite file to analyze (opened read-only); default: the 6 holow databases",
+			},
+			"returns": map[string]interface{}{
+				"databases":   "array - Per database, largest first: file, total_bytes (file + WAL), wal_bytes, free_bytes, tables",
+				"tables":      "array - Per table, largest first: rows, bytes (table and its indexes, via dbstat)",
+				"total_bytes": "int - Sum over all databases",
+			},
+			"example": map[string]interface{}{
+				"action": "db_stats",
+			},
+		},
+		"migrations_status": map[string]interface{}{
+			"action":   "migrations_status",
+			"required": []string{},
+			"returns": map[string]interface{}{
+				"schema_version": "int - Schema version expected by this binary",
+				"databases":      "array - Per database: version (user_version), available, applied and pending migrations, up_to_date",
+				"pending":        "int - Migrations not yet applied, all databases",
+			},
+			"example": map[string]interface{}{
+				"action": "migrations_status",
+			},
+		},
+		"count_lines": map[string]interface{}{
+			"action":   "count_lines",
+			"required": []string{},
+			"optional": map[string]interface{}{
+				"path":    "string (default: .) - Directory to walk (hidden, vendor, node_modules, build dirs skipped)",
+				"pattern": "string - File glob filter (default: *)",
+			},
+			"returns": map[string]interface{}{
+				"totals":    "object - files, lines, blank, bytes",
+				"languages": "array - Same counters per language, most lines first",
+				"skipped":   "int - Binary, unreadable or >1MB files",
+			},
+			"example": map[string]interface{}{
+				"action":  "count_lines",
+				"path":    "/workspace/projets/my-worker",
+				"pattern": "*.go",
+			},
+		},
+		"hash_tree": map[string]interface{}{
+			"action":   "hash_tree",
+			"required": []string{"path"},
+			"optional": map[string]interface{}{
+				"pattern":  "string - File glob filter (default: *)",
+				"snapshot": "object - files map from a previous hash_tree call, returns added/removed/changed",
+			},
+			"example": map[string]interface{}{
+				"action": "hash_tree",
+				"path":   "/workspace/projets/my-worker",
+			},
+		},
 		// Discovery
 		"get_stats": map[string]interface{}{
 			"action":   "get_stats",
@@ -582,11 +1303,38 @@ func (m *ToolsManager) getSchema(args map[string]interface{}) (interface{}, erro
 				"action": "get_stats",
 			},
 		},
+		"export_tools_schema": map[string]interface{}{
+			"action":   "export_tools_schema",
+			"required": []string{},
+			"returns": map[string]interface{}{
+				"tools": "array - MCP tool definitions (name, description, inputSchema) for browser, brainloop and SQL tools",
+				"count": "int - Number of tools",
+			},
+			"example": map[string]interface{}{
+				"action": "export_tools_schema",
+			},
+		},
+		"discovery": map[string]interface{}{
+			"action":   "discovery",
+			"required": []string{},
+			"returns": map[string]interface{}{
+				"discovered": "bool - false if discovery never ran (values are defaults)",
+				"system":     "object - platform, arch, chromium_path, chromium_found, user_data_dir, default_port, sqlite3_path, git_path, temp_dir, disk_path, disk_free_mb, discovered_at",
+			},
+			"example": map[string]interface{}{
+				"action": "discovery",
+			},
+		},
 	}
 
 	schema, ok := schemas[actionName]
 	if !ok {
-		return nil, fmt.Errorf("unknown action: %s", actionName)
+		names := make([]string, 0, len(schemas))
+		for name := range schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, unknownActionError(actionName, names)
 	}
 
 	return schema, nil
@@ -610,7 +1358,8 @@ func (m *ToolsManager) readSQLite(args map[string]interface{}) (interface{}, err
 		maxRows = int(mr)
 	}
 
-	db, err := sql.Open("sqlite", validPath)
+	// Lecture seule: l'analyse ne crée ni -wal/-shm ni ne modifie la base
+	db, err := database.OpenReadOnly(validPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -684,12 +1433,7 @@ rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type='table' AND nam
 
 					row := make(map[string]interface{})
 					for i, col := range cols {
-						val := values[i]
-						if b, ok := val.([]byte); ok {
-							row[col] = string(b)
-						} else {
-							row[col] = val
-						}
+						row[col] = database.JSONValue(values[i])
 					}
 					samples = append(samples, row)
 				}
@@ -748,7 +1492,7 @@ func (m *ToolsManager) readCode(args map[string]interface{}) (interface{}, error
 	ext := filepath.Ext(validPath)
 
 	// Detect language
-language := detectLanguage(ext)
+language := detectLanguage(ext)
 
 	result := map[string]interface{}{
 		"success":    true,
@@ -762,8 +1506,9 @@ func (m *ToolsManager) readCode(args map[string]interface{}) (interface{}, error
 	switch language {
 	case "go":
 		result["imports"] = extractGoImports(code)
-		result["functions"] = extractGoFunctions(code)
-		result["types"] = extractGoTypes(code)
+		functions, types := extractGoSymbols(code)
+		result["functions"] = functions
+		result["types"] = types
 		result["patterns"] = detectGoPatterns(code)
 	case "python":
 		result["imports"] = extractPythonImports(code)
@@ -880,6 +1625,7 @@ func (m *ToolsManager) readConfig(args map[string]interface{}) (interface{}, err
 		"file_path": validPath,
 		"format":    strings.TrimPrefix(ext, "."),
 	}
+	secretPatterns := m.secretPatterns(args)
 
 	// Parse JSON
 	if ext == ".json" {
@@ -889,7 +1635,25 @@ func (m *ToolsManager) readConfig(args map[string]interface{}) (interface{}, err
 		} else {
 			result["keys"] = extractKeys(data, "")
 			result["parsed"] = true
+
+			if keyPath, ok := args["key_path"].(string); ok && keyPath != "" {
+				result["key_path"] = keyPath
+				if value, found := lookupConfigPath(data, keyPath); found {
+					// Ni une valeur sous une clé sensible ni les clés sensibles d'un objet extrait ne sont renvoyées en clair
+					if isSecretPath(keyPath, secretPatterns) {
+						value = redactedValue
+					} else {
+						value = redactConfigValue(value, secretPatterns)
+					}
+					result["value"] = value
+				} else {
+					result["key_path_error"] = fmt.Sprintf("no value at %s", keyPath)
+				}
+			}
 		}
+	} else if keyPath, ok := args["key_path"].(string); ok && keyPath != "" {
+		result["key_path"] = keyPath
+		result["key_path_error"] = "value extraction requires a parsed JSON config"
 	}
 
 	// Detect environment variables
@@ -903,27 +1667,138 @@ func (m *ToolsManager) readConfig(args map[string]interface{}) (interface{}, err
 	}
 
 	// Detect potential secrets
-	secretPatterns := []string{
-		"password", "secret", "key", "token", "api_key", "apikey",
-		"auth", "credential", "private",
-	}
 	var potentialSecrets []string
-	lowerContent := strings.ToLower(string(content))
-	for _, pattern := range secretPatterns {
-		if strings.Contains(lowerContent, pattern) {
+	var findings []map[string]interface{}
+	for i, line := range strings.Split(string(content), "\n") {
+		lowerLine := strings.ToLower(line)
+		for _, pattern := range secretPatterns {
+			if !strings.Contains(lowerLine, pattern) {
+				continue
+			}
 			potentialSecrets = append(potentialSecrets, pattern)
+			// La valeur n'est jamais renvoyée, seulement la clé et la ligne
+			findings = append(findings, map[string]interface{}{
+				"line":    i + 1,
+				"key":     configLineKey(line),
+				"pattern": pattern,
+			})
+			break
 		}
 	}
 	if len(potentialSecrets) > 0 {
-		result["potential_secrets"] = potentialSecrets
+		result["potential_secrets"] = unique(potentialSecrets)
+		result["secret_findings"] = findings
 		result["warning"] = "File may contain sensitive data"
 	}
 
 	return result, nil
 }
 
+// configSecretPatterns clé config des motifs de secrets additionnels (séparés par des virgules)
+const configSecretPatterns = "brainloop.secret_patterns"
+
+// defaultSecretPatterns motifs de noms de clés considérés comme sensibles
+var defaultSecretPatterns = []string{
+	"password", "secret", "key", "token", "api_key", "apikey",
+	"auth", "credential", "private",
+}
+
+// secretPatterns fusionne les motifs par défaut, ceux de la config et ceux de l'action
+func (m *ToolsManager) secretPatterns(args map[string]interface{}) []string {
+	patterns := append([]string{}, defaultSecretPatterns...)
+
+	if m.coreDB != nil {
+		if value, err := config.Get(m.coreDB, configSecretPatterns); err == nil {
+			patterns = append(patterns, strings.Split(value, ",")...)
+		}
+	}
+
+	switch extra := args["secret_patterns"].(type) {
+	case string:
+		patterns = append(patterns, strings.Split(extra, ",")...)
+	case []interface{}:
+		for _, p := range extra {
+			if s, ok := p.(string); ok {
+				patterns = append(patterns, s)
+			}
+		}
+	}
+
+	merged := make([]string, 0, len(patterns))
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// redactedValue remplace la valeur d'une clé sensible extraite par key_path
+const redactedValue = "[REDACTED]"
+
+// isSecretKey indique si un nom de clé contient un des motifs de secrets
+func isSecretKey(key string, patterns []string) bool {
+	lower := strings.ToLower(key)
+	for _, pattern := range patterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretPath indique si un des noms de clé d'un chemin key_path est sensible
+// (credentials.github.user -> credentials, github, user; tokens[0] -> tokens)
+func isSecretPath(path string, patterns []string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		if i := strings.Index(segment, "["); i >= 0 {
+			segment = segment[:i]
+		}
+		if isSecretKey(segment, patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConfigValue copie une valeur extraite en masquant les clés sensibles des objets imbriqués
+func redactConfigValue(value interface{}, patterns []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSecretKey(key, patterns) {
+				out[key] = redactedValue
+			} else {
+				out[key] = redactConfigValue(item, patterns)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactConfigValue(item, patterns)
+		}
+		return out
+	}
+	return value
+}
+
+// configLineKey extrait le nom de clé d'une ligne de config (JSON, YAML, TOML, .env)
+func configLineKey(line string) string {
+	key := strings.TrimSpace(line)
+	if i := strings.IndexAny(key, ":="); i >= 0 {
+		key = key[:i]
+	}
+	return strings.Trim(strings.TrimSpace(key), `"',`)
+}
+
 // listFiles liste les fichiers correspondant à un pattern
-func (m *ToolsManager) listFiles(args map[string]interface{}) (interface{}, error) {
+func (m *ToolsManager) listFiles(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	pattern, ok := args["pattern"].(string)
 	if !ok {
 		return nil, fmt.Errorf("pattern is required for list_files")
@@ -966,7 +1841,7 @@ func (m *ToolsManager) listFiles(args map[string]interface{}) (interface{}, erro
 
 	var files []map[string]interface{}
 
-	walkErr := filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
+	walkErr := walkContext(ctx, basePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -1005,7 +1880,7 @@ func (m *ToolsManager) listFiles(args map[string]interface{}) (interface{}, erro
 }
 
 // searchCode recherche un pattern dans les fichiers de code
-func (m *ToolsManager) searchCode(args map[string]interface{}) (interface{}, error) {
+func (m *ToolsManager) searchCode(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	pattern, ok := args["pattern"].(string)
 	if !ok {
 		return nil, fmt.Errorf("pattern is required for search_code")
@@ -1035,27 +1910,21 @@ func (m *ToolsManager) searchCode(args map[string]interface{}) (interface{}, err
 
 	var matches []map[string]interface{}
 
-	// Dossiers à exclure
-	excludeDirs := map[string]bool{
-		"bin": true, ".git": true, "node_modules": true, "vendor": true,
-		"dist": true, "build": true, "__pycache__": true,
-	}
-
-	filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
+	walkErr := walkContext(ctx, basePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
 		// Skip excluded directories
 		if info.IsDir() {
-			if excludeDirs[info.Name()] || strings.HasPrefix(info.Name(), ".") {
+			if codeExcludeDirs[info.Name()] || strings.HasPrefix(info.Name(), ".") {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		// Skip large files (>1MB)
-		if info.Size() > 1024*1024 {
+		if info.Size() > maxCodeFileSize {
 			return nil
 		}
 
@@ -1070,14 +1939,8 @@ func (m *ToolsManager) searchCode(args map[string]interface{}) (interface{}, err
 		}
 
 		// Skip binary files (check for null bytes in first 512 bytes)
-		checkLen := len(content)
-		if checkLen > 512 {
-			checkLen = 512
-		}
-		for i := 0; i < checkLen; i++ {
-			if content[i] == 0 {
-				return nil // Binary file
-			}
+		if isBinary(content) {
+			return nil
 		}
 
 		lines := strings.Split(string(content), "\n")
@@ -1092,6 +1955,9 @@ func (m *ToolsManager) searchCode(args map[string]interface{}) (interface{}, err
 		}
 		return nil
 	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
 
 	return map[string]interface{}{
 		"success":     true,
@@ -1117,6 +1983,9 @@ func (m *ToolsManager) createTool(args map[string]interface{}) (interface{}, err
 	if name == "" || desc == "" || sqlQuery == "" {
 		return nil, fmt.Errorf("name, tool_description, and sql are required for create_tool")
 	}
+	if err := tools.ValidateName(name); err != nil {
+		return nil, err
+	}
 
 	if category == "" {
 		category = "custom"
@@ -1129,8 +1998,14 @@ func (m *ToolsManager) createTool(args map[string]interface{}) (interface{}, err
 		paramsJSON = string(jsonBytes)
 	}
 
+	tx, err := m.toolsDB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Insérer le tool
-	_, err := m.toolsDB.Exec(`
+	_, err = tx.Exec(`
 		INSERT INTO tool_definitions (name, description, input_schema, category, version, enabled, timeout_seconds, created_by, created_at, updated_at)
 		VALUES (?, ?, ?, ?, 1, 1, 30, 'brainloop', strftime('%s', 'now'), strftime('%s', 'now'))
 	`, name, desc, paramsJSON, category)
@@ -1139,7 +2014,7 @@ func (m *ToolsManager) createTool(args map[string]interface{}) (interface{}, err
 	}
 
 	// Insérer l'implémentation
-	_, err = m.toolsDB.Exec(`
+	_, err = tx.Exec(`
 		INSERT INTO tool_implementations (tool_name, step_order, step_name, step_type, sql_template)
 		VALUES (?, 1, 'execute', 'sql', ?)
 	`, name, sqlQuery)
@@ -1147,6 +2022,14 @@ func (m *ToolsManager) createTool(args map[string]interface{}) (interface{}, err
 		return nil, fmt.Errorf("failed to create tool implementation: %w", err)
 	}
 
+	// Version 1 historisée: reste appelable via _version après un upsert_tool
+	if err := tools.SnapshotVersion(tx, name, "brainloop", "create"); err != nil {
+		return nil, fmt.Errorf("failed to snapshot tool version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
 		"success": true,
 		"action":  "create_tool",
@@ -1155,6 +2038,85 @@ func (m *ToolsManager) createTool(args map[string]interface{}) (interface{}, err
 	}, nil
 }
 
+// upsertTool crée ou remplace un tool et ses étapes (provisioning déclaratif)
+func (m *ToolsManager) upsertTool(args map[string]interface{}) (interface{}, error) {
+	if m.toolsDB == nil {
+		return nil, fmt.Errorf("tools database not configured")
+	}
+
+	name, _ := args["name"].(string)
+	desc, _ := args["tool_description"].(string)
+	category, _ := args["category"].(string)
+	if category == "" {
+		category = "custom"
+	}
+
+	steps, err := stepsArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" || desc == "" || len(steps) == 0 {
+		return nil, fmt.Errorf("name, tool_description, and sql or steps are required for upsert_tool")
+	}
+	for i, step := range steps {
+		if step.SQLTemplate == "" {
+			return nil, fmt.Errorf("step %d has no sql", i+1)
+		}
+	}
+
+	paramsJSON := json.RawMessage(`{}`)
+	if params, ok := args["parameters"]; ok {
+		paramsJSON, _ = json.Marshal(params)
+	}
+
+	created, err := tools.UpsertTool(m.toolsDB, name, desc, paramsJSON, category, "brainloop", steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert tool: %w", err)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"action":  "upsert_tool",
+		"name":    name,
+		"created": created,
+		"steps":   len(steps),
+	}, nil
+}
+
+// stepsArg lit les étapes d'un tool depuis steps, ou sql (étape unique "execute")
+func stepsArg(args map[string]interface{}) ([]tools.StepDef, error) {
+	var steps []tools.StepDef
+	if rawSteps, ok := args["steps"].([]interface{}); ok {
+		stepsJSON, _ := json.Marshal(rawSteps)
+		if err := json.Unmarshal(stepsJSON, &steps); err != nil {
+			return nil, fmt.Errorf("invalid steps: %w", err)
+		}
+	} else if sqlQuery, _ := args["sql"].(string); sqlQuery != "" {
+		steps = []tools.StepDef{{Name: "execute", StepType: "sql", SQLTemplate: sqlQuery}}
+	}
+	return steps, nil
+}
+
+// validateTool compile les steps d'un tool (enregistré ou fourni via steps/sql) sans les exécuter
+func (m *ToolsManager) validateTool(args map[string]interface{}) (interface{}, error) {
+	if m.validate == nil {
+		return nil, fmt.Errorf("tool validation not configured")
+	}
+
+	name, _ := args["name"].(string)
+	steps, err := stepsArg(args)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" && len(steps) == 0 {
+		return nil, fmt.Errorf("name, or sql or steps, is required for validate_tool")
+	}
+	toolArgs, _ := args["arguments"].(map[string]interface{})
+	// Les valeurs des arguments sensibles (mêmes motifs que read_config) ne sont pas renvoyées
+	return m.validate.ValidateToolSteps(name, steps, toolArgs, m.secretPatterns(args))
+}
+
 // listTools liste tous les tools disponibles
 func (m *ToolsManager) listTools(args map[string]interface{}) (interface{}, error) {
 	if m.toolsDB == nil {
@@ -1313,6 +2275,164 @@ func (m *ToolsManager) getMetrics() (interface{}, error) {
 	}, nil
 }
 
+// flushMetrics persiste immédiatement la fenêtre de métriques courante
+func (m *ToolsManager) flushMetrics() (interface{}, error) {
+	if m.metrics == nil {
+		return nil, fmt.Errorf("metrics collector not configured")
+	}
+
+	samples, err := m.metrics.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("failed to flush metrics: %w", err)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"action":  "flush_metrics",
+		"samples": samples,
+	}, nil
+}
+
+// listInflight liste les requêtes JSON-RPC en cours (la plus ancienne d'abord)
+func (m *ToolsManager) listInflight() (interface{}, error) {
+	if m.requests == nil {
+		return nil, fmt.Errorf("request registry not configured")
+	}
+
+	requests := m.requests.ListInflight()
+	return map[string]interface{}{
+		"success":  true,
+		"action":   "list_inflight",
+		"requests": requests,
+		"count":    len(requests),
+	}, nil
+}
+
+// cancelRequest annule le contexte d'une requête en cours par son ID JSON-RPC
+func (m *ToolsManager) cancelRequest(args map[string]interface{}) (interface{}, error) {
+	if m.requests == nil {
+		return nil, fmt.Errorf("request registry not configured")
+	}
+
+	var id string
+	switch v := args["request_id"].(type) {
+	case string:
+		id = v
+	case float64:
+		id = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("request_id is required for cancel_request")
+	}
+
+	cancelled := m.requests.CancelRequest(id)
+	if cancelled == 0 {
+		return nil, fmt.Errorf("no in-flight request with id %s", id)
+	}
+	return map[string]interface{}{
+		"success":    true,
+		"action":     "cancel_request",
+		"request_id": id,
+		"cancelled":  cancelled,
+	}, nil
+}
+
+// recoverTool remet en service un tool après correction de la cause de ses échecs
+func (m *ToolsManager) recoverTool(args map[string]interface{}) (interface{}, error) {
+	if m.recovery == nil {
+		return nil, fmt.Errorf("tool recovery not configured")
+	}
+
+	name, _ := args["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("name is required for recover_tool")
+	}
+	requeue := true
+	if v, ok := args["requeue"].(bool); ok {
+		requeue = v
+	}
+	return m.recovery.RecoverTool(name, requeue)
+}
+
+// llmUsage résume la consommation LLM (table llm_usage de output.db) par provider et par jour
+func (m *ToolsManager) llmUsage(args map[string]interface{}) (interface{}, error) {
+	if m.outputDB == nil {
+		return nil, fmt.Errorf("output database not configured")
+	}
+
+	days := 30
+	if d, ok := args["days"].(float64); ok && d > 0 {
+		days = int(d)
+	}
+	since := time.Now().AddDate(0, 0, -days).Unix()
+
+	rows, err := m.outputDB.Query(`
+		SELECT provider, date(created_at, 'unixepoch') AS day,
+		       COUNT(*), SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_usd)
+		FROM llm_usage
+		WHERE created_at >= ?
+		GROUP BY provider, day
+		ORDER BY day DESC, provider`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query llm_usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []map[string]interface{}{}
+	var totalCalls, totalPrompt, totalCompletion int64
+	var totalCost float64
+	for rows.Next() {
+		var provider, day string
+		var calls, promptTokens, completionTokens int64
+		var cost float64
+		if err := rows.Scan(&provider, &day, &calls, &promptTokens, &completionTokens, &cost); err != nil {
+			return nil, err
+		}
+		usage = append(usage, map[string]interface{}{
+			"provider":          provider,
+			"day":               day,
+			"calls":             calls,
+			"prompt_tokens":     promptTokens,
+			"completion_tokens": completionTokens,
+			"cost_usd":          cost,
+		})
+		totalCalls += calls
+		totalPrompt += promptTokens
+		totalCompletion += completionTokens
+		totalCost += cost
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"action":  "llm_usage",
+		"days":    days,
+		"usage":   usage,
+		"totals": map[string]interface{}{
+			"calls":             totalCalls,
+			"prompt_tokens":     totalPrompt,
+			"completion_tokens": totalCompletion,
+			"cost_usd":          totalCost,
+		},
+	}, nil
+}
+
+// exportToolsSchema retourne le catalogue complet des tools au format MCP
+func (m *ToolsManager) exportToolsSchema() (interface{}, error) {
+	if m.catalog == nil {
+		return nil, fmt.Errorf("tool catalog not configured")
+	}
+
+	catalog := m.catalog.ToolCatalog()
+	return map[string]interface{}{
+		"success": true,
+		"action":  "export_tools_schema",
+		"tools":   catalog,
+		"count":   len(catalog),
+	}, nil
+}
+
 // getStats retourne les statistiques d'usage depuis processed_log
 func (m *ToolsManager) getStats() (interface{}, error) {
 	if m.execDB == nil {
@@ -1469,24 +2589,14 @@ func extractGoImports(code string) []string {
 	return imports
 }
 
-func extractGoFunctions(code string) []string {
-	var functions []string
+func extractGoFunctions(code string) []Symbol {
 	funcRegex := regexp.MustCompile(`func\s+(?:\([^)]+\)\s+)?(\w+)\s*\(`)
-
-	for _, match := range funcRegex.FindAllStringSubmatch(code, -1) {
-		functions = append(functions, match[1])
-	}
-	return functions
+	return matchSymbols(code, funcRegex, "func")
 }
 
-func extractGoTypes(code string) []string {
-	var types []string
+func extractGoTypes(code string) []Symbol {
 	typeRegex := regexp.MustCompile(`type\s+(\w+)\s+(?:struct|interface)`)
-
-	for _, match := range typeRegex.FindAllStringSubmatch(code, -1) {
-		types = append(types, match[1])
-	}
-	return types
+	return matchSymbols(code, typeRegex, "type")
 }
 
 func detectGoPatterns(code string) []string {
@@ -1528,24 +2638,14 @@ func extractPythonImports(code string) []string {
 	return imports
 }
 
-func extractPythonFunctions(code string) []string {
-	var functions []string
+func extractPythonFunctions(code string) []Symbol {
 	funcRegex := regexp.MustCompile(`def\s+(\w+)\s*\(`)
-
-	for _, match := range funcRegex.FindAllStringSubmatch(code, -1) {
-		functions = append(functions, match[1])
-	}
-	return functions
+	return matchSymbols(code, funcRegex, "func")
 }
 
-func extractPythonClasses(code string) []string {
-	var classes []string
+func extractPythonClasses(code string) []Symbol {
 	classRegex := regexp.MustCompile(`class\s+(\w+)`)
-
-	for _, match := range classRegex.FindAllStringSubmatch(code, -1) {
-		classes = append(classes, match[1])
-	}
-	return classes
+	return matchSymbols(code, classRegex, "class")
 }
 
 func extractSQLTables(code string) []string {
@@ -1581,7 +2681,18 @@ func extractGenericFunctions(code string) []string {
 
 func extractKeys(data interface{}, prefix string) []string {
 	var keys []string
+	walkConfig(data, prefix, func(key string, _ interface{}, isIndex bool) bool {
+		if !isIndex {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	return keys
+}
 
+// walkConfig parcourt une config parsée en nommant chaque nœud (a.b[0].c)
+// visit reçoit isIndex=true pour les éléments de tableau; retourner false arrête le parcours
+func walkConfig(data interface{}, prefix string, visit func(key string, value interface{}, isIndex bool) bool) bool {
 	switch v := data.(type) {
 	case map[string]interface{}:
 		for key, value := range v {
@@ -1589,17 +2700,38 @@ func extractKeys(data interface{}, prefix string) []string {
 			if prefix != "" {
 				fullKey = prefix + "." + key
 			}
-			keys = append(keys, fullKey)
-			keys = append(keys, extractKeys(value, fullKey)...)
+			if !visit(fullKey, value, false) || !walkConfig(value, fullKey, visit) {
+				return false
+			}
 		}
 	case []interface{}:
 		for i, item := range v {
 			arrayKey := fmt.Sprintf("%s[%d]", prefix, i)
-			keys = append(keys, extractKeys(item, arrayKey)...)
+			if !visit(arrayKey, item, true) || !walkConfig(item, arrayKey, visit) {
+				return false
+			}
 		}
 	}
+	return true
+}
 
-	return keys
+// lookupConfigPath retourne la valeur à un chemin JSONPath simplifié (services.db.port, items[0].name)
+func lookupConfigPath(data interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, true
+	}
+
+	var found interface{}
+	ok := false
+	walkConfig(data, "", func(key string, value interface{}, _ bool) bool {
+		if key == path {
+			found, ok = value, true
+			return false
+		}
+		return true
+	})
+	return found, ok
 }
 
 func unique(slice []string) []string {
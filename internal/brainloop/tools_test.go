@@ -0,0 +1,54 @@
+package brainloop
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadConfigRedactsKeyPath(t *testing.T) {
+	// validatePath n'accepte que le dossier courant: fichier temporaire dans le package
+	f, err := os.CreateTemp(".", "read_config_*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(f.Name()) })
+	if _, err := f.WriteString(`{
+		"credentials": {"github": {"user": "octo", "scopes": ["repo"]}},
+		"services": {"db": {"port": 5432, "password": "hunter2", "replicas": [{"host": "a", "token": "t1"}]}}
+	}`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		name    string
+		keyPath string
+		want    interface{}
+	}{
+		{"secret leaf", "services.db.password", redactedValue},
+		{"secret parent", "credentials.github", redactedValue},
+		{"below secret parent", "credentials.github.user", redactedValue},
+		{"index below secret parent", "credentials.github.scopes[0]", redactedValue},
+		{"plain value", "services.db.port", float64(5432)},
+		{"nested secrets in subtree", "services.db", map[string]interface{}{
+			"port":     float64(5432),
+			"password": redactedValue,
+			"replicas": []interface{}{map[string]interface{}{"host": "a", "token": redactedValue}},
+		}},
+	}
+
+	m := NewToolsManager()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.readConfig(map[string]interface{}{"path": f.Name(), "key_path": tt.keyPath})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := result.(map[string]interface{})
+			if !reflect.DeepEqual(got["value"], tt.want) {
+				t.Errorf("value = %#v, want %#v", got["value"], tt.want)
+			}
+		})
+	}
+}
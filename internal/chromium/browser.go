@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -35,6 +36,46 @@ type Browser struct {
 	currentTargetID  string
 	currentSessionID string
 
+	// Session de l'onglet temporaire de WithScratchPage: Call y route les commandes de page (protégé par mu)
+	scratchSessionID string
+
+	// Contexte de l'opération en cours (budget global d'un tools/call)
+	opCtx context.Context
+
+	// Contexte d'exécution du frame choisi par SetFrame (0 = frame principal, protégé par mu)
+	frameContextID int
+
+	// Handlers d'événements CDP par méthode (ex: "Fetch.authRequired")
+	handlers map[string][]EventHandler
+
+	// Authentification proxy (EnableProxyAuth): Fetch.enable envoyé une fois par session attachée
+	proxyWatch    sync.Once
+	proxyUser     string          // Protégé par mu
+	proxyPass     string          // Protégé par mu
+	fetchSessions map[string]bool // Sessions où Fetch est actif, nil sans proxy auth (protégé par mu)
+
+	// Activité réseau suivie pour WaitNetworkIdle (compteurs atomiques)
+	netWatch    sync.Once
+	netRequests int64 // Network.requestWillBeSent reçus depuis le début du suivi
+	netLastAt   int64 // UnixNano du dernier Network.requestWillBeSent
+
+	// Navigation attendue par NavigateWithTimeout (Page.loadEventFired, Page.frameStoppedLoading)
+	loadWatch sync.Once
+	loadFrame string        // frameId de la navigation en cours ("" tant que Page.navigate n'a pas répondu)
+	loadDone  chan struct{} // Fermé à la fin du chargement, nil hors navigation
+
+	// Messages console capturés pour GetConsoleLogs (console.go)
+	consoleWatch   sync.Once
+	consoleEnabled bool // Runtime.enable et Log.enable envoyés (protégé par mu)
+	consoleMu      sync.Mutex
+	consoleLogs    []ConsoleLog
+
+	// Viewport émulé par SetViewport, rétabli après ScreenshotFullPage (protégé par mu)
+	viewport *Viewport
+
+	// Fermé quand readLoop s'arrête (connexion WebSocket perdue)
+	readDone chan struct{}
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -54,10 +95,15 @@ type CDPError struct {
 
 // Event représente un événement CDP
 type Event struct {
-	Method string          `json:"method"`
-	Params json.RawMessage `json:"params"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	SessionID string          `json:"sessionId,omitempty"`
 }
 
+// EventHandler traite un événement CDP
+// Exécuté dans sa propre goroutine: il peut émettre des appels CDP
+type EventHandler func(evt Event)
+
 // Config configuration pour lancer Chromium
 type Config struct {
 	Headless    bool
@@ -66,6 +112,7 @@ type Config struct {
 	WindowSize  string // "1920,1080"
 	ExtraArgs   []string
 	ChromePath  string // Chemin vers l'exécutable (depuis Discovery)
+	Proxy       string // Serveur proxy (ex: "http://host:3128", "socks5://host:1080")
 }
 
 // DefaultConfig retourne la configuration par défaut
@@ -128,6 +175,10 @@ func Launch(cfg *Config) (*Browser, error) {
 		args = append(args, fmt.Sprintf("--window-size=%s", cfg.WindowSize))
 	}
 
+	if cfg.Proxy != "" {
+		args = append(args, fmt.Sprintf("--proxy-server=%s", cfg.Proxy))
+	}
+
 	args = append(args, cfg.ExtraArgs...)
 
 	// Lancer Chromium
@@ -162,6 +213,7 @@ func Launch(cfg *Config) (*Browser, error) {
 		debugPort:   cfg.DebugPort,
 		userDataDir: cfg.UserDataDir,
 		pending:     make(map[int64]chan *Response),
+		readDone:    make(chan struct{}),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
@@ -191,6 +243,7 @@ func Connect(debugPort int) (*Browser, error) {
 		conn:      conn,
 		debugPort: debugPort,
 		pending:   make(map[int64]chan *Response),
+		readDone:  make(chan struct{}),
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -202,6 +255,8 @@ func Connect(debugPort int) (*Browser, error) {
 
 // readLoop lit les messages WebSocket
 func (b *Browser) readLoop() {
+	defer close(b.readDone)
+
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -223,19 +278,224 @@ func (b *Browser) readLoop() {
 				delete(b.pending, resp.ID)
 			}
 			b.mu.Unlock()
+			continue
+		}
+
+		var evt Event
+		if err := json.Unmarshal(message, &evt); err == nil && evt.Method != "" {
+			b.handleEvent(evt)
+		}
+	}
+}
+
+// OnEvent enregistre un handler pour une méthode d'événement CDP
+func (b *Browser) OnEvent(method string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.handlers == nil {
+		b.handlers = make(map[string][]EventHandler)
+	}
+	b.handlers[method] = append(b.handlers[method], handler)
+}
+
+// handleEvent distribue un événement aux handlers enregistrés
+// Chaque handler tourne dans une goroutine pour ne pas bloquer readLoop
+// (qui doit rester libre de livrer les réponses aux appels du handler)
+func (b *Browser) handleEvent(evt Event) {
+	b.mu.Lock()
+	handlers := b.handlers[evt.Method]
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		go h(evt)
+	}
+}
+
+// callForEvent répond à un événement sur la session qui l'a émis
+func (b *Browser) callForEvent(evt Event, method string, params interface{}) (json.RawMessage, error) {
+	if evt.SessionID != "" {
+		return b.CallWithSession(evt.SessionID, method, params)
+	}
+	return b.Call(method, params)
+}
+
+// EnableProxyAuth répond automatiquement aux demandes d'authentification du proxy
+// via le domaine Fetch (Fetch.authRequired); les autres défis sont laissés au navigateur
+// Fetch est activé sur chaque session de page attachée (courante puis suivantes), jamais au niveau browser:
+// un même défi n'est ainsi traité qu'une fois
+func (b *Browser) EnableProxyAuth(username, password string) error {
+	b.mu.Lock()
+	b.proxyUser, b.proxyPass = username, password
+	if b.fetchSessions == nil {
+		b.fetchSessions = make(map[string]bool)
+	}
+	current := b.currentSessionID
+	b.mu.Unlock()
+
+	b.proxyWatch.Do(b.watchProxyAuth)
+
+	if current != "" {
+		if err := b.enableFetch(current); err != nil {
+			return fmt.Errorf("failed to enable proxy auth: %w", err)
+		}
+	}
+	return nil
+}
+
+// watchProxyAuth enregistre les handlers Fetch et le suivi des sessions attachées
+func (b *Browser) watchProxyAuth() {
+	b.OnEvent("Fetch.requestPaused", func(evt Event) {
+		var p struct {
+			RequestID string `json:"requestId"`
+		}
+		if json.Unmarshal(evt.Params, &p) == nil {
+			b.callForEvent(evt, "Fetch.continueRequest", map[string]interface{}{"requestId": p.RequestID})
+		}
+	})
+
+	b.OnEvent("Fetch.authRequired", func(evt Event) {
+		var p struct {
+			RequestID     string `json:"requestId"`
+			AuthChallenge struct {
+				Source string `json:"source"`
+			} `json:"authChallenge"`
+		}
+		if json.Unmarshal(evt.Params, &p) != nil {
+			return
+		}
+
+		response := map[string]interface{}{"response": "Default"}
+		if p.AuthChallenge.Source == "Proxy" {
+			b.mu.Lock()
+			response = map[string]interface{}{
+				"response": "ProvideCredentials",
+				"username": b.proxyUser,
+				"password": b.proxyPass,
+			}
+			b.mu.Unlock()
+		}
+		b.callForEvent(evt, "Fetch.continueWithAuth", map[string]interface{}{
+			"requestId":             p.RequestID,
+			"authChallengeResponse": response,
+		})
+	})
+
+	// Chaque session attachée par la suite a son propre domaine Fetch
+	b.OnEvent("Target.attachedToTarget", func(evt Event) {
+		var p struct {
+			SessionID string `json:"sessionId"`
+		}
+		if json.Unmarshal(evt.Params, &p) == nil && p.SessionID != "" {
+			b.enableFetch(p.SessionID)
+		}
+	})
+	b.OnEvent("Target.detachedFromTarget", func(evt Event) {
+		var p struct {
+			SessionID string `json:"sessionId"`
+		}
+		if json.Unmarshal(evt.Params, &p) == nil {
+			b.mu.Lock()
+			delete(b.fetchSessions, p.SessionID)
+			b.mu.Unlock()
 		}
-		// Les événements sont ignorés pour l'instant
+	})
+}
+
+// proxyFetchParams paramètres de Fetch.enable pour l'authentification proxy
+var proxyFetchParams = map[string]interface{}{
+	"handleAuthRequests": true,
+	"patterns":           []map[string]interface{}{{"urlPattern": "*"}},
+}
+
+// enableFetch active Fetch sur sessionID si l'authentification proxy est active et que ce n'est pas déjà fait
+func (b *Browser) enableFetch(sessionID string) error {
+	b.mu.Lock()
+	if b.fetchSessions == nil || b.fetchSessions[sessionID] {
+		b.mu.Unlock()
+		return nil
+	}
+	b.fetchSessions[sessionID] = true
+	b.mu.Unlock()
+
+	if _, err := b.CallWithSession(sessionID, "Fetch.enable", proxyFetchParams); err != nil {
+		b.mu.Lock()
+		delete(b.fetchSessions, sessionID)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// livenessTimeout délai max du ping de vérification du browser
+const livenessTimeout = 3 * time.Second
+
+// Alive vérifie que le browser répond encore (connexion ouverte et ping CDP)
+func (b *Browser) Alive() bool {
+	select {
+	case <-b.readDone:
+		return false
+	default:
 	}
+	_, err := b.callTimeout("Target.getTargets", nil, livenessTimeout)
+	return err == nil
 }
 
+// callDefaultTimeout délai de réponse d'une commande CDP envoyée par Call
+const callDefaultTimeout = 30 * time.Second
+
 // Call envoie une commande CDP et attend la réponse
 func (b *Browser) Call(method string, params interface{}) (json.RawMessage, error) {
+	return b.callTimeout(method, params, callDefaultTimeout)
+}
+
+// callTimeout envoie une commande CDP et attend la réponse au plus timeout
+func (b *Browser) callTimeout(method string, params interface{}, timeout time.Duration) (json.RawMessage, error) {
+	return b.send(b.operationContext(), b.callSession(method), method, params, timeout)
+}
+
+// callSession session d'une commande envoyée par Call: celle de l'onglet temporaire
+// pendant WithScratchPage (sauf domaines Target et Browser), aucune sinon
+func (b *Browser) callSession(method string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.scratchSessionID == "" || strings.HasPrefix(method, "Target.") || strings.HasPrefix(method, "Browser.") {
+		return ""
+	}
+	return b.scratchSessionID
+}
+
+// CallContext envoie une commande CDP bornée par ctx plutôt que par SetOperationContext
+// sessionID vide pour une commande de niveau browser
+func (b *Browser) CallContext(ctx context.Context, sessionID, method string, params interface{}) (json.RawMessage, error) {
+	return b.send(ctx, sessionID, method, params, callDefaultTimeout)
+}
+
+// operationContext retourne le contexte posé par SetOperationContext (Background sinon)
+func (b *Browser) operationContext() context.Context {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.opCtx == nil {
+		return context.Background()
+	}
+	return b.opCtx
+}
+
+// send envoie une commande CDP (sur sessionID si non vide) et attend la réponse
+// au plus timeout; l'attente est abandonnée dès que ctx est annulé ou expiré
+func (b *Browser) send(ctx context.Context, sessionID, method string, params interface{}, timeout time.Duration) (json.RawMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	id := atomic.AddInt64(&b.msgID, 1)
 
 	msg := map[string]interface{}{
 		"id":     id,
 		"method": method,
 	}
+	if sessionID != "" {
+		msg["sessionId"] = sessionID
+	}
 	if params != nil {
 		msg["params"] = params
 	}
@@ -266,13 +526,23 @@ func (b *Browser) Call(method string, params interface{}) (json.RawMessage, erro
 			return nil, fmt.Errorf("CDP error %d: %s", resp.Error.Code, resp.Error.Message)
 		}
 		return resp.Result, nil
-	case <-time.After(30 * time.Second):
+	case <-time.After(timeout):
 		b.mu.Lock()
 		delete(b.pending, id)
 		b.mu.Unlock()
 		return nil, fmt.Errorf("timeout waiting for response")
 	case <-b.ctx.Done():
 		return nil, b.ctx.Err()
+	case <-b.readDone:
+		b.mu.Lock()
+		delete(b.pending, id)
+		b.mu.Unlock()
+		return nil, fmt.Errorf("browser connection closed")
+	case <-ctx.Done():
+		b.mu.Lock()
+		delete(b.pending, id)
+		b.mu.Unlock()
+		return nil, ctx.Err()
 	}
 }
 
@@ -346,6 +616,11 @@ func (b *Browser) AttachToTarget(targetID string) (string, error) {
 	b.currentSessionID = resp.SessionID
 	b.mu.Unlock()
 
+	// Sans attendre Target.attachedToTarget: la première requête de la page doit déjà passer par Fetch
+	if err := b.enableFetch(resp.SessionID); err != nil {
+		return "", fmt.Errorf("failed to enable proxy auth: %w", err)
+	}
+
 	return resp.SessionID, nil
 }
 
@@ -359,51 +634,7 @@ func (b *Browser) CloseTarget(targetID string) error {
 
 // CallWithSession envoie une commande CDP avec un sessionId spécifique
 func (b *Browser) CallWithSession(sessionID, method string, params interface{}) (json.RawMessage, error) {
-	id := atomic.AddInt64(&b.msgID, 1)
-
-	msg := map[string]interface{}{
-		"id":        id,
-		"method":    method,
-		"sessionId": sessionID,
-	}
-	if params != nil {
-		msg["params"] = params
-	}
-
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return nil, err
-	}
-
-	// Créer le canal de réponse
-	ch := make(chan *Response, 1)
-	b.mu.Lock()
-	b.pending[id] = ch
-	b.mu.Unlock()
-
-	// Envoyer le message
-	if err := b.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		b.mu.Lock()
-		delete(b.pending, id)
-		b.mu.Unlock()
-		return nil, err
-	}
-
-	// Attendre la réponse avec timeout
-	select {
-	case resp := <-ch:
-		if resp.Error != nil {
-			return nil, fmt.Errorf("CDP error %d: %s", resp.Error.Code, resp.Error.Message)
-		}
-		return resp.Result, nil
-	case <-time.After(30 * time.Second):
-		b.mu.Lock()
-		delete(b.pending, id)
-		b.mu.Unlock()
-		return nil, fmt.Errorf("timeout waiting for response")
-	case <-b.ctx.Done():
-		return nil, b.ctx.Err()
-	}
+	return b.send(b.operationContext(), sessionID, method, params, callDefaultTimeout)
 }
 
 // EnsurePageSession s'assure qu'une session page est active
@@ -448,6 +679,14 @@ func (b *Browser) EnsurePageSession() (string, error) {
 	return sessionID, nil
 }
 
+// SetOperationContext borne les appels CDP suivants par ctx (nil pour retirer la borne)
+// Un appel en attente est abandonné dès que ctx est annulé ou expiré
+func (b *Browser) SetOperationContext(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opCtx = ctx
+}
+
 // GetCurrentSession retourne le sessionId actuel
 func (b *Browser) GetCurrentSession() string {
 	b.mu.Lock()
@@ -462,19 +701,112 @@ func (b *Browser) GetCurrentTargetID() string {
 	return b.currentTargetID
 }
 
-// Navigate navigue vers une URL
+// DebugPort retourne le port de débogage distant du browser
+func (b *Browser) DebugPort() int {
+	return b.debugPort
+}
+
+// Launched indique si le processus browser a été lancé par holow-mcp (sinon: connect)
+func (b *Browser) Launched() bool {
+	return b.cmd != nil
+}
+
+// defaultNavigateTimeout attente max du chargement pour Navigate
+const defaultNavigateTimeout = 30 * time.Second
+
+// Navigate navigue vers une URL et attend son chargement (au plus defaultNavigateTimeout)
 func (b *Browser) Navigate(url string) error {
-	// Activer les événements Page
-	b.Call("Page.enable", nil)
+	_, err := b.NavigateWithTimeout(url, defaultNavigateTimeout)
+	return err
+}
+
+// NavigateWithTimeout navigue vers une URL et attend l'événement load du document final
+// (redirections HTTP suivies par le browser). loaded est false si timeout a expiré avant:
+// la page reste utilisable mais peut être incomplète
+func (b *Browser) NavigateWithTimeout(url string, timeout time.Duration) (loaded bool, err error) {
+	b.watchLoad()
+	if _, err := b.Call("Page.enable", nil); err != nil {
+		return false, fmt.Errorf("failed to enable page events: %w", err)
+	}
+
+	done := make(chan struct{})
+	b.mu.Lock()
+	b.loadFrame, b.loadDone = "", done
+	opCtx := b.opCtx
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		if b.loadDone == done {
+			b.loadDone = nil
+		}
+		b.mu.Unlock()
+	}()
 
-	_, err := b.Call("Page.navigate", map[string]string{"url": url})
+	result, err := b.Call("Page.navigate", map[string]string{"url": url})
 	if err != nil {
-		return err
+		return false, err
 	}
+	var nav struct {
+		FrameID   string `json:"frameId"`
+		LoaderID  string `json:"loaderId"`
+		ErrorText string `json:"errorText"`
+	}
+	json.Unmarshal(result, &nav)
+	if nav.ErrorText != "" {
+		return false, fmt.Errorf("navigation to %s failed: %s", url, nav.ErrorText)
+	}
+	if nav.LoaderID == "" {
+		return true, nil // Navigation dans le même document (ancre): aucun chargement
+	}
+	b.mu.Lock()
+	b.loadFrame = nav.FrameID
+	b.mu.Unlock()
 
-	// Attendre que la page charge (simple sleep pour éviter complexité événements)
-	time.Sleep(2 * time.Second)
-	return nil
+	var opDone <-chan struct{}
+	if opCtx != nil {
+		opDone = opCtx.Done()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true, nil
+	case <-timer.C:
+		return false, nil
+	case <-opDone:
+		return false, opCtx.Err()
+	case <-b.readDone:
+		return false, fmt.Errorf("browser connection closed")
+	}
+}
+
+// watchLoad enregistre (une fois) les handlers de fin de chargement utilisés par NavigateWithTimeout
+func (b *Browser) watchLoad() {
+	b.loadWatch.Do(func() {
+		b.OnEvent("Page.loadEventFired", func(evt Event) {
+			b.signalLoad("")
+		})
+		b.OnEvent("Page.frameStoppedLoading", func(evt Event) {
+			var p struct {
+				FrameID string `json:"frameId"`
+			}
+			if json.Unmarshal(evt.Params, &p) == nil && p.FrameID != "" {
+				b.signalLoad(p.FrameID)
+			}
+		})
+	})
+}
+
+// signalLoad termine l'attente de la navigation en cours
+// frameID "" = load du document principal; sinon seul l'arrêt du frame navigué compte
+func (b *Browser) signalLoad(frameID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.loadDone == nil || (frameID != "" && frameID != b.loadFrame) {
+		return
+	}
+	close(b.loadDone)
+	b.loadDone = nil
 }
 
 // Screenshot prend une capture d'écran
@@ -510,77 +842,411 @@ func (b *Browser) Screenshot(format string, quality int, fullPage bool) ([]byte,
 	return base64.StdEncoding.DecodeString(resp.Data)
 }
 
-// Evaluate exécute du JavaScript
-func (b *Browser) Evaluate(expression string) (interface{}, error) {
-	result, err := b.Call("Runtime.evaluate", map[string]interface{}{
-		"expression":    expression,
-		"returnByValue": true,
-	})
+// ScreenshotElement capture le premier élément correspondant au sélecteur CSS
+// Le rectangle (coordonnées de la page) est passé en clip: l'élément est capturé même hors du viewport
+func (b *Browser) ScreenshotElement(selector string, format string, quality int) ([]byte, error) {
+	escaped, err := b.requireElement(selector)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp struct {
-		Result struct {
-			Value interface{} `json:"value"`
-			Type  string      `json:"type"`
-		} `json:"result"`
-		ExceptionDetails *struct {
-			Text string `json:"text"`
-		} `json:"exceptionDetails"`
-	}
-
-	if err := json.Unmarshal(result, &resp); err != nil {
-		return nil, err
+	value, err := b.Evaluate(fmt.Sprintf(`(() => {
+		const r = document.querySelector('%s').getBoundingClientRect();
+		return {x: r.left + window.scrollX, y: r.top + window.scrollY, width: r.width, height: r.height};
+	})()`, escaped))
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure element: %w", err)
 	}
-
-	if resp.ExceptionDetails != nil {
-		return nil, fmt.Errorf("JS error: %s", resp.ExceptionDetails.Text)
+	rect, _ := value.(map[string]interface{})
+	x, _ := rect["x"].(float64)
+	y, _ := rect["y"].(float64)
+	width, _ := rect["width"].(float64)
+	height, _ := rect["height"].(float64)
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("element has zero size: %s", selector)
 	}
 
-	return resp.Result.Value, nil
-}
-
-// GetHTML retourne le HTML de la page
-func (b *Browser) GetHTML() (string, error) {
-	result, err := b.Call("DOM.getDocument", map[string]interface{}{
-		"depth": -1,
-	})
-	if err != nil {
-		return "", err
+	if format == "" {
+		format = "png"
 	}
-
-	var doc struct {
-		Root struct {
-			NodeID int `json:"nodeId"`
-		} `json:"root"`
+	params := map[string]interface{}{
+		"format":                format,
+		"captureBeyondViewport": true,
+		"clip": map[string]interface{}{
+			"x":      x,
+			"y":      y,
+			"width":  width,
+			"height": height,
+			"scale":  1,
+		},
 	}
-	if err := json.Unmarshal(result, &doc); err != nil {
-		return "", err
+	if format == "jpeg" && quality > 0 {
+		params["quality"] = quality
 	}
 
-	result, err = b.Call("DOM.getOuterHTML", map[string]int{
-		"nodeId": doc.Root.NodeID,
-	})
+	result, err := b.Call("Page.captureScreenshot", params)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var html struct {
-		OuterHTML string `json:"outerHTML"`
+	var resp struct {
+		Data string `json:"data"`
 	}
-	if err := json.Unmarshal(result, &html); err != nil {
-		return "", err
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return nil, err
 	}
+	return base64.StdEncoding.DecodeString(resp.Data)
+}
 
-	return html.OuterHTML, nil
+// TabScreenshot capture d'un onglet; Err est renseigné si cet onglet n'a pas pu être capturé
+type TabScreenshot struct {
+	TargetInfo
+	Data []byte
+	Err  error
 }
 
-// escapeJSString échappe une chaîne pour insertion sécurisée dans du JavaScript
-// Protège contre les injections XSS en échappant tous les caractères dangereux
-func escapeJSString(s string) string {
-	var result strings.Builder
-	result.Grow(len(s) + 10) // Pré-allouer un peu plus
+// ScreenshotAll capture chaque onglet (target page) via une session dédiée
+// La session et l'onglet actifs avant l'appel sont restaurés ensuite
+func (b *Browser) ScreenshotAll(format string, quality int) ([]TabScreenshot, error) {
+	targets, err := b.GetTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	prevTarget, prevSession := b.currentTargetID, b.currentSessionID
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.currentTargetID, b.currentSessionID = prevTarget, prevSession
+		b.mu.Unlock()
+		if prevTarget != "" {
+			b.Call("Target.activateTarget", map[string]interface{}{"targetId": prevTarget})
+		}
+	}()
+
+	params := map[string]interface{}{"format": format}
+	if format == "jpeg" && quality > 0 {
+		params["quality"] = quality
+	}
+
+	var shots []TabScreenshot
+	for _, t := range targets {
+		if t.Type != "page" {
+			continue
+		}
+		shot := TabScreenshot{TargetInfo: t}
+		shot.Data, shot.Err = b.captureTarget(t.TargetID, params)
+		shots = append(shots, shot)
+	}
+	return shots, nil
+}
+
+// WithScratchPage exécute fn dans un onglet temporaire, fermé ensuite
+// Les commandes de page de fn y sont envoyées: l'onglet de l'utilisateur n'est pas modifié,
+// puis la session et l'onglet actifs avant l'appel sont restaurés (comme ScreenshotAll)
+func (b *Browser) WithScratchPage(fn func() error) error {
+	b.mu.Lock()
+	prevTarget, prevSession := b.currentTargetID, b.currentSessionID
+	prevFrame := b.frameContextID
+	b.mu.Unlock()
+
+	targetID, err := b.CreateTarget("about:blank")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch page: %w", err)
+	}
+	defer func() {
+		b.mu.Lock()
+		b.scratchSessionID = ""
+		b.currentTargetID, b.currentSessionID = prevTarget, prevSession
+		b.frameContextID = prevFrame
+		b.mu.Unlock()
+		// Hors budget de l'opération: l'onglet doit être fermé même après expiration
+		b.send(context.Background(), "", "Target.closeTarget", map[string]interface{}{"targetId": targetID}, callDefaultTimeout)
+		if prevTarget != "" {
+			b.send(context.Background(), "", "Target.activateTarget", map[string]interface{}{"targetId": prevTarget}, callDefaultTimeout)
+		}
+	}()
+
+	sessionID, err := b.AttachToTarget(targetID)
+	if err != nil {
+		return fmt.Errorf("failed to attach to scratch page: %w", err)
+	}
+	b.mu.Lock()
+	b.scratchSessionID = sessionID
+	b.frameContextID = 0 // Le contexte choisi par SetFrame appartient à l'onglet de l'utilisateur
+	b.mu.Unlock()
+
+	return fn()
+}
+
+// captureTarget active un onglet, s'y attache le temps d'une capture puis s'en détache
+func (b *Browser) captureTarget(targetID string, params map[string]interface{}) ([]byte, error) {
+	// Un onglet en arrière-plan ne produit pas toujours de frame
+	b.Call("Target.activateTarget", map[string]interface{}{"targetId": targetID})
+
+	sessionID, err := b.AttachToTarget(targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Call("Target.detachFromTarget", map[string]interface{}{"sessionId": sessionID})
+
+	result, err := b.CallWithSession(sessionID, "Page.captureScreenshot", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Data)
+}
+
+// maxFullPageHeight hauteur max (px CSS) d'une capture pleine page fiable
+// Au-delà, Chrome dépasse ses limites de texture et produit une image vide
+const maxFullPageHeight = 16384
+
+// fullPageSettleDelay laisse la page se remettre en page après le redimensionnement
+const fullPageSettleDelay = 300 * time.Millisecond
+
+// ScreenshotFullPage capture toute la page en agrandissant le viewport à la hauteur du document
+// Plus fiable que captureBeyondViewport avec en-têtes collants ou contenu virtualisé
+// Retourne aussi la hauteur capturée (bornée à maxFullPageHeight)
+func (b *Browser) ScreenshotFullPage(format string, quality int) ([]byte, int, error) {
+	value, err := b.Evaluate(`({
+		width: window.innerWidth,
+		height: Math.max(
+			document.documentElement.scrollHeight,
+			document.body ? document.body.scrollHeight : 0
+		)
+	})`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to measure page: %w", err)
+	}
+	dims, _ := value.(map[string]interface{})
+	width, _ := dims["width"].(float64)
+	height, _ := dims["height"].(float64)
+	if width <= 0 || height <= 0 {
+		return nil, 0, fmt.Errorf("failed to measure page: unexpected dimensions %v", value)
+	}
+	if height > maxFullPageHeight {
+		height = maxFullPageHeight
+	}
+
+	// Garder les métriques d'appareil émulées par SetViewport (facteur d'échelle, mobile)
+	vp := Viewport{Width: int(width), Height: int(height)}
+	b.mu.Lock()
+	if b.viewport != nil {
+		vp.DeviceScaleFactor, vp.Mobile = b.viewport.DeviceScaleFactor, b.viewport.Mobile
+	}
+	b.mu.Unlock()
+	if err := b.applyViewport(vp); err != nil {
+		return nil, 0, fmt.Errorf("failed to resize viewport: %w", err)
+	}
+	// Toujours restaurer le viewport d'origine, même en cas d'échec de capture
+	defer b.restoreViewport()
+
+	time.Sleep(fullPageSettleDelay)
+
+	data, err := b.Screenshot(format, quality, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, int(height), nil
+}
+
+// Evaluate exécute du JavaScript et retourne le résultat
+func (b *Browser) Evaluate(expression string) (interface{}, error) {
+	return b.EvaluateWithOptions(expression, false)
+}
+
+// EvalError décrit une exception JavaScript levée pendant Runtime.evaluate
+type EvalError struct {
+	Text        string
+	Description string
+	Line        int
+	Column      int
+	Stack       []string
+}
+
+// Error formate l'exception avec sa position et sa pile d'appels
+func (e *EvalError) Error() string {
+	msg := e.Text
+	if e.Description != "" {
+		msg = e.Description
+	}
+	out := fmt.Sprintf("JS error at %d:%d: %s", e.Line, e.Column, msg)
+	if len(e.Stack) > 0 && !strings.Contains(msg, "\n    at ") {
+		out += "\n    at " + strings.Join(e.Stack, "\n    at ")
+	}
+	return out
+}
+
+// EvaluateWithOptions exécute du JavaScript; si awaitPromise est vrai,
+// une expression retournant une Promise est résolue avant de renvoyer sa valeur
+func (b *Browser) EvaluateWithOptions(expression string, awaitPromise bool) (interface{}, error) {
+	return b.evaluateTimeout(expression, awaitPromise, callDefaultTimeout)
+}
+
+// evaluateTimeout implémente EvaluateWithOptions avec un délai de réponse CDP explicite
+// (Promise qui peut rester en attente plus longtemps que callDefaultTimeout)
+func (b *Browser) evaluateTimeout(expression string, awaitPromise bool, timeout time.Duration) (interface{}, error) {
+	params := map[string]interface{}{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  awaitPromise,
+	}
+	if contextID := b.frameContext(); contextID != 0 {
+		params["contextId"] = contextID
+	}
+	result, err := b.callTimeout("Runtime.evaluate", params, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			Value interface{} `json:"value"`
+			Type  string      `json:"type"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text         string `json:"text"`
+			LineNumber   int    `json:"lineNumber"`
+			ColumnNumber int    `json:"columnNumber"`
+			Exception    *struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+			StackTrace *struct {
+				CallFrames []struct {
+					FunctionName string `json:"functionName"`
+					URL          string `json:"url"`
+					LineNumber   int    `json:"lineNumber"`
+					ColumnNumber int    `json:"columnNumber"`
+				} `json:"callFrames"`
+			} `json:"stackTrace"`
+		} `json:"exceptionDetails"`
+	}
+
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return nil, err
+	}
+
+	if d := resp.ExceptionDetails; d != nil {
+		evalErr := &EvalError{
+			Text:   d.Text,
+			Line:   d.LineNumber,
+			Column: d.ColumnNumber,
+		}
+		if d.Exception != nil {
+			evalErr.Description = d.Exception.Description
+		}
+		if d.StackTrace != nil {
+			for _, f := range d.StackTrace.CallFrames {
+				name := f.FunctionName
+				if name == "" {
+					name = "<anonymous>"
+				}
+				evalErr.Stack = append(evalErr.Stack,
+					fmt.Sprintf("%s (%s:%d:%d)", name, f.URL, f.LineNumber, f.ColumnNumber))
+			}
+		}
+		return nil, evalErr
+	}
+
+	return resp.Result.Value, nil
+}
+
+// GetHTML retourne le HTML de la page
+func (b *Browser) GetHTML() (string, error) {
+	result, err := b.Call("DOM.getDocument", map[string]interface{}{
+		"depth": -1,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var doc struct {
+		Root struct {
+			NodeID int `json:"nodeId"`
+		} `json:"root"`
+	}
+	if err := json.Unmarshal(result, &doc); err != nil {
+		return "", err
+	}
+
+	return b.outerHTML(doc.Root.NodeID)
+}
+
+// GetElementHTML retourne le HTML du premier élément correspondant au sélecteur
+func (b *Browser) GetElementHTML(selector string) (string, error) {
+	if err := validateCSSSelector(selector); err != nil {
+		return "", fmt.Errorf("invalid selector: %w", err)
+	}
+
+	result, err := b.Call("DOM.getDocument", map[string]interface{}{
+		"depth": 0,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var doc struct {
+		Root struct {
+			NodeID int `json:"nodeId"`
+		} `json:"root"`
+	}
+	if err := json.Unmarshal(result, &doc); err != nil {
+		return "", err
+	}
+
+	result, err = b.Call("DOM.querySelector", map[string]interface{}{
+		"nodeId":   doc.Root.NodeID,
+		"selector": selector,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var node struct {
+		NodeID int `json:"nodeId"`
+	}
+	if err := json.Unmarshal(result, &node); err != nil {
+		return "", err
+	}
+	if node.NodeID == 0 {
+		return "", fmt.Errorf("element not found: %s", selector)
+	}
+
+	return b.outerHTML(node.NodeID)
+}
+
+// outerHTML retourne le HTML sérialisé d'un nœud DOM
+func (b *Browser) outerHTML(nodeID int) (string, error) {
+	result, err := b.Call("DOM.getOuterHTML", map[string]int{
+		"nodeId": nodeID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var html struct {
+		OuterHTML string `json:"outerHTML"`
+	}
+	if err := json.Unmarshal(result, &html); err != nil {
+		return "", err
+	}
+
+	return html.OuterHTML, nil
+}
+
+// escapeJSString échappe une chaîne pour insertion sécurisée dans du JavaScript
+// Protège contre les injections XSS en échappant tous les caractères dangereux
+func escapeJSString(s string) string {
+	var result strings.Builder
+	result.Grow(len(s) + 10) // Pré-allouer un peu plus
 
 	for _, r := range s {
 		switch r {
@@ -633,25 +1299,43 @@ func validateCSSSelector(selector string) error {
 	return nil
 }
 
-// Click clique sur un élément par sélecteur CSS
-func (b *Browser) Click(selector string) error {
+// requireElement vérifie qu'un sélecteur désigne un élément présent dans la page
+// Retourne le sélecteur échappé pour JS, ou une erreur explicite si rien ne correspond
+func (b *Browser) requireElement(selector string) (string, error) {
 	if err := validateCSSSelector(selector); err != nil {
-		return fmt.Errorf("invalid selector: %w", err)
+		return "", fmt.Errorf("invalid selector: %w", err)
 	}
-	// Trouver l'élément avec sélecteur échappé
 	escaped := escapeJSString(selector)
-	_, err := b.Evaluate(fmt.Sprintf(`document.querySelector('%s').click()`, escaped))
+
+	found, err := b.Evaluate(fmt.Sprintf(`document.querySelector('%s') !== null`, escaped))
+	if err != nil {
+		return "", err
+	}
+	if ok, _ := found.(bool); !ok {
+		return "", fmt.Errorf("element not found: %s", selector)
+	}
+	return escaped, nil
+}
+
+// Click clique sur un élément par sélecteur CSS
+func (b *Browser) Click(selector string) error {
+	// Trouver l'élément avec sélecteur échappé
+	escaped, err := b.requireElement(selector)
+	if err != nil {
+		return err
+	}
+	_, err = b.Evaluate(fmt.Sprintf(`document.querySelector('%s').click()`, escaped))
 	return err
 }
 
 // Type tape du texte dans un élément
 func (b *Browser) Type(selector, text string) error {
-	if err := validateCSSSelector(selector); err != nil {
-		return fmt.Errorf("invalid selector: %w", err)
-	}
 	// Focus sur l'élément avec sélecteur échappé
-	escaped := escapeJSString(selector)
-	_, err := b.Evaluate(fmt.Sprintf(`document.querySelector('%s').focus()`, escaped))
+	escaped, err := b.requireElement(selector)
+	if err != nil {
+		return err
+	}
+	_, err = b.Evaluate(fmt.Sprintf(`document.querySelector('%s').focus()`, escaped))
 	if err != nil {
 		return err
 	}
@@ -670,7 +1354,34 @@ func (b *Browser) Type(selector, text string) error {
 	return nil
 }
 
+// waitSelectorJS Promise résolue dès que le sélecteur correspond (MutationObserver sur tout le document),
+// rejetée avec waitSelectorTimeout après le délai en millisecondes
+const waitSelectorJS = `new Promise((resolve, reject) => {
+	const selector = '%s';
+	if (document.querySelector(selector)) return resolve(true);
+	const observer = new MutationObserver(() => {
+		if (document.querySelector(selector)) {
+			observer.disconnect();
+			clearTimeout(timer);
+			resolve(true);
+		}
+	});
+	observer.observe(document, {childList: true, subtree: true, attributes: true});
+	const timer = setTimeout(() => {
+		observer.disconnect();
+		reject(new Error('%s'));
+	}, %d);
+})`
+
+// waitSelectorTimeout message du rejet de waitSelectorJS à l'expiration du délai
+const waitSelectorTimeout = "holow: wait_selector timeout"
+
+// waitSelectorMargin délai CDP laissé en plus du timeout de la Promise
+const waitSelectorMargin = 5 * time.Second
+
 // WaitForSelector attend qu'un élément soit présent
+// Un MutationObserver injecté dans la page répond dès la mutation qui fait apparaître l'élément;
+// si l'injection échoue (navigation en cours, contexte détruit), l'attente se poursuit par polling
 func (b *Browser) WaitForSelector(selector string, timeout time.Duration) error {
 	if err := validateCSSSelector(selector); err != nil {
 		return fmt.Errorf("invalid selector: %w", err)
@@ -678,6 +1389,23 @@ func (b *Browser) WaitForSelector(selector string, timeout time.Duration) error
 	escaped := escapeJSString(selector)
 	deadline := time.Now().Add(timeout)
 
+	js := fmt.Sprintf(waitSelectorJS, escaped, waitSelectorTimeout, timeout.Milliseconds())
+	result, err := b.evaluateTimeout(js, true, timeout+waitSelectorMargin)
+	var evalErr *EvalError
+	switch {
+	case err == nil:
+		if found, ok := result.(bool); ok && found {
+			return nil
+		}
+	case errors.As(err, &evalErr) && strings.Contains(evalErr.Error(), waitSelectorTimeout):
+		return fmt.Errorf("timeout waiting for selector: %s", selector)
+	}
+
+	return b.pollSelector(selector, escaped, deadline)
+}
+
+// pollSelector vérifie la présence du sélecteur toutes les 100ms jusqu'à deadline
+func (b *Browser) pollSelector(selector, escaped string, deadline time.Time) error {
 	for time.Now().Before(deadline) {
 		result, err := b.Evaluate(fmt.Sprintf(`document.querySelector('%s') !== null`, escaped))
 		if err != nil {
@@ -694,6 +1422,97 @@ func (b *Browser) WaitForSelector(selector string, timeout time.Duration) error
 	return fmt.Errorf("timeout waiting for selector: %s", selector)
 }
 
+// waitFunctionJS évalue la condition de WaitForFunction et retourne sa valeur avec sa véracité JS
+const waitFunctionJS = `(() => { const v = (%s); return {ok: !!v, value: v}; })()`
+
+// WaitForFunction attend qu'une expression JavaScript soit vraie (au sens JS), évaluée toutes les 100ms
+// Une exception (ex: objet pas encore défini) compte comme fausse, sauf erreur de syntaxe.
+// En cas de timeout, l'erreur contient la dernière valeur (ou exception) observée
+func (b *Browser) WaitForFunction(expression string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	last := "not evaluated"
+
+	for {
+		result, err := b.Evaluate(fmt.Sprintf(waitFunctionJS, expression))
+		var evalErr *EvalError
+		switch {
+		case errors.As(err, &evalErr):
+			if strings.Contains(evalErr.Error(), "SyntaxError") {
+				return fmt.Errorf("invalid expression: %w", err)
+			}
+			last = evalErr.Error()
+		case err != nil:
+			return err
+		default:
+			r, _ := result.(map[string]interface{})
+			if ok, _ := r["ok"].(bool); ok {
+				return nil
+			}
+			value, _ := json.Marshal(r["value"])
+			last = string(value)
+		}
+
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("timeout waiting for function: %s (last value: %s)", expression, last)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// WaitNetworkIdle attend qu'aucune requête réseau (Network.requestWillBeSent) ne soit émise
+// pendant quiet, au plus timeout; retourne le nombre de requêtes observées pendant l'attente
+func (b *Browser) WaitNetworkIdle(quiet, timeout time.Duration) (int, error) {
+	b.netWatch.Do(func() {
+		b.OnEvent("Network.requestWillBeSent", func(evt Event) {
+			atomic.AddInt64(&b.netRequests, 1)
+			atomic.StoreInt64(&b.netLastAt, time.Now().UnixNano())
+		})
+	})
+	if _, err := b.Call("Network.enable", nil); err != nil {
+		return 0, fmt.Errorf("failed to enable network events: %w", err)
+	}
+
+	b.mu.Lock()
+	opCtx := b.opCtx
+	b.mu.Unlock()
+	var opDone <-chan struct{}
+	if opCtx != nil {
+		opDone = opCtx.Done()
+	}
+
+	start := time.Now()
+	startCount := atomic.LoadInt64(&b.netRequests)
+	deadline := start.Add(timeout)
+	for {
+		last := start
+		if at := atomic.LoadInt64(&b.netLastAt); at > start.UnixNano() {
+			last = time.Unix(0, at)
+		}
+		seen := int(atomic.LoadInt64(&b.netRequests) - startCount)
+
+		now := time.Now()
+		idleAt := last.Add(quiet)
+		if !now.Before(idleAt) {
+			return seen, nil
+		}
+		if !now.Before(deadline) {
+			return seen, fmt.Errorf("timeout waiting for network idle (%d requests observed)", seen)
+		}
+
+		wait := idleAt.Sub(now)
+		if remaining := deadline.Sub(now); remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-time.After(wait):
+		case <-b.readDone:
+			return seen, fmt.Errorf("browser connection closed")
+		case <-opDone:
+			return seen, opCtx.Err()
+		}
+	}
+}
+
 // GetCookies retourne les cookies
 func (b *Browser) GetCookies() ([]map[string]interface{}, error) {
 	result, err := b.Call("Network.getCookies", nil)
@@ -711,14 +1530,21 @@ func (b *Browser) GetCookies() ([]map[string]interface{}, error) {
 	return resp.Cookies, nil
 }
 
+// Cookie paramètres de Network.setCookie (les champs vides sont omis)
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"` // Strict, Lax ou None
+	Expires  float64 `json:"expires,omitempty"`  // Timestamp Unix en secondes (0 = cookie de session)
+}
+
 // SetCookie définit un cookie
-func (b *Browser) SetCookie(name, value, domain, path string) error {
-	_, err := b.Call("Network.setCookie", map[string]string{
-		"name":   name,
-		"value":  value,
-		"domain": domain,
-		"path":   path,
-	})
+func (b *Browser) SetCookie(cookie Cookie) error {
+	_, err := b.Call("Network.setCookie", cookie)
 	return err
 }
 
@@ -820,6 +1646,27 @@ func waitForDebugger(port int, timeout time.Duration) (string, error) {
 	return "", fmt.Errorf("timeout waiting for debugger on port %d", port)
 }
 
+// debugProbeTimeout délai de réponse accordé à un port sondé par la découverte
+const debugProbeTimeout = 500 * time.Millisecond
+
+// probeDebugPort indique si un débogueur Chrome répond sur le port
+func probeDebugPort(port int) bool {
+	client := &http.Client{Timeout: debugProbeTimeout}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/json/version", port))
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return false
+	}
+	return info.WebSocketDebuggerURL != ""
+}
+
 // getDebuggerURL récupère l'URL WebSocket du débogueur
 func getDebuggerURL(port int) (string, error) {
 	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/json/version", port))
@@ -858,11 +1705,37 @@ func (b *Browser) SaveScreenshot(path string) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-// PDF génère un PDF de la page
-func (b *Browser) PDF() ([]byte, error) {
-	result, err := b.Call("Page.printToPDF", map[string]interface{}{
-		"printBackground": true,
-	})
+// PDFOptions options de Page.printToPDF (dimensions en pouces)
+// Les valeurs nulles gardent les défauts de Chrome (Letter, portrait, marges ~1 cm, échelle 1);
+// une marge à 0 s'exprime avec un pointeur non nil
+type PDFOptions struct {
+	Landscape           bool     `json:"landscape,omitempty"`
+	PaperWidth          float64  `json:"paperWidth,omitempty"`
+	PaperHeight         float64  `json:"paperHeight,omitempty"`
+	MarginTop           *float64 `json:"marginTop,omitempty"`
+	MarginBottom        *float64 `json:"marginBottom,omitempty"`
+	MarginLeft          *float64 `json:"marginLeft,omitempty"`
+	MarginRight         *float64 `json:"marginRight,omitempty"`
+	Scale               float64  `json:"scale,omitempty"`      // 0.1 à 2
+	PageRanges          string   `json:"pageRanges,omitempty"` // ex: "1-5, 8"
+	DisplayHeaderFooter bool     `json:"displayHeaderFooter,omitempty"`
+	HeaderTemplate      string   `json:"headerTemplate,omitempty"` // HTML, classes date, title, url, pageNumber, totalPages
+	FooterTemplate      string   `json:"footerTemplate,omitempty"`
+}
+
+// PDF génère un PDF de la page (arrière-plans imprimés)
+func (b *Browser) PDF(opts PDFOptions) ([]byte, error) {
+	params := map[string]interface{}{}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	params["printBackground"] = true
+
+	result, err := b.Call("Page.printToPDF", params)
 	if err != nil {
 		return nil, err
 	}
@@ -876,3 +1749,246 @@ func (b *Browser) PDF() ([]byte, error) {
 
 	return base64.StdEncoding.DecodeString(resp.Data)
 }
+
+// SetDocumentContent remplace la page par le document html
+// La page repasse d'abord par about:blank pour ne pas hériter de l'origine du site courant
+func (b *Browser) SetDocumentContent(html string) error {
+	b.Call("Page.enable", nil)
+
+	if _, err := b.Call("Page.navigate", map[string]string{"url": "about:blank"}); err != nil {
+		return err
+	}
+
+	result, err := b.Call("Page.getFrameTree", nil)
+	if err != nil {
+		return err
+	}
+	var tree struct {
+		FrameTree struct {
+			Frame struct {
+				ID string `json:"id"`
+			} `json:"frame"`
+		} `json:"frameTree"`
+	}
+	if err := json.Unmarshal(result, &tree); err != nil {
+		return err
+	}
+	if tree.FrameTree.Frame.ID == "" {
+		return fmt.Errorf("no main frame")
+	}
+
+	_, err = b.Call("Page.setDocumentContent", map[string]interface{}{
+		"frameId": tree.FrameTree.Frame.ID,
+		"html":    html,
+	})
+	return err
+}
+
+// AddScriptOnNewDocument fait exécuter source dans chaque document chargé ensuite, avant ses propres scripts
+// Retourne l'identifiant à passer à RemoveScriptOnNewDocument
+func (b *Browser) AddScriptOnNewDocument(source string) (string, error) {
+	b.Call("Page.enable", nil)
+
+	result, err := b.Call("Page.addScriptToEvaluateOnNewDocument", map[string]string{"source": source})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return "", err
+	}
+	return resp.Identifier, nil
+}
+
+// RemoveScriptOnNewDocument retire un script ajouté par AddScriptOnNewDocument
+func (b *Browser) RemoveScriptOnNewDocument(id string) error {
+	_, err := b.Call("Page.removeScriptToEvaluateOnNewDocument", map[string]string{"identifier": id})
+	return err
+}
+
+// AXElement représente un élément interactif de l'arbre d'accessibilité
+type AXElement struct {
+	Role     string `json:"role"`
+	Name     string `json:"name,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// interactiveRoles liste les rôles ARIA considérés comme interactifs
+var interactiveRoles = map[string]bool{
+	"button": true, "link": true, "textbox": true, "searchbox": true,
+	"combobox": true, "checkbox": true, "radio": true, "switch": true,
+	"menuitem": true, "menuitemcheckbox": true, "menuitemradio": true,
+	"tab": true, "option": true, "listbox": true, "slider": true,
+	"spinbutton": true, "treeitem": true,
+}
+
+// maxAXNameLen limite la longueur des noms accessibles retournés
+const maxAXNameLen = 120
+
+// Describe retourne les éléments interactifs de la page via Accessibility.getFullAXTree
+// maxElements borne le nombre d'éléments retournés; truncated indique si la liste a été coupée
+func (b *Browser) Describe(maxElements int) ([]AXElement, bool, error) {
+	result, err := b.Call("Accessibility.getFullAXTree", nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	type axValue struct {
+		Value interface{} `json:"value"`
+	}
+	var tree struct {
+		Nodes []struct {
+			Ignored          bool     `json:"ignored"`
+			Role             *axValue `json:"role"`
+			Name             *axValue `json:"name"`
+			Value            *axValue `json:"value"`
+			BackendDOMNodeID int      `json:"backendDOMNodeId"`
+			Properties       []struct {
+				Name  string  `json:"name"`
+				Value axValue `json:"value"`
+			} `json:"properties"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(result, &tree); err != nil {
+		return nil, false, err
+	}
+
+	elements := make([]AXElement, 0)
+	truncated := false
+	for _, node := range tree.Nodes {
+		if node.Ignored || node.Role == nil {
+			continue
+		}
+		role, _ := node.Role.Value.(string)
+		if !interactiveRoles[role] {
+			continue
+		}
+		if len(elements) >= maxElements {
+			truncated = true
+			break
+		}
+
+		el := AXElement{Role: role}
+		if node.Name != nil {
+			el.Name = truncateAXText(fmt.Sprint(node.Name.Value))
+		}
+		if node.Value != nil && node.Value.Value != nil {
+			el.Value = truncateAXText(fmt.Sprint(node.Value.Value))
+		}
+		for _, prop := range node.Properties {
+			if prop.Name == "disabled" {
+				el.Disabled, _ = prop.Value.Value.(bool)
+			}
+		}
+		if node.BackendDOMNodeID != 0 {
+			el.Selector = b.selectorHint(node.BackendDOMNodeID)
+		}
+
+		elements = append(elements, el)
+	}
+
+	return elements, truncated, nil
+}
+
+// selectorHint construit un sélecteur CSS indicatif pour un nœud DOM
+// Priorité: #id, puis tag[name=...], puis tag[aria-label=...], sinon le tag seul
+func (b *Browser) selectorHint(backendNodeID int) string {
+	result, err := b.Call("DOM.describeNode", map[string]interface{}{
+		"backendNodeId": backendNodeID,
+	})
+	if err != nil {
+		return ""
+	}
+
+	var resp struct {
+		Node struct {
+			LocalName  string   `json:"localName"`
+			Attributes []string `json:"attributes"`
+		} `json:"node"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return ""
+	}
+
+	// Les attributs CDP sont une liste plate [nom, valeur, nom, valeur, ...]
+	attrs := make(map[string]string)
+	for i := 0; i+1 < len(resp.Node.Attributes); i += 2 {
+		attrs[resp.Node.Attributes[i]] = resp.Node.Attributes[i+1]
+	}
+
+	return selectorFromAttributes(resp.Node.LocalName, attrs)
+}
+
+// selectorFromAttributes applique la priorité de selectorHint aux attributs d'un nœud
+// Les valeurs sont échappées: un id ou un name contenant ", ], : ou un chiffre initial reste un sélecteur valide
+func selectorFromAttributes(tag string, attrs map[string]string) string {
+	switch {
+	case attrs["id"] != "":
+		return "#" + cssEscapeIdent(attrs["id"])
+	case attrs["name"] != "":
+		return fmt.Sprintf(`%s[name=%s]`, tag, cssQuote(attrs["name"]))
+	case attrs["aria-label"] != "":
+		return fmt.Sprintf(`%s[aria-label=%s]`, tag, cssQuote(truncateAXText(attrs["aria-label"])))
+	default:
+		return tag
+	}
+}
+
+// cssEscapeIdent échappe s comme CSS.escape() (CSSOM) pour l'utiliser comme identifiant
+func cssEscapeIdent(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		switch {
+		case r == 0:
+			sb.WriteRune('\uFFFD')
+		case (r >= 0x1 && r <= 0x1F) || r == 0x7F,
+			i == 0 && r >= '0' && r <= '9',
+			i == 1 && r >= '0' && r <= '9' && runes[0] == '-':
+			fmt.Fprintf(&sb, "\\%x ", r)
+		case i == 0 && r == '-' && len(runes) == 1:
+			sb.WriteString(`\-`)
+		case r >= 0x80 || r == '-' || r == '_' ||
+			(r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// cssQuote retourne s entre guillemets doubles, échappé pour une valeur d'attribut CSS
+func cssQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == 0:
+			sb.WriteRune('\uFFFD')
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case (r >= 0x1 && r <= 0x1F) || r == 0x7F:
+			fmt.Fprintf(&sb, "\\%x ", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// truncateAXText tronque un texte d'accessibilité à maxAXNameLen caractères
+func truncateAXText(s string) string {
+	s = strings.TrimSpace(s)
+	if len([]rune(s)) > maxAXNameLen {
+		return string([]rune(s)[:maxAXNameLen]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package chromium
+
+import "testing"
+
+func TestSelectorFromAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		tag   string
+		attrs map[string]string
+		want  string
+	}{
+		{"plain id", "div", map[string]string{"id": "main", "name": "ignored"}, "#main"},
+		{"id with leading digit", "div", map[string]string{"id": "1st"}, `#\31 st`},
+		{"id with dash then digit", "div", map[string]string{"id": "-2x"}, `#-\32 x`},
+		{"id single dash", "div", map[string]string{"id": "-"}, `#\-`},
+		{"id with punctuation", "div", map[string]string{"id": "form:email.value"}, `#form\:email\.value`},
+		{"id with space", "div", map[string]string{"id": "a b"}, `#a\ b`},
+		{"id non ascii", "div", map[string]string{"id": "déjà_vu-1"}, "#déjà_vu-1"},
+		{"id with control char", "div", map[string]string{"id": "a\tb"}, `#a\9 b`},
+		{"name", "input", map[string]string{"name": "email"}, `input[name="email"]`},
+		{"name with quote and bracket", "input", map[string]string{"name": `q"]`}, `input[name="q\"]"]`},
+		{"name with backslash", "input", map[string]string{"name": `a\b`}, `input[name="a\\b"]`},
+		{"aria-label with newline", "button", map[string]string{"aria-label": "Save\nnow"}, `button[aria-label="Save\a now"]`},
+		{"tag only", "span", map[string]string{}, "span"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectorFromAttributes(tt.tag, tt.attrs); got != tt.want {
+				t.Errorf("selectorFromAttributes(%q, %v) = %s, want %s", tt.tag, tt.attrs, got, tt.want)
+			}
+		})
+	}
+}
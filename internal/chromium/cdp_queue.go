@@ -0,0 +1,52 @@
+// Package chromium - Contre-pression sur la file cdp_commands
+package chromium
+
+import (
+	"fmt"
+)
+
+// DefaultMaxPendingCommands nombre max par défaut de cdp_commands en attente
+const DefaultMaxPendingCommands = 1000
+
+// Taille d'un lot de ProcessPendingCommands: un quart de l'arriéré, bornée
+const (
+	minCDPBatch = 10
+	maxCDPBatch = 100
+)
+
+// cdpQueueTrigger trigger refusant les insertions au-delà de la limite d'arriéré
+const cdpQueueTrigger = "cdp_commands_backpressure"
+
+// SetQueueLimit borne le nombre de commandes en attente (0 = illimité)
+// Au-delà, l'INSERT dans cdp_commands échoue avec une erreur explicite (trigger recréé à chaque appel)
+func (m *CDPManager) SetQueueLimit(maxPending int) error {
+	if _, err := m.db.Exec("DROP TRIGGER IF EXISTS " + cdpQueueTrigger); err != nil {
+		return err
+	}
+	if maxPending <= 0 {
+		return nil
+	}
+
+	// RAISE n'accepte qu'un littéral: la limite est inscrite dans le message
+	_, err := m.db.Exec(fmt.Sprintf(`
+		CREATE TRIGGER %s
+		BEFORE INSERT ON cdp_commands
+		WHEN COALESCE(NEW.status, 'pending') = 'pending'
+			AND (SELECT COUNT(*) FROM cdp_commands WHERE status = 'pending') >= %d
+		BEGIN
+			SELECT RAISE(ABORT, 'cdp_commands backlog full (%d pending commands): retry later or raise cdp.max_pending_commands');
+		END`, cdpQueueTrigger, maxPending, maxPending))
+	return err
+}
+
+// batchSize retourne le nombre de commandes à traiter dans ce tick selon l'arriéré
+func batchSize(pending int) int {
+	n := pending / 4
+	if n < minCDPBatch {
+		return minCDPBatch
+	}
+	if n > maxCDPBatch {
+		return maxCDPBatch
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+// Package chromium - Bornage des résultats CDP volumineux (cdp_call, file cdp_commands)
+package chromium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxResultBytes taille max par défaut d'un résultat CDP retourné à SQL (1 Mo)
+const DefaultMaxResultBytes = 1 << 20
+
+// truncatedEnvelopeOverhead place réservée aux champs de l'enveloppe hors aperçu
+const truncatedEnvelopeOverhead = 512
+
+// TruncatedResult remplace un résultat CDP dépassant la limite: JSON valide,
+// lisible depuis SQL (json_extract(result, '$.truncated'))
+type TruncatedResult struct {
+	Truncated bool   `json:"truncated"`
+	Method    string `json:"method"`
+	Size      int    `json:"size"`           // Taille du résultat complet en octets
+	Path      string `json:"path,omitempty"` // Fichier contenant le résultat complet
+	Preview   string `json:"preview"`        // Début du JSON brut
+}
+
+// SetResultLimit borne la taille des résultats de Call (0 = illimité)
+// Au-delà, le résultat complet est écrit dans spillDir si non vide
+func (m *CDPManager) SetResultLimit(maxBytes int, spillDir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxResultBytes = maxBytes
+	m.spillDir = spillDir
+}
+
+// limitResult retourne result, ou une enveloppe TruncatedResult si sa taille dépasse la limite
+func (m *CDPManager) limitResult(method, result string) string {
+	m.mu.RLock()
+	maxBytes, spillDir := m.maxResultBytes, m.spillDir
+	m.mu.RUnlock()
+
+	if maxBytes <= 0 || len(result) <= maxBytes {
+		return result
+	}
+
+	envelope := TruncatedResult{Truncated: true, Method: method, Size: len(result)}
+	if spillDir != "" {
+		path, err := spillResult(spillDir, method, result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[warn] cdp result spill failed: %v\n", err)
+		}
+		envelope.Path = path
+	}
+	// L'échappement JSON peut doubler l'aperçu: l'enveloppe reste sous maxBytes
+	envelope.Preview = truncateUTF8(result, (maxBytes-truncatedEnvelopeOverhead)/2)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(envelope); err != nil {
+		return `{"truncated":true}`
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// spillResult écrit le résultat complet dans un fichier de dir et retourne son chemin
+// dir doit être un dossier (pas un lien) réservé au propriétaire: il est créé en 0700 au besoin
+func spillResult(dir, method, result string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() || info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("spill directory %s must be a directory accessible only by its owner", dir)
+	}
+	f, err := os.CreateTemp(dir, "cdp-"+strings.ReplaceAll(method, ".", "-")+"-*.json")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(result); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// CleanupSpilled supprime les résultats complets écrits depuis plus de maxAge
+func (m *CDPManager) CleanupSpilled(maxAge time.Duration) (int, error) {
+	m.mu.RLock()
+	dir := m.spillDir
+	m.mu.RUnlock()
+	if dir == "" {
+		return 0, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "cdp-*.json"))
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil && info.ModTime().Before(cutoff) {
+			if os.Remove(f) == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// truncateUTF8 coupe s à au plus n octets sans couper de caractère
+func truncateUTF8(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
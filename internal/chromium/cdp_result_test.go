@@ -0,0 +1,59 @@
+package chromium
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLimitResultSpillsToPrivateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cdp-results")
+	m := NewCDPManager(nil)
+	m.SetResultLimit(truncatedEnvelopeOverhead+64, dir)
+
+	result := `{"data":"` + strings.Repeat("x", 4096) + `"}`
+	var envelope TruncatedResult
+	if err := json.Unmarshal([]byte(m.limitResult("Page.captureScreenshot", result)), &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if !envelope.Truncated || envelope.Size != len(result) {
+		t.Fatalf("envelope = %+v", envelope)
+	}
+	if filepath.Dir(envelope.Path) != dir {
+		t.Fatalf("spilled to %q, want a file in %q", envelope.Path, dir)
+	}
+	if data, err := os.ReadFile(envelope.Path); err != nil || string(data) != result {
+		t.Fatalf("spilled content mismatch (err %v)", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("spill dir mode = %o, want 700", perm)
+	}
+}
+
+func TestSpillResultRejectsSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spillResult(dir, "Page.captureScreenshot", "{}"); err == nil {
+		t.Error("expected error for a world-writable spill dir")
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	private := filepath.Join(t.TempDir(), "private")
+	if err := os.Mkdir(private, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(private, link); err != nil {
+		t.Skip("symlinks unavailable:", err)
+	}
+	if _, err := spillResult(link, "Page.captureScreenshot", "{}"); err == nil {
+		t.Error("expected error for a symlinked spill dir")
+	}
+}
@@ -2,10 +2,15 @@
 package chromium
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // CDPManager gère la connexion CDP persistante et expose cdp_call() à SQLite
@@ -14,12 +19,34 @@ type CDPManager struct {
 	sessionID string // Session CDP active pour la page courante
 	mu        sync.RWMutex
 	db        *sql.DB
+
+	processMu sync.Mutex // Sérialise le traitement de la file cdp_commands
+
+	// Contexte du tools/call dont un step SQL appelle cdp_call() (BindContext)
+	bindSem  chan struct{}   // Un seul contexte lié à la fois
+	boundCtx context.Context // Protégé par mu, nil hors step
+
+	observer CallObserver // Journal des commandes CDP (SetCallObserver, protégé par mu)
+
+	disabled int32 // 1 si cdp_call() et la file cdp_commands sont refusés (SetDisabled)
+
+	// Résultats volumineux (cdp_result.go)
+	maxResultBytes int    // Taille max d'un résultat retourné par Call (0 = illimité)
+	spillDir       string // Dossier des résultats complets dépassant la limite ("" = pas de copie)
 }
 
+// CallObserver reçoit chaque commande CDP exécutée par CallContext, avec le contexte de l'appel
+// (celui du tools/call via BindContext: il porte le trace ID de la requête)
+type CallObserver func(ctx context.Context, method string, elapsed time.Duration, err error)
+
+// ErrCDPDisabled erreur des appels CDP refusés (mode restreint du serveur)
+var ErrCDPDisabled = errors.New("CDP access disabled (safe mode)")
+
 // NewCDPManager crée un gestionnaire CDP avec connexion persistante
 func NewCDPManager(db *sql.DB) *CDPManager {
 	return &CDPManager{
-		db: db,
+		db:      db,
+		bindSem: make(chan struct{}, 1),
 	}
 }
 
@@ -30,9 +57,38 @@ func (m *CDPManager) SetDB(db *sql.DB) {
 	m.db = db
 }
 
+// SetDisabled refuse (ou réautorise) tout accès CDP: fonctions SQL cdp_*, file cdp_commands
+// La désactivation ferme la connexion en cours
+func (m *CDPManager) SetDisabled(disabled bool) {
+	var v int32
+	if disabled {
+		v = 1
+	}
+	atomic.StoreInt32(&m.disabled, v)
+	if disabled {
+		m.Disconnect()
+	}
+}
+
+// SetCallObserver configure le journal des commandes CDP (nil pour le retirer)
+func (m *CDPManager) SetCallObserver(fn CallObserver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observer = fn
+}
+
+// Disabled indique si l'accès CDP est refusé
+func (m *CDPManager) Disabled() bool {
+	return atomic.LoadInt32(&m.disabled) == 1
+}
+
 // EnsureConnected vérifie et établit la connexion au browser si nécessaire
 // Établit également une session vers une page (target) pour les commandes CDP
 func (m *CDPManager) EnsureConnected() error {
+	if m.Disabled() {
+		return ErrCDPDisabled
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -98,9 +154,142 @@ func isBrowserLevelMethod(method string) bool {
 	return false
 }
 
-// Call exécute une commande CDP et retourne le résultat JSON
-// Utilise automatiquement la session pour les commandes de page (Page, DOM, Runtime, etc.)
+// maxCDPRetries nombre de nouvelles tentatives après une erreur CDP transitoire
+const maxCDPRetries = 2
+
+// retryableSessionErrors erreurs CDP transitoires résolues en rétablissant la session
+// (typiquement juste après une navigation ou la fermeture d'un target)
+var retryableSessionErrors = []string{
+	"Session with given id not found",
+	"No session with given id",
+	"No target with given id found",
+	"Cannot find context with specified id",
+	"Execution context was destroyed",
+	"Inspected target navigated or closed",
+}
+
+// retryableConnectionErrors erreurs indiquant une connexion websocket perdue
+var retryableConnectionErrors = []string{
+	"websocket: close",
+	"use of closed network connection",
+	"broken pipe",
+}
+
+// matchesAny vérifie si le message d'erreur contient l'un des motifs
+func matchesAny(err error, patterns []string) bool {
+	msg := err.Error()
+	for _, p := range patterns {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// BindContext lie ctx aux appels cdp_call() suivants jusqu'à release
+// Les fonctions SQL ne reçoivent pas de contexte Go: le step SQL qui les appelle le pose ici
+// Les steps liés sont sérialisés (une seule page active); l'attente est bornée par ctx
+func (m *CDPManager) BindContext(ctx context.Context) (release func(), err error) {
+	select {
+	case m.bindSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mu.Lock()
+	m.boundCtx = ctx
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		m.boundCtx = nil
+		m.mu.Unlock()
+		<-m.bindSem
+	}, nil
+}
+
+// boundContext retourne le contexte posé par BindContext (Background hors step lié)
+func (m *CDPManager) boundContext() context.Context {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.boundCtx == nil {
+		return context.Background()
+	}
+	return m.boundCtx
+}
+
+// Call exécute une commande CDP sans borne de contexte (cf. CallContext)
 func (m *CDPManager) Call(method string, params map[string]interface{}) (string, error) {
+	return m.CallContext(context.Background(), method, params)
+}
+
+// CallContext exécute une commande CDP et retourne le résultat JSON (borné par SetResultLimit)
+// Les erreurs transitoires (session perdue, connexion fermée) déclenchent un
+// rétablissement via EnsureConnected puis une nouvelle tentative avec backoff
+// L'attente de la réponse et le backoff sont abandonnés dès que ctx expire
+func (m *CDPManager) CallContext(ctx context.Context, method string, params map[string]interface{}) (result string, err error) {
+	m.mu.RLock()
+	observer := m.observer
+	m.mu.RUnlock()
+	if observer != nil {
+		start := time.Now()
+		defer func() { observer(ctx, method, time.Since(start), err) }()
+	}
+
+	result, err = m.call(ctx, method, params)
+	for attempt := 1; err != nil && ctx.Err() == nil && attempt <= maxCDPRetries; attempt++ {
+		switch {
+		case matchesAny(err, retryableSessionErrors):
+			m.resetSession(false)
+		case matchesAny(err, retryableConnectionErrors):
+			m.resetSession(true)
+		default:
+			return "", err
+		}
+
+		select {
+		case <-time.After(time.Duration(attempt) * 200 * time.Millisecond):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		if connErr := m.EnsureConnected(); connErr != nil {
+			return "", fmt.Errorf("%w (reconnect failed: %v)", err, connErr)
+		}
+		result, err = m.call(ctx, method, params)
+	}
+	if err != nil {
+		return result, err
+	}
+	return m.limitResult(method, result), nil
+}
+
+// resetSession invalide la session courante; si dropBrowser, la connexion est aussi abandonnée
+func (m *CDPManager) resetSession(dropBrowser bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessionID = ""
+	if m.browser == nil {
+		return
+	}
+	if dropBrowser {
+		m.browser.Close()
+		m.browser = nil
+		return
+	}
+	m.browser.mu.Lock()
+	m.browser.currentSessionID = ""
+	m.browser.mu.Unlock()
+}
+
+// call exécute une commande CDP sans nouvelle tentative
+// Utilise automatiquement la session pour les commandes de page (Page, DOM, Runtime, etc.)
+func (m *CDPManager) call(ctx context.Context, method string, params map[string]interface{}) (string, error) {
+	if m.Disabled() {
+		return "", ErrCDPDisabled
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -113,13 +302,13 @@ func (m *CDPManager) Call(method string, params map[string]interface{}) (string,
 
 	// Les commandes browser-level n'ont pas besoin de session
 	if isBrowserLevelMethod(method) {
-		result, err = m.browser.Call(method, params)
+		result, err = m.browser.CallContext(ctx, "", method, params)
 	} else {
 		// Les commandes page-level utilisent la session
 		if m.sessionID == "" {
 			return "", fmt.Errorf("no page session - call EnsureConnected first")
 		}
-		result, err = m.browser.CallWithSession(m.sessionID, method, params)
+		result, err = m.browser.CallContext(ctx, m.sessionID, method, params)
 	}
 
 	if err != nil {
@@ -244,15 +433,67 @@ func (m *CDPManager) RegisterSQLFunctions() error {
 	return nil
 }
 
+// PendingCount retourne le nombre de commandes CDP en attente
+func (m *CDPManager) PendingCount() (int, error) {
+	var count int
+	err := m.db.QueryRow(`SELECT COUNT(*) FROM cdp_commands WHERE status = 'pending'`).Scan(&count)
+	return count, err
+}
+
+// AbortPending marque les commandes encore en attente comme abandonnées
+func (m *CDPManager) AbortPending(reason string) (int64, error) {
+	m.processMu.Lock()
+	defer m.processMu.Unlock()
+
+	res, err := m.db.Exec(`
+		UPDATE cdp_commands
+		SET status = 'aborted',
+			error = ?,
+			processed_at = strftime('%s', 'now')
+		WHERE status = 'pending'
+	`, reason)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CleanupProcessed supprime les commandes CDP traitées depuis plus de maxAge
+func (m *CDPManager) CleanupProcessed(maxAge time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-maxAge).Unix()
+	res, err := m.db.Exec(`
+		DELETE FROM cdp_commands
+		WHERE status != 'pending'
+		AND processed_at IS NOT NULL
+		AND processed_at < ?
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // ProcessPendingCommands traite les commandes CDP en attente (à appeler en boucle)
+// Le lot grandit avec l'arriéré (batchSize) pour le résorber sans attendre les ticks suivants
 func (m *CDPManager) ProcessPendingCommands() error {
+	m.processMu.Lock()
+	defer m.processMu.Unlock()
+
+	pending, err := m.PendingCount()
+	if err != nil {
+		return err
+	}
+	if pending == 0 {
+		return nil
+	}
+
 	rows, err := m.db.Query(`
 		SELECT id, method, params
 		FROM cdp_commands
 		WHERE status = 'pending'
 		ORDER BY id ASC
-		LIMIT 10
-	`)
+		LIMIT ?
+	`, batchSize(pending))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,243 @@
+// Package chromium - Capture des messages console de la page (action get_console)
+package chromium
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxConsoleLogs messages conservés au plus; les plus anciens sont évincés
+const maxConsoleLogs = 500
+
+// consoleReplayDelay laisse aux handlers (goroutines) le temps de stocker les messages rejoués
+const consoleReplayDelay = 100 * time.Millisecond
+
+// ConsoleLog message console, exception non interceptée ou entrée du journal du navigateur
+type ConsoleLog struct {
+	Level     string    `json:"level"`  // error, warning ou log
+	Type      string    `json:"type"`   // Type CDP d'origine (log, info, warn, assert, exception, ...)
+	Source    string    `json:"source"` // console-api, exception ou source de Log.entryAdded (network, javascript, ...)
+	Text      string    `json:"text"`
+	URL       string    `json:"url,omitempty"`
+	Line      int       `json:"line,omitempty"`   // 1-based, 0 si inconnue
+	Column    int       `json:"column,omitempty"` // 1-based, 0 si inconnue
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EnableMonitoring active la capture console (domaines Runtime et Log), sans effet si elle l'est déjà;
+// à l'activation, Chrome rejoue les messages déjà émis par la page
+func (b *Browser) EnableMonitoring() error {
+	b.consoleWatch.Do(func() {
+		b.OnEvent("Runtime.consoleAPICalled", b.handleConsoleEvent)
+		b.OnEvent("Runtime.exceptionThrown", b.handleExceptionEvent)
+		b.OnEvent("Log.entryAdded", b.handleLogEntryEvent)
+	})
+
+	b.mu.Lock()
+	enabled := b.consoleEnabled
+	b.mu.Unlock()
+	if enabled {
+		return nil
+	}
+
+	if _, err := b.Call("Runtime.enable", nil); err != nil {
+		return fmt.Errorf("failed to enable console events: %w", err)
+	}
+	if _, err := b.Call("Log.enable", nil); err != nil {
+		return fmt.Errorf("failed to enable log events: %w", err)
+	}
+	b.mu.Lock()
+	b.consoleEnabled = true
+	b.mu.Unlock()
+	time.Sleep(consoleReplayDelay)
+	return nil
+}
+
+// GetConsoleLogs retourne les messages capturés, du plus ancien au plus récent,
+// filtrés par level ("" = tous); clear vide ensuite le tampon
+func (b *Browser) GetConsoleLogs(level string, clear bool) []ConsoleLog {
+	b.consoleMu.Lock()
+	defer b.consoleMu.Unlock()
+
+	logs := []ConsoleLog{}
+	for _, l := range b.consoleLogs {
+		if level == "" || l.Level == level {
+			logs = append(logs, l)
+		}
+	}
+	// Les handlers tournent dans des goroutines: l'ordre d'arrivée n'est pas garanti
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Timestamp.Before(logs[j].Timestamp) })
+	if clear {
+		b.consoleLogs = nil
+	}
+	return logs
+}
+
+// addConsoleLog ajoute un message au tampon borné par maxConsoleLogs
+func (b *Browser) addConsoleLog(l ConsoleLog) {
+	b.consoleMu.Lock()
+	defer b.consoleMu.Unlock()
+	b.consoleLogs = append(b.consoleLogs, l)
+	if n := len(b.consoleLogs) - maxConsoleLogs; n > 0 {
+		b.consoleLogs = append([]ConsoleLog(nil), b.consoleLogs[n:]...)
+	}
+}
+
+// cdpCallFrame position d'un appel dans une pile CDP (lignes et colonnes 0-based)
+type cdpCallFrame struct {
+	URL          string `json:"url"`
+	LineNumber   int    `json:"lineNumber"`
+	ColumnNumber int    `json:"columnNumber"`
+}
+
+// cdpStackTrace pile d'appels CDP (Runtime.StackTrace)
+type cdpStackTrace struct {
+	CallFrames []cdpCallFrame `json:"callFrames"`
+}
+
+// cdpRemoteObject valeur JS transmise par CDP (Runtime.RemoteObject)
+type cdpRemoteObject struct {
+	Type                string          `json:"type"`
+	Value               json.RawMessage `json:"value"`
+	UnserializableValue string          `json:"unserializableValue"`
+	Description         string          `json:"description"`
+}
+
+// String formate l'objet comme la console: chaînes brutes, primitives en JSON, objets décrits
+func (o cdpRemoteObject) String() string {
+	if o.Type == "string" {
+		var s string
+		if json.Unmarshal(o.Value, &s) == nil {
+			return s
+		}
+	}
+	if o.UnserializableValue != "" {
+		return o.UnserializableValue
+	}
+	if o.Description != "" {
+		return o.Description
+	}
+	if len(o.Value) > 0 {
+		return string(o.Value)
+	}
+	return o.Type
+}
+
+// cdpTime convertit un timestamp CDP (millisecondes depuis l'epoch) en time.Time
+func cdpTime(ms float64) time.Time {
+	if ms <= 0 {
+		return time.Now()
+	}
+	return time.UnixMicro(int64(ms * 1000))
+}
+
+// consoleLevel ramène un type ou niveau CDP à error, warning ou log
+func consoleLevel(cdpType string) string {
+	switch cdpType {
+	case "error", "assert", "exception":
+		return "error"
+	case "warning", "warn":
+		return "warning"
+	default:
+		return "log"
+	}
+}
+
+// handleConsoleEvent capture un appel console.* (Runtime.consoleAPICalled)
+func (b *Browser) handleConsoleEvent(evt Event) {
+	var p struct {
+		Type       string            `json:"type"`
+		Args       []cdpRemoteObject `json:"args"`
+		Timestamp  float64           `json:"timestamp"`
+		StackTrace *cdpStackTrace    `json:"stackTrace"`
+	}
+	if json.Unmarshal(evt.Params, &p) != nil {
+		return
+	}
+
+	parts := make([]string, 0, len(p.Args))
+	for _, arg := range p.Args {
+		parts = append(parts, arg.String())
+	}
+	l := ConsoleLog{
+		Level:     consoleLevel(p.Type),
+		Type:      p.Type,
+		Source:    "console-api",
+		Text:      strings.Join(parts, " "),
+		Timestamp: cdpTime(p.Timestamp),
+	}
+	if p.StackTrace != nil && len(p.StackTrace.CallFrames) > 0 {
+		f := p.StackTrace.CallFrames[0]
+		l.URL, l.Line, l.Column = f.URL, f.LineNumber+1, f.ColumnNumber+1
+	}
+	b.addConsoleLog(l)
+}
+
+// handleExceptionEvent capture une exception non interceptée (Runtime.exceptionThrown)
+func (b *Browser) handleExceptionEvent(evt Event) {
+	var p struct {
+		Timestamp        float64 `json:"timestamp"`
+		ExceptionDetails struct {
+			Text         string           `json:"text"`
+			URL          string           `json:"url"`
+			LineNumber   int              `json:"lineNumber"`
+			ColumnNumber int              `json:"columnNumber"`
+			Exception    *cdpRemoteObject `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	if json.Unmarshal(evt.Params, &p) != nil {
+		return
+	}
+
+	d := p.ExceptionDetails
+	text := d.Text
+	if d.Exception != nil && d.Exception.Description != "" {
+		// "Uncaught" + description (message et pile JS)
+		text = strings.TrimSpace(d.Text + " " + d.Exception.Description)
+	}
+	b.addConsoleLog(ConsoleLog{
+		Level:     "error",
+		Type:      "exception",
+		Source:    "exception",
+		Text:      text,
+		URL:       d.URL,
+		Line:      d.LineNumber + 1,
+		Column:    d.ColumnNumber + 1,
+		Timestamp: cdpTime(p.Timestamp),
+	})
+}
+
+// handleLogEntryEvent capture une entrée du journal du navigateur (Log.entryAdded):
+// ressources en échec, violations, avertissements de sécurité...
+func (b *Browser) handleLogEntryEvent(evt Event) {
+	var p struct {
+		Entry struct {
+			Source     string  `json:"source"`
+			Level      string  `json:"level"`
+			Text       string  `json:"text"`
+			Timestamp  float64 `json:"timestamp"`
+			URL        string  `json:"url"`
+			LineNumber *int    `json:"lineNumber"`
+		} `json:"entry"`
+	}
+	if json.Unmarshal(evt.Params, &p) != nil {
+		return
+	}
+
+	e := p.Entry
+	l := ConsoleLog{
+		Level:     consoleLevel(e.Level),
+		Type:      e.Level,
+		Source:    e.Source,
+		Text:      e.Text,
+		URL:       e.URL,
+		Timestamp: cdpTime(e.Timestamp),
+	}
+	if e.LineNumber != nil {
+		l.Line = *e.LineNumber + 1
+	}
+	b.addConsoleLog(l)
+}
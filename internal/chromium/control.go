@@ -0,0 +1,82 @@
+// Package chromium - Interface de contrôle du browser utilisée par ToolsManager
+package chromium
+
+import (
+	"context"
+	"time"
+)
+
+// BrowserControl opérations du browser dont dépend ToolsManager
+// *Browser l'implémente via CDP; FakeBrowser permet de tester le dispatch sans Chrome
+type BrowserControl interface {
+	// Cycle de vie
+	Alive() bool
+	Launched() bool
+	DebugPort() int
+	Close() error
+	SetOperationContext(ctx context.Context)
+	EnableProxyAuth(username, password string) error
+	GetTargets() ([]TargetInfo, error)
+	SetFrame(frame string) (FrameInfo, error)
+	SetViewport(width, height int, deviceScaleFactor float64, mobile bool) error
+	SetUserAgent(ua string) error
+
+	// Page
+	Navigate(url string) error
+	NavigateWithTimeout(url string, timeout time.Duration) (bool, error)
+	SetDocumentContent(html string) error
+	WithScratchPage(fn func() error) error
+	AddScriptOnNewDocument(source string) (string, error)
+	RemoveScriptOnNewDocument(id string) error
+	GetURL() (string, error)
+	GetTitle() (string, error)
+	GetHTML() (string, error)
+	GetElementHTML(selector string) (string, error)
+	Describe(maxElements int) ([]AXElement, bool, error)
+	Screenshot(format string, quality int, fullPage bool) ([]byte, error)
+	ScreenshotFullPage(format string, quality int) ([]byte, int, error)
+	ScreenshotElement(selector string, format string, quality int) ([]byte, error)
+	ScreenshotAll(format string, quality int) ([]TabScreenshot, error)
+	PDF(opts PDFOptions) ([]byte, error)
+
+	// Interaction
+	EvaluateWithOptions(expression string, awaitPromise bool) (interface{}, error)
+	Click(selector string) error
+	Type(selector, text string) error
+	PressKey(key string, modifiers []string) error
+	SelectOption(selector, value string) error
+	FillForm(fields map[string]string) []FormFieldResult
+	WaitForSelector(selector string, timeout time.Duration) error
+	WaitForFunction(expression string, timeout time.Duration) error
+	WaitNetworkIdle(quiet, timeout time.Duration) (int, error)
+	EnableMonitoring() error
+	GetConsoleLogs(level string, clear bool) []ConsoleLog
+	GetCookies() ([]map[string]interface{}, error)
+	SetCookie(cookie Cookie) error
+}
+
+var _ BrowserControl = (*Browser)(nil)
+
+// LaunchFunc démarre un browser (Launch par défaut)
+type LaunchFunc func(cfg *Config) (BrowserControl, error)
+
+// ConnectFunc se connecte à un browser existant (Connect par défaut)
+type ConnectFunc func(port int) (BrowserControl, error)
+
+// launchBrowser adapte Launch à LaunchFunc (évite une interface non-nil enveloppant un *Browser nil)
+func launchBrowser(cfg *Config) (BrowserControl, error) {
+	b, err := Launch(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// connectBrowser adapte Connect à ConnectFunc
+func connectBrowser(port int) (BrowserControl, error) {
+	b, err := Connect(port)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
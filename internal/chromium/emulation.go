@@ -0,0 +1,84 @@
+// Package chromium - Émulation d'appareil: viewport et user agent (action set_viewport)
+package chromium
+
+import (
+	"fmt"
+)
+
+// maxDeviceScaleFactor ratio de pixels accepté au plus par set_viewport
+const maxDeviceScaleFactor = 4
+
+// Viewport dimensions et métriques d'appareil émulées par SetViewport
+type Viewport struct {
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor"` // 0 = facteur de l'écran réel
+	Mobile            bool    `json:"mobile"`
+}
+
+// SetViewport émule un viewport de width x height pixels CSS (Emulation.setDeviceMetricsOverride)
+// mobile active aussi l'émulation tactile; l'override reste actif jusqu'à la fermeture du browser
+func (b *Browser) SetViewport(width, height int, deviceScaleFactor float64, mobile bool) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid viewport %dx%d: width and height must be positive", width, height)
+	}
+	if deviceScaleFactor < 0 {
+		return fmt.Errorf("invalid device scale factor: %v", deviceScaleFactor)
+	}
+
+	vp := Viewport{Width: width, Height: height, DeviceScaleFactor: deviceScaleFactor, Mobile: mobile}
+	if err := b.applyViewport(vp); err != nil {
+		return err
+	}
+	if _, err := b.Call("Emulation.setTouchEmulationEnabled", map[string]interface{}{
+		"enabled": mobile,
+	}); err != nil {
+		return fmt.Errorf("failed to set touch emulation: %w", err)
+	}
+
+	b.mu.Lock()
+	b.viewport = &vp
+	b.mu.Unlock()
+	return nil
+}
+
+// SetUserAgent remplace le user agent de la page (navigator.userAgent et en-tête User-Agent)
+// Emulation.setUserAgentOverride, ou Network.setUserAgentOverride sur les versions qui ne l'ont pas
+func (b *Browser) SetUserAgent(ua string) error {
+	if ua == "" {
+		return fmt.Errorf("user agent is empty")
+	}
+	params := map[string]interface{}{"userAgent": ua}
+	if _, err := b.Call("Emulation.setUserAgentOverride", params); err != nil {
+		if _, err := b.Call("Network.setUserAgentOverride", params); err != nil {
+			return fmt.Errorf("failed to set user agent: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyViewport envoie les métriques d'appareil de vp
+func (b *Browser) applyViewport(vp Viewport) error {
+	if _, err := b.Call("Emulation.setDeviceMetricsOverride", map[string]interface{}{
+		"width":             vp.Width,
+		"height":            vp.Height,
+		"deviceScaleFactor": vp.DeviceScaleFactor,
+		"mobile":            vp.Mobile,
+	}); err != nil {
+		return fmt.Errorf("failed to set viewport: %w", err)
+	}
+	return nil
+}
+
+// restoreViewport rétablit le viewport émulé par SetViewport, ou celui de la fenêtre s'il n'y en a pas
+// (après un redimensionnement temporaire, ex: ScreenshotFullPage)
+func (b *Browser) restoreViewport() error {
+	b.mu.Lock()
+	vp := b.viewport
+	b.mu.Unlock()
+	if vp != nil {
+		return b.applyViewport(*vp)
+	}
+	_, err := b.Call("Emulation.clearDeviceMetricsOverride", nil)
+	return err
+}
@@ -0,0 +1,282 @@
+// Package chromium - Browser factice pour tester les actions sans Chrome
+package chromium
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeBrowser implémente BrowserControl en mémoire
+// Les champs exportés scriptent les réponses; Err, si défini, est retourné par chaque opération
+type FakeBrowser struct {
+	mu sync.Mutex
+
+	URL         string
+	Title       string
+	HTML        string
+	Elements    []AXElement
+	EvalResult  interface{}
+	Image       []byte
+	PageHeight  int
+	Cookies     []map[string]interface{}
+	Targets     []TargetInfo
+	Frames      []FrameInfo // Frames de la page (principal en premier), cibles de SetFrame
+	Port        int
+	WasLaunched bool
+	Requests    int              // Requêtes réseau retournées par WaitNetworkIdle
+	ConsoleLogs []ConsoleLog     // Messages retournés par GetConsoleLogs
+	Dead        bool             // Alive() retourne false
+	RedirectTo  string           // URL finale après Navigate (redirection)
+	LoadTimeout bool             // Navigate expire avant l'événement load
+	FieldErrors map[string]error // Échecs de FillForm par sélecteur (remplissage partiel)
+	Err         error
+
+	Calls  []string // Opérations reçues, dans l'ordre ("Navigate https://...")
+	Closed bool
+}
+
+// record enregistre l'appel et retourne Err
+func (f *FakeBrowser) record(format string, args ...interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, fmt.Sprintf(format, args...))
+	return f.Err
+}
+
+func (f *FakeBrowser) Alive() bool    { return !f.Dead && !f.Closed }
+func (f *FakeBrowser) Launched() bool { return f.WasLaunched }
+func (f *FakeBrowser) DebugPort() int { return f.Port }
+
+func (f *FakeBrowser) Close() error {
+	f.record("Close")
+	f.Closed = true
+	return nil
+}
+
+func (f *FakeBrowser) SetOperationContext(ctx context.Context) {}
+
+func (f *FakeBrowser) EnableProxyAuth(username, password string) error {
+	return f.record("EnableProxyAuth %s", username)
+}
+
+func (f *FakeBrowser) GetTargets() ([]TargetInfo, error) {
+	if err := f.record("GetTargets"); err != nil {
+		return nil, err
+	}
+	if f.Targets == nil {
+		return []TargetInfo{{TargetID: "fake", Type: "page", Title: f.Title, URL: f.URL}}, nil
+	}
+	return f.Targets, nil
+}
+
+func (f *FakeBrowser) SetFrame(frame string) (FrameInfo, error) {
+	if err := f.record("SetFrame %s", frame); err != nil {
+		return FrameInfo{}, err
+	}
+	if frame == "" {
+		return FrameInfo{}, nil
+	}
+	return matchFrame(f.Frames, frame)
+}
+
+func (f *FakeBrowser) SetViewport(width, height int, deviceScaleFactor float64, mobile bool) error {
+	return f.record("SetViewport %dx%d %v %v", width, height, deviceScaleFactor, mobile)
+}
+
+func (f *FakeBrowser) SetUserAgent(ua string) error {
+	return f.record("SetUserAgent %s", ua)
+}
+
+func (f *FakeBrowser) Navigate(url string) error {
+	_, err := f.NavigateWithTimeout(url, defaultNavigateTimeout)
+	return err
+}
+
+// NavigateWithTimeout charge url instantanément, ou RedirectTo si défini
+func (f *FakeBrowser) NavigateWithTimeout(url string, timeout time.Duration) (bool, error) {
+	if err := f.record("Navigate %s", url); err != nil {
+		return false, err
+	}
+	f.URL = url
+	if f.RedirectTo != "" {
+		f.URL = f.RedirectTo
+	}
+	return !f.LoadTimeout, nil
+}
+
+func (f *FakeBrowser) SetDocumentContent(html string) error {
+	if err := f.record("SetDocumentContent %d bytes", len(html)); err != nil {
+		return err
+	}
+	f.URL = "about:blank"
+	f.HTML = html
+	return nil
+}
+
+func (f *FakeBrowser) WithScratchPage(fn func() error) error {
+	if err := f.record("OpenScratchPage"); err != nil {
+		return err
+	}
+	url, html := f.URL, f.HTML
+	defer func() {
+		f.URL, f.HTML = url, html
+		f.record("CloseScratchPage")
+	}()
+	return fn()
+}
+
+func (f *FakeBrowser) AddScriptOnNewDocument(source string) (string, error) {
+	if err := f.record("AddScriptOnNewDocument %s", source); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("fake-script-%d", len(f.Calls)), nil
+}
+
+func (f *FakeBrowser) RemoveScriptOnNewDocument(id string) error {
+	return f.record("RemoveScriptOnNewDocument %s", id)
+}
+
+func (f *FakeBrowser) GetURL() (string, error)   { return f.URL, f.record("GetURL") }
+func (f *FakeBrowser) GetTitle() (string, error) { return f.Title, f.record("GetTitle") }
+func (f *FakeBrowser) GetHTML() (string, error)  { return f.HTML, f.record("GetHTML") }
+
+func (f *FakeBrowser) GetElementHTML(selector string) (string, error) {
+	return f.HTML, f.record("GetElementHTML %s", selector)
+}
+
+func (f *FakeBrowser) Describe(maxElements int) ([]AXElement, bool, error) {
+	if err := f.record("Describe %d", maxElements); err != nil {
+		return nil, false, err
+	}
+	if maxElements > 0 && len(f.Elements) > maxElements {
+		return f.Elements[:maxElements], true, nil
+	}
+	return f.Elements, false, nil
+}
+
+func (f *FakeBrowser) Screenshot(format string, quality int, fullPage bool) ([]byte, error) {
+	return f.Image, f.record("Screenshot %s %d %t", format, quality, fullPage)
+}
+
+func (f *FakeBrowser) ScreenshotFullPage(format string, quality int) ([]byte, int, error) {
+	return f.Image, f.PageHeight, f.record("ScreenshotFullPage %s %d", format, quality)
+}
+
+func (f *FakeBrowser) ScreenshotElement(selector string, format string, quality int) ([]byte, error) {
+	return f.Image, f.record("ScreenshotElement %s %s %d", selector, format, quality)
+}
+
+func (f *FakeBrowser) ScreenshotAll(format string, quality int) ([]TabScreenshot, error) {
+	targets, err := f.GetTargets()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.record("ScreenshotAll %s %d", format, quality); err != nil {
+		return nil, err
+	}
+	var shots []TabScreenshot
+	for _, t := range targets {
+		if t.Type == "page" {
+			shots = append(shots, TabScreenshot{TargetInfo: t, Data: f.Image})
+		}
+	}
+	return shots, nil
+}
+
+func (f *FakeBrowser) PDF(opts PDFOptions) ([]byte, error) {
+	return f.Image, f.record("PDF %+v", opts)
+}
+
+func (f *FakeBrowser) EvaluateWithOptions(expression string, awaitPromise bool) (interface{}, error) {
+	return f.EvalResult, f.record("Evaluate %s %t", expression, awaitPromise)
+}
+
+func (f *FakeBrowser) Click(selector string) error {
+	return f.record("Click %s", selector)
+}
+
+func (f *FakeBrowser) Type(selector, text string) error {
+	return f.record("Type %s %s", selector, text)
+}
+
+func (f *FakeBrowser) PressKey(key string, modifiers []string) error {
+	return f.record("PressKey %s %v", key, modifiers)
+}
+
+func (f *FakeBrowser) SelectOption(selector, value string) error {
+	return f.record("SelectOption %s %s", selector, value)
+}
+
+func (f *FakeBrowser) FillForm(fields map[string]string) []FormFieldResult {
+	selectors := make([]string, 0, len(fields))
+	for selector := range fields {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+
+	results := make([]FormFieldResult, 0, len(selectors))
+	for _, selector := range selectors {
+		result := FormFieldResult{Selector: selector, Kind: "text", Value: fields[selector], Success: true}
+		err := f.record("FillForm %s %s", selector, fields[selector])
+		if err == nil {
+			err = f.FieldErrors[selector]
+		}
+		if err != nil {
+			result = FormFieldResult{Selector: selector, Error: err.Error()}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (f *FakeBrowser) WaitForSelector(selector string, timeout time.Duration) error {
+	return f.record("WaitForSelector %s %s", selector, timeout)
+}
+
+func (f *FakeBrowser) WaitForFunction(expression string, timeout time.Duration) error {
+	return f.record("WaitForFunction %s %s", expression, timeout)
+}
+
+func (f *FakeBrowser) WaitNetworkIdle(quiet, timeout time.Duration) (int, error) {
+	return f.Requests, f.record("WaitNetworkIdle %s %s", quiet, timeout)
+}
+
+func (f *FakeBrowser) EnableMonitoring() error {
+	return f.record("EnableMonitoring")
+}
+
+func (f *FakeBrowser) GetConsoleLogs(level string, clear bool) []ConsoleLog {
+	f.record("GetConsoleLogs %s %v", level, clear)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	logs := []ConsoleLog{}
+	for _, l := range f.ConsoleLogs {
+		if level == "" || l.Level == level {
+			logs = append(logs, l)
+		}
+	}
+	if clear {
+		f.ConsoleLogs = nil
+	}
+	return logs
+}
+
+func (f *FakeBrowser) GetCookies() ([]map[string]interface{}, error) {
+	return f.Cookies, f.record("GetCookies")
+}
+
+func (f *FakeBrowser) SetCookie(cookie Cookie) error {
+	if err := f.record("SetCookie %s", cookie.Name); err != nil {
+		return err
+	}
+	f.Cookies = append(f.Cookies, map[string]interface{}{
+		"name": cookie.Name, "value": cookie.Value, "domain": cookie.Domain, "path": cookie.Path,
+		"secure": cookie.Secure, "httpOnly": cookie.HTTPOnly, "sameSite": cookie.SameSite, "expires": cookie.Expires,
+	})
+	return nil
+}
+
+var _ BrowserControl = (*FakeBrowser)(nil)
@@ -0,0 +1,118 @@
+// Package chromium - Listes déroulantes et remplissage de formulaires (actions select, fill_form)
+package chromium
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FormFieldResult résultat du remplissage d'un champ par FillForm
+type FormFieldResult struct {
+	Selector string `json:"selector"`
+	Success  bool   `json:"success"`
+	Kind     string `json:"kind,omitempty"`  // select, text, checkbox, radio ou contenteditable
+	Value    string `json:"value,omitempty"` // Valeur du champ après remplissage
+	Error    string `json:"error,omitempty"`
+}
+
+// formFieldJS renseigne un champ puis émet input et change (bubbles), comme une saisie utilisateur
+// Arguments: sélecteur, valeur, mode ("select": <select> uniquement, "fill": tout champ)
+// Les <input> et <textarea> passent par le setter natif de value pour que les frameworks (React...) voient le changement
+const formFieldJS = `(() => {
+	const el = document.querySelector('%s');
+	if (!el) return {error: 'element not found'};
+	const value = '%s';
+	const tag = el.tagName;
+	const fire = () => {
+		el.dispatchEvent(new Event('input', {bubbles: true}));
+		el.dispatchEvent(new Event('change', {bubbles: true}));
+	};
+	if ('%s' === 'select' && tag !== 'SELECT') {
+		return {error: 'element is not a <select> (' + tag.toLowerCase() + ')'};
+	}
+	if (el.disabled) return {error: 'field is disabled'};
+	if (tag === 'SELECT') {
+		const options = Array.from(el.options);
+		const opt = options.find(o => o.value === value) || options.find(o => o.text.trim() === value);
+		if (!opt) return {error: 'no option with value or text ' + JSON.stringify(value) + ' (options: ' + options.map(o => JSON.stringify(o.value)).join(', ') + ')'};
+		if (opt.disabled) return {error: 'option ' + JSON.stringify(opt.value) + ' is disabled'};
+		el.value = opt.value;
+		fire();
+		return {kind: 'select', value: el.value};
+	}
+	if (tag === 'INPUT' && (el.type === 'checkbox' || el.type === 'radio')) {
+		const on = /^(true|1|on|yes|checked)$/i.test(value) || (el.type === 'radio' && value === el.value);
+		if (el.checked !== on) {
+			el.checked = on;
+			fire();
+		}
+		return {kind: el.type, value: String(el.checked)};
+	}
+	if (tag === 'INPUT' || tag === 'TEXTAREA') {
+		if (el.readOnly) return {error: 'field is read-only'};
+		const proto = tag === 'INPUT' ? HTMLInputElement.prototype : HTMLTextAreaElement.prototype;
+		Object.getOwnPropertyDescriptor(proto, 'value').set.call(el, value);
+		fire();
+		return {kind: 'text', value: el.value};
+	}
+	if (el.isContentEditable) {
+		el.textContent = value;
+		el.dispatchEvent(new Event('input', {bubbles: true}));
+		return {kind: 'contenteditable', value: el.textContent};
+	}
+	return {error: 'element is not a form field (' + tag.toLowerCase() + ')'};
+})()`
+
+// SelectOption choisit l'option d'un <select> par sa valeur, ou à défaut par son texte, puis émet change
+func (b *Browser) SelectOption(selector, value string) error {
+	result := b.fillField(selector, value, "select")
+	if !result.Success {
+		return fmt.Errorf("select %s: %s", selector, result.Error)
+	}
+	return nil
+}
+
+// FillForm renseigne plusieurs champs (sélecteur CSS -> valeur), dans l'ordre des sélecteurs
+// Un échec n'interrompt pas les autres champs: le résultat indique le succès ou l'erreur de chacun
+// Cases à cocher: true, 1, on, yes ou checked cochent; boutons radio: aussi leur propre value
+func (b *Browser) FillForm(fields map[string]string) []FormFieldResult {
+	selectors := make([]string, 0, len(fields))
+	for selector := range fields {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+
+	results := make([]FormFieldResult, 0, len(selectors))
+	for _, selector := range selectors {
+		results = append(results, b.fillField(selector, fields[selector], "fill"))
+	}
+	return results
+}
+
+// fillField renseigne un champ via formFieldJS
+func (b *Browser) fillField(selector, value, mode string) FormFieldResult {
+	result := FormFieldResult{Selector: selector}
+	if err := validateCSSSelector(selector); err != nil {
+		result.Error = fmt.Sprintf("invalid selector: %v", err)
+		return result
+	}
+
+	raw, err := b.Evaluate(fmt.Sprintf(formFieldJS, escapeJSString(selector), escapeJSString(value), mode))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	outcome, ok := raw.(map[string]interface{})
+	if !ok {
+		result.Error = fmt.Sprintf("unexpected result: %v", raw)
+		return result
+	}
+	if msg, ok := outcome["error"].(string); ok {
+		result.Error = msg
+		return result
+	}
+	result.Success = true
+	result.Kind, _ = outcome["kind"].(string)
+	result.Value, _ = outcome["value"].(string)
+	return result
+}
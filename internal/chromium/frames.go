@@ -0,0 +1,139 @@
+// Package chromium - Exécution dans un frame/iframe (paramètre frame de evaluate, click, type)
+package chromium
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FrameInfo décrit un frame de la page courante (Page.getFrameTree)
+type FrameInfo struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parentId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	URL      string `json:"url"`
+}
+
+// frameWorldName nom du monde isolé créé dans le frame ciblé
+const frameWorldName = "holow-frame"
+
+// GetFrames liste les frames de la page courante, frame principal en premier
+func (b *Browser) GetFrames() ([]FrameInfo, error) {
+	result, err := b.Call("Page.getFrameTree", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type frameTree struct {
+		Frame       FrameInfo   `json:"frame"`
+		ChildFrames []frameTree `json:"childFrames"`
+	}
+	var resp struct {
+		FrameTree frameTree `json:"frameTree"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return nil, err
+	}
+
+	var frames []FrameInfo
+	var walk func(t frameTree)
+	walk = func(t frameTree) {
+		frames = append(frames, t.Frame)
+		for _, child := range t.ChildFrames {
+			walk(child)
+		}
+	}
+	walk(resp.FrameTree)
+	return frames, nil
+}
+
+// SetFrame oriente les évaluations suivantes (evaluate, click, type...) vers un frame désigné
+// par son identifiant, son nom ou un fragment de son URL; "" revient au frame principal.
+// Le code s'exécute dans un monde isolé du frame: DOM partagé, globales JS de la page invisibles.
+// Les iframes d'une autre origine isolées dans leur propre processus (OOPIF) ne sont pas accessibles.
+//
+// Le frame choisi vaut pour toutes les évaluations du Browser jusqu'au SetFrame suivant:
+// l'appelant sérialise les opérations entre SetFrame(frame) et SetFrame("") (cf. ToolsManager.enterFrame)
+func (b *Browser) SetFrame(frame string) (FrameInfo, error) {
+	b.setFrameContext(0)
+	if frame == "" {
+		return FrameInfo{}, nil
+	}
+
+	frames, err := b.GetFrames()
+	if err != nil {
+		return FrameInfo{}, err
+	}
+	target, err := matchFrame(frames, frame)
+	if err != nil {
+		return FrameInfo{}, err
+	}
+
+	result, err := b.Call("Page.createIsolatedWorld", map[string]interface{}{
+		"frameId":   target.ID,
+		"worldName": frameWorldName,
+	})
+	if err != nil {
+		return FrameInfo{}, fmt.Errorf("frame %s: %w", target.ID, err)
+	}
+	var resp struct {
+		ExecutionContextID int `json:"executionContextId"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return FrameInfo{}, err
+	}
+	b.setFrameContext(resp.ExecutionContextID)
+	return target, nil
+}
+
+// setFrameContext fixe le contexte d'exécution des évaluations (0 = frame principal)
+func (b *Browser) setFrameContext(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.frameContextID = id
+}
+
+// frameContext retourne le contexte d'exécution choisi par SetFrame (0 = frame principal)
+func (b *Browser) frameContext() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.frameContextID
+}
+
+// matchFrame cherche le frame par identifiant exact, puis parmi les sous-frames par nom exact puis fragment d'URL
+func matchFrame(frames []FrameInfo, frame string) (FrameInfo, error) {
+	for _, f := range frames {
+		if f.ID == frame {
+			return f, nil
+		}
+	}
+	if len(frames) > 1 {
+		for _, f := range frames[1:] {
+			if f.Name == frame {
+				return f, nil
+			}
+		}
+		for _, f := range frames[1:] {
+			if strings.Contains(f.URL, frame) {
+				return f, nil
+			}
+		}
+	}
+
+	available := make([]string, 0, len(frames))
+	for i, f := range frames {
+		if i == 0 {
+			continue
+		}
+		label := f.ID
+		if f.Name != "" {
+			label += " name=" + f.Name
+		}
+		available = append(available, label+" url="+f.URL)
+	}
+	if len(available) == 0 {
+		return FrameInfo{}, fmt.Errorf("frame not found: %s (page has no iframe)", frame)
+	}
+	return FrameInfo{}, fmt.Errorf("frame not found: %s (available: %s)", frame, strings.Join(available, "; "))
+}
@@ -0,0 +1,156 @@
+// Package chromium - Touches spéciales et raccourcis clavier (action press_key)
+package chromium
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// keyDefinition touche telle que décrite à Input.dispatchKeyEvent
+type keyDefinition struct {
+	key     string // Valeur de KeyboardEvent.key ("Enter", "a")
+	code    string // Touche physique, KeyboardEvent.code ("Enter", "KeyA")
+	keyCode int    // windowsVirtualKeyCode
+	text    string // Texte produit, vide pour les touches sans caractère
+}
+
+// namedKeys touches non imprimables ou ambiguës, indexées en minuscules
+var namedKeys = map[string]keyDefinition{
+	"enter":      {"Enter", "Enter", 13, "\r"},
+	"tab":        {"Tab", "Tab", 9, ""},
+	"backspace":  {"Backspace", "Backspace", 8, ""},
+	"delete":     {"Delete", "Delete", 46, ""},
+	"escape":     {"Escape", "Escape", 27, ""},
+	"space":      {" ", "Space", 32, " "},
+	"arrowup":    {"ArrowUp", "ArrowUp", 38, ""},
+	"arrowdown":  {"ArrowDown", "ArrowDown", 40, ""},
+	"arrowleft":  {"ArrowLeft", "ArrowLeft", 37, ""},
+	"arrowright": {"ArrowRight", "ArrowRight", 39, ""},
+	"home":       {"Home", "Home", 36, ""},
+	"end":        {"End", "End", 35, ""},
+	"pageup":     {"PageUp", "PageUp", 33, ""},
+	"pagedown":   {"PageDown", "PageDown", 34, ""},
+	"insert":     {"Insert", "Insert", 45, ""},
+}
+
+// keyAliases autres noms acceptés pour les touches de namedKeys
+var keyAliases = map[string]string{
+	"return": "enter",
+	"esc":    "escape",
+	"del":    "delete",
+	" ":      "space",
+	"up":     "arrowup",
+	"down":   "arrowdown",
+	"left":   "arrowleft",
+	"right":  "arrowright",
+}
+
+// keyModifiers bits du champ modifiers de Input.dispatchKeyEvent
+var keyModifiers = map[string]int{
+	"alt":     1,
+	"option":  1,
+	"control": 2,
+	"ctrl":    2,
+	"meta":    4,
+	"cmd":     4,
+	"command": 4,
+	"shift":   8,
+}
+
+// lookupKey résout un nom de touche: touche nommée, F1-F12 ou caractère unique
+func lookupKey(name string) (keyDefinition, error) {
+	lower := strings.ToLower(name)
+	if alias, ok := keyAliases[lower]; ok {
+		lower = alias
+	}
+	if def, ok := namedKeys[lower]; ok {
+		return def, nil
+	}
+
+	if strings.HasPrefix(lower, "f") {
+		if n, err := strconv.Atoi(lower[1:]); err == nil && n >= 1 && n <= 12 {
+			return keyDefinition{key: fmt.Sprintf("F%d", n), code: fmt.Sprintf("F%d", n), keyCode: 111 + n}, nil
+		}
+	}
+
+	if utf8.RuneCountInString(name) != 1 {
+		return keyDefinition{}, fmt.Errorf("unknown key %q: expected a single character, F1-F12 or one of Enter, Tab, Backspace, Delete, Escape, Space, ArrowUp, ArrowDown, ArrowLeft, ArrowRight, Home, End, PageUp, PageDown, Insert", name)
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	def := keyDefinition{key: name, text: name}
+	switch upper := unicode.ToUpper(r); {
+	case upper >= 'A' && upper <= 'Z':
+		def.code, def.keyCode = "Key"+string(upper), int(upper)
+	case r >= '0' && r <= '9':
+		def.code, def.keyCode = "Digit"+name, int(r)
+	}
+	return def, nil
+}
+
+// modifierMask convertit des noms de modificateurs (Alt, Control/Ctrl, Meta/Cmd, Shift) en bitmask CDP
+func modifierMask(modifiers []string) (int, error) {
+	mask := 0
+	for _, m := range modifiers {
+		bit, ok := keyModifiers[strings.ToLower(m)]
+		if !ok {
+			return 0, fmt.Errorf("unknown modifier %q: expected Alt, Control, Meta or Shift", m)
+		}
+		mask |= bit
+	}
+	return mask, nil
+}
+
+// PressKey appuie puis relâche une touche sur l'élément qui a le focus, avec modifiers maintenus
+// Une touche produisant du texte (Enter, lettres) l'insère, sauf avec Alt, Control ou Meta (raccourci)
+func (b *Browser) PressKey(key string, modifiers []string) error {
+	def, err := lookupKey(key)
+	if err != nil {
+		return err
+	}
+	mask, err := modifierMask(modifiers)
+	if err != nil {
+		return err
+	}
+
+	text := def.text
+	if mask&8 != 0 && strings.HasPrefix(def.code, "Key") {
+		def.key = strings.ToUpper(def.key)
+		text = def.key
+	}
+	if mask&(1|2|4) != 0 {
+		text = ""
+	}
+
+	down := map[string]interface{}{
+		"type":                  "rawKeyDown",
+		"key":                   def.key,
+		"code":                  def.code,
+		"windowsVirtualKeyCode": def.keyCode,
+		"nativeVirtualKeyCode":  def.keyCode,
+		"modifiers":             mask,
+	}
+	if text != "" {
+		// keyDown avec texte: l'équivalent de rawKeyDown suivi de char (soumission d'un formulaire sur Enter)
+		down["type"] = "keyDown"
+		down["text"] = text
+		down["unmodifiedText"] = text
+	}
+	if _, err := b.Call("Input.dispatchKeyEvent", down); err != nil {
+		return fmt.Errorf("failed to press %s: %w", def.key, err)
+	}
+
+	if _, err := b.Call("Input.dispatchKeyEvent", map[string]interface{}{
+		"type":                  "keyUp",
+		"key":                   def.key,
+		"code":                  def.code,
+		"windowsVirtualKeyCode": def.keyCode,
+		"nativeVirtualKeyCode":  def.keyCode,
+		"modifiers":             mask,
+	}); err != nil {
+		return fmt.Errorf("failed to release %s: %w", def.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,459 @@
+// Package chromium - Conversion du contenu principal d'une page en markdown (action get_markdown)
+package chromium
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// minMainContentScore texte de paragraphes (caractères) en dessous duquel tout le body est converti
+const minMainContentScore = 250
+
+// minScoredParagraph longueur minimale d'un paragraphe compté dans le score d'un conteneur
+const minScoredParagraph = 25
+
+// noiseElements éléments retirés avant conversion: code, médias interactifs, navigation, formulaires
+var noiseElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Canvas: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+	atom.Dialog: true,
+}
+
+// noiseRoles rôles ARIA de navigation et d'habillage
+var noiseRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"search": true, "dialog": true, "alertdialog": true, "menu": true, "menubar": true,
+}
+
+// noiseClassRegex classes et ids typiques des blocs hors contenu (menus, pubs, partage, cookies...)
+var noiseClassRegex = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|menu|sidebar|footer|breadcrumbs?|cookie|consent|banner|advert|ads?|promo|social|share|sharing|newsletter|popup|modal|related|comments?)($|[\s_-])`)
+
+// blockElements éléments convertis en blocs séparés par une ligne vide
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Nav: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Pre: true, atom.Blockquote: true, atom.Hr: true,
+	atom.Table: true, atom.Figure: true, atom.Figcaption: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Address: true, atom.Details: true, atom.Summary: true, atom.Fieldset: true, atom.Center: true,
+}
+
+var (
+	mdSpaceRegex     = regexp.MustCompile(`\s+`)
+	mdBlankLineRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown convertit un document HTML en markdown
+// Avec mainOnly, seul le contenu principal est gardé (main, article unique, sinon le conteneur le plus riche
+// en paragraphes); les liens et images relatifs sont résolus par rapport à pageURL
+func htmlToMarkdown(document, pageURL string, mainOnly bool) (markdown, title string, err error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	title = pageTitle(doc)
+	removeNoise(doc, false)
+
+	root := findElement(doc, atom.Body)
+	if root == nil {
+		root = doc
+	}
+	if mainOnly {
+		if main := mainContent(root); main != nil {
+			root = main
+		}
+	}
+
+	c := &mdConverter{}
+	if base, err := url.Parse(pageURL); err == nil && base.IsAbs() {
+		c.base = base
+	}
+	markdown = strings.Join(c.blocks(root), "\n\n")
+	markdown = mdBlankLineRegex.ReplaceAllString(markdown, "\n\n")
+	return strings.TrimSpace(markdown), title, nil
+}
+
+// pageTitle retourne le titre du document (<title>, sinon premier <h1>)
+func pageTitle(doc *html.Node) string {
+	for _, a := range []atom.Atom{atom.Title, atom.H1} {
+		if n := findElement(doc, a); n != nil {
+			if t := strings.TrimSpace(mdSpaceRegex.ReplaceAllString(textContent(n), " ")); t != "" {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// removeNoise retire récursivement les éléments hors contenu et les éléments masqués
+// inContent: n est dans un article ou main, dont header et footer (titre, auteur) sont gardés
+func removeNoise(n *html.Node, inContent bool) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode || (child.Type == html.ElementNode && isNoise(child, inContent)) {
+			n.RemoveChild(child)
+		} else {
+			removeNoise(child, inContent || child.DataAtom == atom.Article || child.DataAtom == atom.Main)
+		}
+		child = next
+	}
+}
+
+// isNoise indique si un élément est de la navigation, de l'habillage ou masqué
+func isNoise(n *html.Node, inContent bool) bool {
+	if inContent && (n.DataAtom == atom.Header || n.DataAtom == atom.Footer) {
+		return false
+	}
+	if noiseElements[n.DataAtom] {
+		return true
+	}
+	if _, hidden := attr(n, "hidden"); hidden {
+		return true
+	}
+	if v, _ := attr(n, "aria-hidden"); v == "true" {
+		return true
+	}
+	if role, _ := attr(n, "role"); noiseRoles[strings.ToLower(role)] {
+		return true
+	}
+	if style, _ := attr(n, "style"); strings.Contains(strings.ReplaceAll(style, " ", ""), "display:none") {
+		return true
+	}
+	// Le conteneur principal n'est jamais retiré sur la foi de sa classe
+	if n.DataAtom == atom.Main || n.DataAtom == atom.Article || n.DataAtom == atom.Body {
+		return false
+	}
+	class, _ := attr(n, "class")
+	id, _ := attr(n, "id")
+	return noiseClassRegex.MatchString(class) || noiseClassRegex.MatchString(id)
+}
+
+// mainContent choisit le conteneur du contenu principal, nil pour garder tout le body
+func mainContent(body *html.Node) *html.Node {
+	var mains, articles []*html.Node
+	walk(body, func(n *html.Node) {
+		if role, _ := attr(n, "role"); n.DataAtom == atom.Main || role == "main" {
+			mains = append(mains, n)
+		}
+		if n.DataAtom == atom.Article {
+			articles = append(articles, n)
+		}
+	})
+	if len(mains) == 1 {
+		return mains[0]
+	}
+	if len(articles) == 1 {
+		return articles[0]
+	}
+
+	// Score: texte des paragraphes, compté pour le parent et pour moitié pour le grand-parent
+	scores := make(map[*html.Node]int)
+	walk(body, func(n *html.Node) {
+		if n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Blockquote {
+			return
+		}
+		length := len(strings.TrimSpace(textContent(n)))
+		if length < minScoredParagraph || n.Parent == nil {
+			return
+		}
+		scores[n.Parent] += length
+		if n.Parent.Parent != nil {
+			scores[n.Parent.Parent] += length / 2
+		}
+	})
+
+	// Parcours dans l'ordre du document: à score égal, le premier conteneur l'emporte
+	var best *html.Node
+	walk(body, func(n *html.Node) {
+		if score, ok := scores[n]; ok && (best == nil || score > scores[best]) {
+			best = n
+		}
+	})
+	if best == nil || best == body || scores[best] < minMainContentScore {
+		return nil
+	}
+	return best
+}
+
+// mdConverter convertit un arbre HTML en markdown
+type mdConverter struct {
+	base *url.URL // URL de la page, pour les liens relatifs (nil si inconnue)
+}
+
+// blocks convertit les enfants de n en blocs markdown; le texte inline entre deux blocs forme un paragraphe
+func (c *mdConverter) blocks(n *html.Node) []string {
+	var out []string
+	var inline strings.Builder
+	flush := func() {
+		if text := trimLines(inline.String()); text != "" {
+			out = append(out, text)
+		}
+		inline.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockElements[child.DataAtom] {
+			flush()
+			if block := c.block(child); block != "" {
+				out = append(out, block)
+			}
+			continue
+		}
+		inline.WriteString(c.inline(child))
+	}
+	flush()
+	return out
+}
+
+// block convertit un élément de bloc
+func (c *mdConverter) block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.ReplaceAll(trimLines(c.inlineChildren(n)), "\n", " ")
+		if text == "" {
+			return ""
+		}
+		level, _ := strconv.Atoi(n.Data[1:])
+		return strings.Repeat("#", level) + " " + text
+	case atom.P:
+		return trimLines(c.inlineChildren(n))
+	case atom.Ul, atom.Ol:
+		return c.list(n)
+	case atom.Pre:
+		code := strings.Trim(textContent(n), "\n")
+		if strings.TrimSpace(code) == "" {
+			return ""
+		}
+		return "```\n" + code + "\n```"
+	case atom.Blockquote:
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if inner == "" {
+			return ""
+		}
+		return prefixLines(inner, "> ", "> ")
+	case atom.Hr:
+		return "---"
+	case atom.Table:
+		return c.table(n)
+	default:
+		return strings.Join(c.blocks(n), "\n\n")
+	}
+}
+
+// list convertit une liste (ul: "- ", ol: "1. "), sous-listes indentées sous leur élément
+func (c *mdConverter) list(n *html.Node) string {
+	var items []string
+	index := 1
+	if start, ok := attr(n, "start"); ok {
+		if v, err := strconv.Atoi(start); err == nil {
+			index = v
+		}
+	}
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		content := strings.Join(c.blocks(li), "\n")
+		if content == "" {
+			continue
+		}
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table convertit un tableau en tableau GFM, la première ligne servant d'en-tête
+func (c *mdConverter) table(n *html.Node) string {
+	var rows [][]string
+	columns := 0
+	walk(n, func(tr *html.Node) {
+		if tr.DataAtom != atom.Tr {
+			return
+		}
+		var row []string
+		for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+				continue
+			}
+			text := strings.ReplaceAll(trimLines(c.inlineChildren(cell)), "\n", " ")
+			row = append(row, strings.ReplaceAll(text, "|", `\|`))
+		}
+		if len(row) > columns {
+			columns = len(row)
+		}
+		rows = append(rows, row)
+	})
+	if columns == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inlineChildren convertit les enfants de n en texte inline
+func (c *mdConverter) inlineChildren(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(c.inline(child))
+	}
+	return sb.String()
+}
+
+// inline convertit un nœud en texte inline: liens, images, emphase, code
+func (c *mdConverter) inline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return mdSpaceRegex.ReplaceAllString(n.Data, " ")
+	}
+	if n.Type != html.ElementNode {
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.A:
+		text := strings.TrimSpace(strings.ReplaceAll(c.inlineChildren(n), "\n", " "))
+		href, _ := attr(n, "href")
+		href = c.resolve(href)
+		if text == "" || href == "" || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return text
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		alt, _ := attr(n, "alt")
+		src, _ := attr(n, "src")
+		alt = strings.TrimSpace(mdSpaceRegex.ReplaceAllString(alt, " "))
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return alt
+		}
+		return "![" + alt + "](" + c.resolve(src) + ")"
+	case atom.Strong, atom.B:
+		return wrapInline(c.inlineChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(c.inlineChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(c.inlineChildren(n), "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		return wrapInline(mdSpaceRegex.ReplaceAllString(textContent(n), " "), "`")
+	}
+	if blockElements[n.DataAtom] {
+		// Bloc imbriqué dans du texte inline (ex: div dans un lien): séparé par des espaces
+		return " " + c.inlineChildren(n) + " "
+	}
+	return c.inlineChildren(n)
+}
+
+// resolve rend une URL absolue par rapport à l'URL de la page
+func (c *mdConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || c.base == nil {
+		return ref
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// wrapInline entoure un texte de marker, espaces de bord laissés à l'extérieur ("**gras** suite")
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+// trimLines retire les espaces de bord de chaque ligne et les lignes vides aux extrémités
+func trimLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// prefixLines préfixe la première ligne par first et les suivantes (non vides) par rest
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + line
+		case line != "":
+			lines[i] = rest + line
+		case strings.TrimSpace(rest) != "":
+			lines[i] = strings.TrimRight(rest, " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textContent concatène le texte de tous les descendants de n
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(d *html.Node) {
+		if d.Type == html.TextNode {
+			sb.WriteString(d.Data)
+		}
+	})
+	return sb.String()
+}
+
+// findElement retourne le premier élément a de l'arbre n (parcours en profondeur)
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// walk appelle fn sur n et tous ses descendants
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+// attr retourne la valeur d'un attribut et sa présence
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
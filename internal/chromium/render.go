@@ -0,0 +1,124 @@
+// Package chromium - Rendu d'un document HTML/markdown en PDF ou image (action render)
+package chromium
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdownPage gabarit HTML autour du markdown converti (titre, corps)
+const markdownPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; max-width: 860px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+pre, code { font-family: ui-monospace, Menlo, Consolas, monospace; background: #f6f8fa; border-radius: 4px; }
+pre { padding: 1em; overflow-x: auto; }
+code { padding: 0.1em 0.3em; }
+pre code { padding: 0; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.4em 0.8em; }
+blockquote { margin: 0; padding: 0 1em; color: #59636e; border-left: 0.25em solid #d0d7de; }
+img { max-width: 100%%; }
+</style>
+</head>
+<body>
+%s
+</body>
+</html>`
+
+// markdownRenderer convertit le markdown (GFM: tableaux, listes de tâches, liens auto, barré)
+var markdownRenderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// markdownToHTML convertit markdown en document HTML complet
+func markdownToHTML(markdown, title string) (string, error) {
+	var body bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(markdown), &body); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(markdownPage, html.EscapeString(title), body.String()), nil
+}
+
+// render charge html (ou markdown converti) dans un onglet temporaire puis retourne un PDF ou une capture
+func (m *ToolsManager) render(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	source := "html"
+	document, _ := args["html"].(string)
+	if md, ok := args["markdown"].(string); ok && md != "" {
+		if document != "" {
+			return nil, fmt.Errorf("html and markdown are mutually exclusive")
+		}
+		title, _ := args["title"].(string)
+		var err error
+		if document, err = markdownToHTML(md, title); err != nil {
+			return nil, fmt.Errorf("markdown conversion failed: %w", err)
+		}
+		source = "markdown"
+	}
+	if document == "" {
+		return nil, fmt.Errorf("html or markdown is required for render")
+	}
+
+	output := "pdf"
+	if o, ok := args["output"].(string); ok && o != "" {
+		output = o
+	}
+	if output != "pdf" && output != "png" && output != "jpeg" {
+		return nil, fmt.Errorf("invalid output: %s (expected pdf, png or jpeg)", output)
+	}
+
+	// Rendu dans un onglet temporaire: la page de l'utilisateur reste intacte
+	var result interface{}
+	err := m.browser.WithScratchPage(func() error {
+		if err := m.browser.SetDocumentContent(document); err != nil {
+			return err
+		}
+
+		// Laisser charger images, feuilles de style et polices référencées par le document
+		quiet := defaultNetworkQuiet
+		if q, ok := args["quiet_ms"].(float64); ok && q > 0 {
+			quiet = time.Duration(q) * time.Millisecond
+		}
+		timeout := 30 * time.Second
+		if t, ok := args["timeout"].(float64); ok && t > 0 {
+			timeout = time.Duration(t) * time.Second
+		}
+		if _, err := m.browser.WaitNetworkIdle(quiet, timeout); err != nil {
+			return err
+		}
+
+		var err error
+		if output == "pdf" {
+			result, err = m.pdf(args)
+		} else {
+			captureArgs := map[string]interface{}{"format": output, "full_page": true}
+			for _, key := range []string{"full_page", "mode", "path", "save"} {
+				if v, ok := args[key]; ok {
+					captureArgs[key] = v
+				}
+			}
+			result, err = m.screenshot(captureArgs)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := result.(map[string]interface{}); ok {
+		r["action"] = "render"
+		r["source"] = source
+		r["output"] = output
+	}
+	return result, nil
+}
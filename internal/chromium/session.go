@@ -0,0 +1,189 @@
+// Package chromium - Sauvegarde et restauration de l'état de session (actions session_save, session_restore)
+package chromium
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// sessionFormat version du format Session produit par session_save
+const sessionFormat = 1
+
+// Session état réutilisable d'une session navigateur: cookies, localStorage de l'origine courante et URL
+type Session struct {
+	Version      int               `json:"version"`
+	URL          string            `json:"url"`
+	Origin       string            `json:"origin,omitempty"`
+	Cookies      []Cookie          `json:"cookies"`
+	LocalStorage map[string]string `json:"localStorage,omitempty"`
+	SavedAt      int64             `json:"savedAt"`
+}
+
+// storageSnapshotJS lit le localStorage de la page (vide si l'origine n'y a pas accès: about:blank, data:)
+const storageSnapshotJS = `(() => {
+	const items = {};
+	try {
+		for (let i = 0; i < localStorage.length; i++) {
+			const key = localStorage.key(i);
+			items[key] = localStorage.getItem(key);
+		}
+	} catch (e) {}
+	return {origin: location.origin, items: items};
+})()`
+
+// storageRestoreJS script injecté avant les scripts de la page: remplit le localStorage de la seule origine sauvegardée
+const storageRestoreJS = `(() => {
+	if (location.origin !== %s) return;
+	const items = %s;
+	try {
+		for (const key in items) localStorage.setItem(key, items[key]);
+	} catch (e) {}
+})()`
+
+// sessionSave capture cookies, localStorage et URL de la page courante
+func (m *ToolsManager) sessionSave() (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	url, err := m.browser.GetURL()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := m.browser.GetCookies()
+	if err != nil {
+		return nil, err
+	}
+	session := Session{
+		Version: sessionFormat,
+		URL:     url,
+		Cookies: make([]Cookie, 0, len(raw)),
+		SavedAt: time.Now().Unix(),
+	}
+	for _, c := range raw {
+		session.Cookies = append(session.Cookies, cookieFromCDP(c))
+	}
+
+	snapshot, err := m.browser.EvaluateWithOptions(storageSnapshotJS, false)
+	if err != nil {
+		return nil, fmt.Errorf("localStorage snapshot failed: %w", err)
+	}
+	if s, ok := snapshot.(map[string]interface{}); ok {
+		session.Origin, _ = s["origin"].(string)
+		if items, ok := s["items"].(map[string]interface{}); ok && len(items) > 0 {
+			session.LocalStorage = make(map[string]string, len(items))
+			for k, v := range items {
+				session.LocalStorage[k], _ = v.(string)
+			}
+		}
+	}
+	if session.Origin == "null" {
+		session.Origin = "" // Origine opaque: rien à restaurer
+	}
+
+	return map[string]interface{}{
+		"success":      true,
+		"action":       "session_save",
+		"session":      session,
+		"cookies":      len(session.Cookies),
+		"localStorage": len(session.LocalStorage),
+	}, nil
+}
+
+// sessionRestore réapplique les cookies d'une session sauvegardée, puis navigue vers son URL
+// en remplissant le localStorage de son origine avant l'exécution des scripts de la page
+func (m *ToolsManager) sessionRestore(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	session, err := parseSession(args["session"])
+	if err != nil {
+		return nil, err
+	}
+
+	now := float64(time.Now().Unix())
+	restored, expired := 0, 0
+	for _, c := range session.Cookies {
+		if c.Expires > 0 && c.Expires <= now {
+			expired++
+			continue
+		}
+		if err := m.browser.SetCookie(c); err != nil {
+			return nil, fmt.Errorf("failed to restore cookie %s: %w", c.Name, err)
+		}
+		restored++
+	}
+
+	if session.Origin != "" && len(session.LocalStorage) > 0 {
+		origin, _ := json.Marshal(session.Origin)
+		items, _ := json.Marshal(session.LocalStorage)
+		id, err := m.browser.AddScriptOnNewDocument(fmt.Sprintf(storageRestoreJS, origin, items))
+		if err != nil {
+			return nil, fmt.Errorf("localStorage restore failed: %w", err)
+		}
+		defer m.browser.RemoveScriptOnNewDocument(id)
+	}
+
+	if session.URL != "" {
+		if err := m.browser.Navigate(session.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"success":         true,
+		"action":          "session_restore",
+		"url":             session.URL,
+		"cookies":         restored,
+		"expired_cookies": expired,
+		"localStorage":    len(session.LocalStorage),
+	}, nil
+}
+
+// parseSession accepte l'objet session de session_save ou sa forme JSON sérialisée
+func parseSession(value interface{}) (*Session, error) {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("session is required for session_restore")
+	case string:
+		data = []byte(v)
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("invalid session: %w", err)
+		}
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid session: %w", err)
+	}
+	if session.Version > sessionFormat {
+		return nil, fmt.Errorf("unsupported session version: %d (max %d)", session.Version, sessionFormat)
+	}
+	if session.URL == "" && len(session.Cookies) == 0 {
+		return nil, fmt.Errorf("invalid session: no url and no cookies")
+	}
+	return &session, nil
+}
+
+// cookieFromCDP convertit un cookie de Network.getCookies (expires -1 = cookie de session)
+func cookieFromCDP(c map[string]interface{}) Cookie {
+	cookie := Cookie{}
+	cookie.Name, _ = c["name"].(string)
+	cookie.Value, _ = c["value"].(string)
+	cookie.Domain, _ = c["domain"].(string)
+	cookie.Path, _ = c["path"].(string)
+	cookie.Secure, _ = c["secure"].(bool)
+	cookie.HTTPOnly, _ = c["httpOnly"].(bool)
+	cookie.SameSite, _ = c["sameSite"].(string)
+	if session, _ := c["session"].(bool); !session {
+		if expires, ok := c["expires"].(float64); ok && expires > 0 {
+			cookie.Expires = expires
+		}
+	}
+	return cookie
+}
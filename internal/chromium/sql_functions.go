@@ -7,6 +7,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sync"
 
 	"modernc.org/sqlite"
@@ -62,6 +63,14 @@ func init() {
 	})
 }
 
+// cdpFunctionCall repère un appel aux fonctions SQL CDP enregistrées dans init
+var cdpFunctionCall = regexp.MustCompile(`(?i)\b(cdp_call|cdp_connected|cdp_session_id|cdp_list_pages)\s*\(`)
+
+// CallsCDP indique si une requête SQL appelle une fonction CDP
+func CallsCDP(sql string) bool {
+	return cdpFunctionCall.MatchString(sql)
+}
+
 // SetCDPManager définit le CDPManager global pour les fonctions SQL
 func SetCDPManager(manager *CDPManager) {
 	globalRegistry.mu.Lock()
@@ -103,8 +112,8 @@ func ExecuteCDPCall(method string, paramsJSON string) (string, error) {
 		return "", fmt.Errorf("browser not connected: %w", err)
 	}
 
-	// Exécuter la commande CDP
-	result, err := manager.Call(method, params)
+	// Exécuter la commande CDP (bornée par le tools/call en cours, cf. BindContext)
+	result, err := manager.CallContext(manager.boundContext(), method, params)
 	if err != nil {
 		return "", fmt.Errorf("CDP call failed: %w", err)
 	}
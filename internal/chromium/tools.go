@@ -2,27 +2,47 @@
 package chromium
 
 import (
+	"context"
+	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // ToolsManager gère les tools Chromium
 type ToolsManager struct {
-	browser       *Browser
+	browser       BrowserControl
 	mu            sync.Mutex
+	launchFn      LaunchFunc  // Démarrage d'un browser (remplaçable pour les tests)
+	connectFn     ConnectFunc // Connexion à un browser existant (remplaçable pour les tests)
 	screenshotDir string
-	chromePath    string // Chemin vers Chromium (depuis Discovery)
-	userDataDir   string // Répertoire profil (depuis Discovery)
-	defaultPort   int    // Port par défaut (depuis Discovery)
+	maxFiles      int           // Nombre max de captures conservées
+	maxAge        time.Duration // Âge max des captures
+	chromePath    string        // Chemin vers Chromium (depuis Discovery)
+	userDataDir   string        // Répertoire profil (depuis Discovery)
+	defaultPort   int           // Port par défaut (depuis Discovery)
+	sessionDB     *sql.DB       // Base contenant cdp_session_state (dernier port utilisé)
+
+	// Reprise automatique si le browser meurt entre deux appels
+	autoRecover bool
+	recoverMode string                 // "launch" ou "connect"
+	recoverArgs map[string]interface{} // Arguments du dernier launch/connect
 }
 
 // ToolsConfig configuration pour ToolsManager depuis Discovery
 type ToolsConfig struct {
 	ScreenshotDir string
+	MaxFiles      int
+	MaxAge        time.Duration
 	ChromePath    string
 	UserDataDir   string
 	DefaultPort   int
@@ -45,12 +65,79 @@ func NewToolsManager(cfg *ToolsConfig) *ToolsManager {
 		defaultPort = 9222
 	}
 
+	maxFiles := cfg.MaxFiles
+	if maxFiles == 0 {
+		maxFiles = defaultScreenshotMaxFiles
+	}
+	maxAge := cfg.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultScreenshotMaxAge
+	}
+
 	return &ToolsManager{
 		screenshotDir: screenshotDir,
+		maxFiles:      maxFiles,
+		maxAge:        maxAge,
 		chromePath:    cfg.ChromePath,
 		userDataDir:   cfg.UserDataDir,
 		defaultPort:   defaultPort,
+		launchFn:      launchBrowser,
+		connectFn:     connectBrowser,
+	}
+}
+
+// SetBrowserFactory remplace le démarrage et la connexion du browser (nil = inchangé)
+// Permet de tester le dispatch des actions avec FakeBrowser
+func (m *ToolsManager) SetBrowserFactory(launch LaunchFunc, connect ConnectFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if launch != nil {
+		m.launchFn = launch
 	}
+	if connect != nil {
+		m.connectFn = connect
+	}
+}
+
+// SetBrowser remplace le browser actif (nil = aucun browser)
+func (m *ToolsManager) SetBrowser(b BrowserControl) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.browser = b
+}
+
+// SetSessionDB configure la base où lire le dernier port CDP (cdp_session_state)
+func (m *ToolsManager) SetSessionDB(db *sql.DB) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessionDB = db
+}
+
+// Politique de rétention par défaut des captures enregistrées
+const (
+	defaultScreenshotMaxFiles = 100
+	defaultScreenshotMaxAge   = 24 * time.Hour
+)
+
+// SetScreenshotPolicy configure le répertoire et la rétention des captures
+// Les valeurs vides/nulles conservent la configuration actuelle
+func (m *ToolsManager) SetScreenshotPolicy(dir string, maxFiles int, maxAge time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create screenshot dir: %w", err)
+		}
+		m.screenshotDir = dir
+	}
+	if maxFiles > 0 {
+		m.maxFiles = maxFiles
+	}
+	if maxAge > 0 {
+		m.maxAge = maxAge
+	}
+	return nil
 }
 
 // ToolDefinitions retourne la définition du tool maître browser
@@ -59,7 +146,7 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 	return []map[string]interface{}{
 		{
 			"name":        "browser",
-			"description": "Browser automation tool. Actions: launch, connect, navigate, screenshot, evaluate, click, type, wait, get_html, get_url, get_title, cookies, set_cookie, pdf, close, list_actions",
+			"description": "Browser automation tool. Actions: status, launch, connect, ensure, navigate, screenshot, evaluate, click, type, press_key, select, fill_form, wait, wait_function, wait_network_idle, get_console, get_html, get_markdown, describe, get_url, get_title, cookies, set_cookie, set_viewport, pdf, render, screenshot_all, session_save, session_restore, close, clear_screenshots, list_actions",
 			"inputSchema": map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -67,11 +154,13 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 						"type":        "string",
 						"description": "Action to perform",
 						"enum": []string{
-							"launch", "connect", "navigate", "screenshot",
-							"evaluate", "click", "type", "wait",
-							"get_html", "get_url", "get_title",
-							"cookies", "set_cookie", "pdf", "close",
-							"list_actions",
+							"status", "launch", "connect", "ensure", "navigate", "screenshot",
+							"evaluate", "click", "type", "press_key", "select", "fill_form",
+							"wait", "wait_function", "wait_network_idle",
+							"get_console", "get_html", "get_markdown", "describe", "get_url", "get_title",
+							"cookies", "set_cookie", "set_viewport", "pdf", "render", "screenshot_all",
+							"session_save", "session_restore", "close",
+							"clear_screenshots", "list_actions",
 						},
 					},
 					"url": map[string]interface{}{
@@ -80,39 +169,203 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 					},
 					"selector": map[string]interface{}{
 						"type":        "string",
-						"description": "CSS selector (for click, type, wait)",
+						"description": "CSS selector (for click, type, select, wait, get_html and get_markdown subtree, screenshot of a single element)",
 					},
 					"text": map[string]interface{}{
 						"type":        "string",
 						"description": "Text to type",
 					},
+					"press_enter": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Press Enter after typing, e.g. to submit a form (for type)",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "Key to press: Enter, Tab, Backspace, Delete, Escape, Space, ArrowUp/Down/Left/Right, Home, End, PageUp, PageDown, Insert, F1-F12 or a single character (for press_key)",
+					},
+					"modifiers": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string", "enum": []string{"Alt", "Control", "Meta", "Shift"}},
+						"description": "Modifier keys held during the key press, e.g. [\"Control\"] with key \"a\" (for press_key)",
+					},
 					"expression": map[string]interface{}{
 						"type":        "string",
-						"description": "JavaScript expression (for evaluate)",
+						"description": "JavaScript expression (for evaluate, wait_function)",
+					},
+					"await_promise": map[string]interface{}{
+						"type":        "boolean",
+						"default":     true,
+						"description": "Await promise results (for evaluate)",
 					},
 					"headless": map[string]interface{}{
 						"type":        "boolean",
 						"default":     true,
 						"description": "Headless mode (for launch)",
 					},
+					"window_size": map[string]interface{}{
+						"type":        "string",
+						"description": "Window size WIDTH,HEIGHT (for launch, default 1920,1080)",
+					},
+					"proxy": map[string]interface{}{
+						"type":        "string",
+						"description": "Proxy server scheme://host:port (for launch)",
+					},
+					"proxy_auth": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"username": map[string]interface{}{"type": "string"},
+							"password": map[string]interface{}{"type": "string"},
+						},
+						"description": "Proxy credentials answered on auth challenges (for launch)",
+					},
+					"extra_args": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Extra Chromium flags (for launch)",
+					},
+					"auto_recover": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Relaunch/reconnect automatically if the browser died (set on launch/connect, or per action)",
+					},
+					"allow_unsafe_args": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Allow security-weakening flags in extra_args (for launch)",
+					},
 					"port": map[string]interface{}{
 						"type":        "integer",
-						"default":     9222,
-						"description": "Debug port",
+						"description": "Debug port (launch: 9222 by default; connect: auto-discovered when omitted)",
 					},
 					"timeout": map[string]interface{}{
 						"type":        "integer",
 						"default":     30,
-						"description": "Timeout in seconds (for wait)",
+						"description": "Timeout in seconds (for navigate, wait, wait_function, wait_network_idle, render)",
+					},
+					"quiet_ms": map[string]interface{}{
+						"type":        "integer",
+						"default":     500,
+						"description": "Quiet period without new requests, in milliseconds (for wait_network_idle, render)",
+					},
+					"level": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"error", "warning", "log"},
+						"description": "Only return messages of this level (for get_console, default all)",
+					},
+					"clear": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Empty the console buffer after reading (for get_console)",
+					},
+					"width": map[string]interface{}{
+						"type":        "integer",
+						"description": "Viewport width in CSS pixels (for set_viewport)",
+					},
+					"height": map[string]interface{}{
+						"type":        "integer",
+						"description": "Viewport height in CSS pixels (for set_viewport)",
+					},
+					"mobile": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Emulate a mobile device: meta viewport, overlay scrollbars, touch events (for set_viewport)",
+					},
+					"user_agent": map[string]interface{}{
+						"type":        "string",
+						"description": "User agent to send and expose as navigator.userAgent (for set_viewport)",
 					},
 					"format": map[string]interface{}{
 						"type":        "string",
 						"enum":        []string{"png", "jpeg"},
-						"description": "Image format (for screenshot)",
+						"description": "Image format (for screenshot, screenshot_all)",
+					},
+					"full_page": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Capture the whole page, not just the viewport (for screenshot; render defaults to true)",
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"native", "reliable"},
+						"description": "Full-page strategy: native (captureBeyondViewport) or reliable (resize viewport to page height, then restore) (for screenshot)",
 					},
 					"path": map[string]interface{}{
 						"type":        "string",
-						"description": "Save path (for screenshot/pdf)",
+						"description": "Save path (for screenshot/pdf/render), cookie path (for set_cookie, default /)",
+					},
+					"save": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Write screenshot to disk (default: only when path is given)",
+					},
+					"html": map[string]interface{}{
+						"type":        "string",
+						"description": "HTML document to render (for render)",
+					},
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "Markdown (GFM) converted to a styled HTML document (for render, instead of html)",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Document title for markdown (for render)",
+					},
+					"output": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"pdf", "png", "jpeg"},
+						"default":     "pdf",
+						"description": "Rendered document format (for render)",
+					},
+					"landscape": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Landscape orientation (for pdf, render)",
+					},
+					"paper_width": map[string]interface{}{
+						"type":        "number",
+						"description": "Paper width in inches, e.g. 8.27 for A4 (for pdf, render; default 8.5)",
+					},
+					"paper_height": map[string]interface{}{
+						"type":        "number",
+						"description": "Paper height in inches, e.g. 11.69 for A4 (for pdf, render; default 11)",
+					},
+					"margin_top": map[string]interface{}{
+						"type":        "number",
+						"description": "Top margin in inches (for pdf, render; default ~0.4)",
+					},
+					"margin_bottom": map[string]interface{}{
+						"type":        "number",
+						"description": "Bottom margin in inches (for pdf, render; default ~0.4)",
+					},
+					"margin_left": map[string]interface{}{
+						"type":        "number",
+						"description": "Left margin in inches (for pdf, render; default ~0.4)",
+					},
+					"margin_right": map[string]interface{}{
+						"type":        "number",
+						"description": "Right margin in inches (for pdf, render; default ~0.4)",
+					},
+					"scale": map[string]interface{}{
+						"type":        "number",
+						"default":     1,
+						"description": "Rendering scale, 0.1 to 2 (for pdf, render); device pixel ratio (for set_viewport, default 1)",
+					},
+					"page_ranges": map[string]interface{}{
+						"type":        "string",
+						"description": "Pages to print, e.g. \"1-5, 8\" (for pdf, render; default all)",
+					},
+					"display_header_footer": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Print header_template and footer_template on each page (for pdf, render)",
+					},
+					"header_template": map[string]interface{}{
+						"type":        "string",
+						"description": "Header HTML; elements with class date, title, url, pageNumber or totalPages are filled in (for pdf, render)",
+					},
+					"footer_template": map[string]interface{}{
+						"type":        "string",
+						"description": "Footer HTML, same classes as header_template (for pdf, render)",
 					},
 					"name": map[string]interface{}{
 						"type":        "string",
@@ -120,12 +373,67 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 					},
 					"value": map[string]interface{}{
 						"type":        "string",
-						"description": "Cookie value (for set_cookie)",
+						"description": "Cookie value (for set_cookie); option value, or text, to choose (for select)",
+					},
+					"fields": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": []string{"string", "number", "boolean"}},
+						"description":          "CSS selector -> value to fill; checkboxes take true/false, selects an option value or text (for fill_form)",
 					},
 					"domain": map[string]interface{}{
 						"type":        "string",
 						"description": "Cookie domain (for set_cookie)",
 					},
+					"secure": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "HTTPS-only cookie (for set_cookie, required with same_site None)",
+					},
+					"http_only": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Cookie hidden from JavaScript (for set_cookie)",
+					},
+					"same_site": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"Strict", "Lax", "None"},
+						"description": "SameSite policy (for set_cookie)",
+					},
+					"expires": map[string]interface{}{
+						"type":        "number",
+						"description": "Expiry as Unix timestamp in seconds (for set_cookie, session cookie if omitted)",
+					},
+					"max_age": map[string]interface{}{
+						"type":        "integer",
+						"description": "Lifetime in seconds from now (for set_cookie, instead of expires)",
+					},
+					"frame": map[string]interface{}{
+						"type":        "string",
+						"description": "Target iframe by frame id, name or URL fragment; runs in an isolated world of that frame (for evaluate, click, type, select, fill_form)",
+					},
+					"session": map[string]interface{}{
+						"type":        "object",
+						"description": "Session returned by session_save, as an object or a JSON string (for session_restore)",
+					},
+					"max_elements": map[string]interface{}{
+						"type":        "integer",
+						"default":     100,
+						"description": "Max interactive elements returned (for describe, max 500)",
+					},
+					"max_bytes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Max bytes of HTML or markdown returned (for get_html, get_markdown; truncated beyond)",
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"default":     0,
+						"description": "Byte offset to start from (for get_html, get_markdown paging, use next_offset)",
+					},
+					"full": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Convert the whole body instead of the detected main content (for get_markdown)",
+					},
 				},
 				"required": []string{"action"},
 			},
@@ -135,6 +443,11 @@ func (m *ToolsManager) ToolDefinitions() []map[string]interface{} {
 
 // Execute exécute le tool maître browser avec dispatch sur action
 func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (interface{}, error) {
+	return m.ExecuteContext(context.Background(), toolName, args)
+}
+
+// ExecuteContext exécute le tool browser; les appels CDP sont interrompus quand ctx expire
+func (m *ToolsManager) ExecuteContext(ctx context.Context, toolName string, args map[string]interface{}) (interface{}, error) {
 	if toolName != "browser" {
 		return nil, fmt.Errorf("unknown tool: %s (expected 'browser')", toolName)
 	}
@@ -147,11 +460,28 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !livenessExempt[action] {
+		if err := m.ensureAlive(args); err != nil {
+			return nil, err
+		}
+	}
+	if m.browser != nil {
+		m.browser.SetOperationContext(ctx)
+		defer func(b BrowserControl) { b.SetOperationContext(nil) }(m.browser)
+	}
+
 	switch action {
+	case "status":
+		return m.status()
 	case "launch":
 		return m.launch(args)
 	case "connect":
 		return m.connect(args)
+	case "ensure":
+		return m.ensure(args)
 	case "navigate":
 		return m.navigate(args)
 	case "screenshot":
@@ -162,10 +492,26 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 		return m.click(args)
 	case "type":
 		return m.typeText(args)
+	case "press_key":
+		return m.pressKey(args)
+	case "select":
+		return m.selectOption(args)
+	case "fill_form":
+		return m.fillForm(args)
 	case "wait":
 		return m.wait(args)
+	case "wait_function":
+		return m.waitFunction(args)
+	case "wait_network_idle":
+		return m.waitNetworkIdle(args)
+	case "get_console":
+		return m.getConsole(args)
 	case "get_html":
-		return m.getHTML()
+		return m.getHTML(args)
+	case "get_markdown":
+		return m.getMarkdown(args)
+	case "describe":
+		return m.describe(args)
 	case "get_url":
 		return m.getURL()
 	case "get_title":
@@ -174,10 +520,22 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 		return m.getCookies()
 	case "set_cookie":
 		return m.setCookie(args)
+	case "set_viewport":
+		return m.setViewport(args)
 	case "pdf":
 		return m.pdf(args)
+	case "render":
+		return m.render(args)
+	case "screenshot_all":
+		return m.screenshotAll(args)
+	case "session_save":
+		return m.sessionSave()
+	case "session_restore":
+		return m.sessionRestore(args)
 	case "close":
 		return m.close()
+	case "clear_screenshots":
+		return m.clearScreenshots()
 	case "list_actions":
 		return m.listActions()
 	default:
@@ -189,26 +547,92 @@ func (m *ToolsManager) Execute(toolName string, args map[string]interface{}) (in
 func (m *ToolsManager) listActions() (interface{}, error) {
 	return map[string]interface{}{
 		"actions": []map[string]interface{}{
-			{"name": "launch", "description": "Launch new browser instance", "params": []string{"headless", "port"}},
-			{"name": "connect", "description": "Connect to existing browser", "params": []string{"port"}},
-			{"name": "navigate", "description": "Navigate to URL", "params": []string{"url"}},
-			{"name": "screenshot", "description": "Take screenshot", "params": []string{"format", "path"}},
-			{"name": "evaluate", "description": "Execute JavaScript", "params": []string{"expression"}},
-			{"name": "click", "description": "Click element", "params": []string{"selector"}},
-			{"name": "type", "description": "Type text into element", "params": []string{"selector", "text"}},
+			{"name": "launch", "description": "Launch new browser instance", "params": []string{"headless", "port", "window_size", "proxy", "proxy_auth", "extra_args", "allow_unsafe_args", "auto_recover"}},
+			{"name": "connect", "description": "Connect to existing browser", "params": []string{"port", "auto_recover"}},
+			{"name": "ensure", "description": "Reuse the active browser, else connect to a running one, else launch", "params": []string{"port", "headless", "window_size", "proxy", "proxy_auth", "extra_args", "allow_unsafe_args", "auto_recover"}},
+			{"name": "navigate", "description": "Navigate to URL and wait for the page load event (returns final url and loaded)", "params": []string{"url", "timeout"}},
+			{"name": "screenshot", "description": "Take screenshot of the viewport, the full page or one element (returned inline, saved only with path/save)", "params": []string{"format", "full_page", "mode", "selector", "path", "save"}},
+			{"name": "evaluate", "description": "Execute JavaScript (awaits promises), optionally inside an iframe", "params": []string{"expression", "await_promise", "frame"}},
+			{"name": "click", "description": "Click element, optionally inside an iframe", "params": []string{"selector", "frame"}},
+			{"name": "type", "description": "Type text into element, optionally inside an iframe, then optionally press Enter", "params": []string{"selector", "text", "frame", "press_enter"}},
+			{"name": "press_key", "description": "Press and release a key (Enter, Tab, arrows, F1-F12, a character) on the focused element, with optional modifiers for shortcuts", "params": []string{"key", "modifiers"}},
+			{"name": "select", "description": "Choose a <select> option by value or text and fire input/change, optionally inside an iframe", "params": []string{"selector", "value", "frame"}},
+			{"name": "fill_form", "description": "Fill several fields (inputs, textareas, selects, checkboxes) in one call; reports success or error per field", "params": []string{"fields", "frame"}},
 			{"name": "wait", "description": "Wait for element", "params": []string{"selector", "timeout"}},
-			{"name": "get_html", "description": "Get page HTML", "params": []string{}},
+			{"name": "wait_function", "description": "Wait until a JavaScript expression is truthy", "params": []string{"expression", "timeout"}},
+			{"name": "wait_network_idle", "description": "Wait until no new network request is sent for quiet_ms", "params": []string{"quiet_ms", "timeout"}},
+			{"name": "get_console", "description": "Get captured console messages, uncaught exceptions and browser log entries (capture starts on first call; messages already logged are replayed)", "params": []string{"level", "clear"}},
+			{"name": "get_html", "description": "Get page HTML or a subtree, paged by bytes", "params": []string{"selector", "max_bytes", "offset"}},
+			{"name": "get_markdown", "description": "Get the page's main content (or a subtree) as markdown, without scripts, styles, navigation and forms; paged by bytes", "params": []string{"selector", "full", "max_bytes", "offset"}},
+			{"name": "describe", "description": "List interactive elements from accessibility tree", "params": []string{"max_elements"}},
+			{"name": "status", "description": "Report whether a browser is active, its port, URL, title and page count", "params": []string{}},
 			{"name": "get_url", "description": "Get current URL", "params": []string{}},
 			{"name": "get_title", "description": "Get page title", "params": []string{}},
 			{"name": "cookies", "description": "Get all cookies", "params": []string{}},
-			{"name": "set_cookie", "description": "Set a cookie", "params": []string{"name", "value", "domain"}},
-			{"name": "pdf", "description": "Generate PDF", "params": []string{"path"}},
+			{"name": "set_cookie", "description": "Set a cookie", "params": []string{"name", "value", "domain", "path", "secure", "http_only", "same_site", "expires", "max_age"}},
+			{"name": "set_viewport", "description": "Emulate a device viewport (and optionally a user agent) until the browser closes; later screenshots use it", "params": []string{"width", "height", "scale", "mobile", "user_agent"}},
+			{"name": "pdf", "description": "Generate PDF (paper size and margins in inches)", "params": []string{"path", "landscape", "paper_width", "paper_height", "margin_top", "margin_bottom", "margin_left", "margin_right", "scale", "page_ranges", "display_header_footer", "header_template", "footer_template"}},
+			{"name": "render", "description": "Render an HTML or markdown string to PDF or image in a scratch tab (the current page is left untouched)", "params": []string{"html", "markdown", "title", "output", "path", "save", "full_page", "mode", "quiet_ms", "timeout", "landscape", "paper_width", "paper_height", "margin_top", "margin_bottom", "margin_left", "margin_right", "scale", "page_ranges", "display_header_footer", "header_template", "footer_template"}},
+			{"name": "screenshot_all", "description": "Screenshot every open tab (returned inline with targetId, url, title), then restore the active tab", "params": []string{"format"}},
+			{"name": "session_save", "description": "Save cookies, localStorage of the current origin and URL as a session object", "params": []string{}},
+			{"name": "session_restore", "description": "Restore a saved session: set its cookies, then navigate to its URL with localStorage prefilled", "params": []string{"session"}},
 			{"name": "close", "description": "Close browser", "params": []string{}},
+			{"name": "clear_screenshots", "description": "Delete saved screenshots from the screenshot dir", "params": []string{}},
 		},
-		"total": 15,
+		"total": 31,
 	}, nil
 }
 
+// livenessExempt actions qui ne vérifient pas la santé du browser avant exécution
+var livenessExempt = map[string]bool{
+	"launch":            true,
+	"connect":           true,
+	"ensure":            true,
+	"status":            true,
+	"close":             true,
+	"clear_screenshots": true,
+	"list_actions":      true,
+}
+
+// ensureAlive vérifie que le browser répond toujours avant une action
+// S'il est mort, il est relancé/reconnecté avec auto_recover, sinon une erreur explicite est retournée
+func (m *ToolsManager) ensureAlive(args map[string]interface{}) error {
+	if m.browser == nil || m.browser.Alive() {
+		return nil
+	}
+
+	m.browser.Close()
+	m.browser = nil
+
+	autoRecover := m.autoRecover
+	if v, ok := args["auto_recover"].(bool); ok {
+		autoRecover = v
+	}
+	if !autoRecover || m.recoverArgs == nil {
+		return fmt.Errorf("browser is no longer responding (crashed or closed): use launch or connect, or set auto_recover")
+	}
+
+	var err error
+	if m.recoverMode == "connect" {
+		_, err = m.connect(m.recoverArgs)
+	} else {
+		_, err = m.launch(m.recoverArgs)
+	}
+	if err != nil {
+		return fmt.Errorf("auto_recover failed: %w", err)
+	}
+	return nil
+}
+
+// rememberSession conserve les arguments de launch/connect pour auto_recover
+func (m *ToolsManager) rememberSession(mode string, args map[string]interface{}) {
+	m.recoverMode = mode
+	m.recoverArgs = args
+	if v, ok := args["auto_recover"].(bool); ok {
+		m.autoRecover = v
+	}
+}
+
 func (m *ToolsManager) launch(args map[string]interface{}) (interface{}, error) {
 	if m.browser != nil {
 		m.browser.Close()
@@ -228,13 +652,53 @@ func (m *ToolsManager) launch(args map[string]interface{}) (interface{}, error)
 	if port, ok := args["port"].(float64); ok {
 		cfg.DebugPort = int(port)
 	}
+	if ws, ok := args["window_size"].(string); ok && ws != "" {
+		if !windowSizeRegex.MatchString(ws) {
+			return nil, fmt.Errorf("invalid window_size: %s (expected WIDTH,HEIGHT)", ws)
+		}
+		cfg.WindowSize = ws
+	}
+	if proxy, ok := args["proxy"].(string); ok && proxy != "" {
+		if err := validateProxy(proxy); err != nil {
+			return nil, err
+		}
+		cfg.Proxy = proxy
+	}
+	if rawArgs, ok := args["extra_args"].([]interface{}); ok {
+		allowUnsafe, _ := args["allow_unsafe_args"].(bool)
+		extra, err := validateExtraArgs(rawArgs, allowUnsafe)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ExtraArgs = extra
+	}
+
+	var proxyUser, proxyPass string
+	if auth, ok := args["proxy_auth"].(map[string]interface{}); ok {
+		if cfg.Proxy == "" {
+			return nil, fmt.Errorf("proxy_auth requires proxy")
+		}
+		proxyUser, _ = auth["username"].(string)
+		proxyPass, _ = auth["password"].(string)
+		if proxyUser == "" {
+			return nil, fmt.Errorf("proxy_auth.username is required")
+		}
+	}
 
-	browser, err := Launch(cfg)
+	browser, err := m.launchFn(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	if proxyUser != "" {
+		if err := browser.EnableProxyAuth(proxyUser, proxyPass); err != nil {
+			browser.Close()
+			return nil, err
+		}
+	}
+
 	m.browser = browser
+	m.rememberSession("launch", args)
 
 	return map[string]interface{}{
 		"success":    true,
@@ -242,33 +706,245 @@ func (m *ToolsManager) launch(args map[string]interface{}) (interface{}, error)
 		"port":       cfg.DebugPort,
 		"headless":   cfg.Headless,
 		"chromePath": cfg.ChromePath,
+		"windowSize": cfg.WindowSize,
+		"proxy":      cfg.Proxy,
+		"proxyAuth":  proxyUser != "",
+		"extraArgs":  cfg.ExtraArgs,
 	}, nil
 }
 
+// windowSizeRegex valide le format WIDTH,HEIGHT
+var windowSizeRegex = regexp.MustCompile(`^\d{2,5},\d{2,5}$`)
+
+// forbiddenLaunchArgs flags gérés par HOLOW, jamais surchargeables
+var forbiddenLaunchArgs = []string{
+	"--remote-debugging-port",
+	"--remote-debugging-address",
+	"--remote-debugging-pipe",
+	"--user-data-dir",
+	"--proxy-server", // Utiliser le paramètre proxy
+}
+
+// unsafeLaunchArgs flags affaiblissant la sécurité, acceptés seulement avec allow_unsafe_args
+var unsafeLaunchArgs = []string{
+	"--disable-web-security",
+	"--allow-running-insecure-content",
+	"--ignore-certificate-errors",
+	"--disable-site-isolation-trials",
+	"--remote-allow-origins",
+	"--no-sandbox",
+	"--load-extension",
+	"--user-agent",
+}
+
+// flagName extrait le nom d'un flag Chromium (sans valeur)
+func flagName(arg string) string {
+	if idx := strings.Index(arg, "="); idx != -1 {
+		return arg[:idx]
+	}
+	return arg
+}
+
+// validateExtraArgs vérifie les arguments Chromium supplémentaires
+func validateExtraArgs(rawArgs []interface{}, allowUnsafe bool) ([]string, error) {
+	extra := make([]string, 0, len(rawArgs))
+	for _, raw := range rawArgs {
+		arg, ok := raw.(string)
+		if !ok || !strings.HasPrefix(arg, "--") {
+			return nil, fmt.Errorf("invalid extra_args entry: %v (expected --flag or --flag=value)", raw)
+		}
+		name := flagName(arg)
+		for _, forbidden := range forbiddenLaunchArgs {
+			if name == forbidden {
+				return nil, fmt.Errorf("extra_args: %s is managed by the server and cannot be overridden", name)
+			}
+		}
+		if !allowUnsafe {
+			for _, unsafe := range unsafeLaunchArgs {
+				if name == unsafe {
+					return nil, fmt.Errorf("extra_args: %s weakens browser security, set allow_unsafe_args: true to use it", name)
+				}
+			}
+		}
+		extra = append(extra, arg)
+	}
+	return extra, nil
+}
+
+// validateProxy vérifie le format d'une adresse de proxy
+func validateProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid proxy: %s (expected scheme://host:port)", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks4", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy scheme: %s (expected http, https, socks4 or socks5)", u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("proxy credentials must not be embedded in the proxy URL")
+	}
+	return nil
+}
+
+func (m *ToolsManager) status() (interface{}, error) {
+	if m.browser == nil {
+		return map[string]interface{}{
+			"success": true,
+			"active":  false,
+			"message": "No browser running, use launch or connect",
+		}, nil
+	}
+
+	mode := "connected"
+	if m.browser.Launched() {
+		mode = "launched"
+	}
+
+	targets, err := m.browser.GetTargets()
+	if err != nil {
+		// Connexion CDP perdue: le browser n'est plus utilisable
+		return map[string]interface{}{
+			"success":    true,
+			"active":     false,
+			"mode":       mode,
+			"debug_port": m.browser.DebugPort(),
+			"error":      err.Error(),
+			"message":    "Browser not responding, use launch or connect",
+		}, nil
+	}
+
+	pages := 0
+	for _, t := range targets {
+		if t.Type == "page" {
+			pages++
+		}
+	}
+
+	result := map[string]interface{}{
+		"success":      true,
+		"active":       true,
+		"mode":         mode,
+		"debug_port":   m.browser.DebugPort(),
+		"page_count":   pages,
+		"auto_recover": m.autoRecover,
+	}
+	if url, err := m.browser.GetURL(); err == nil {
+		result["url"] = url
+	}
+	if title, err := m.browser.GetTitle(); err == nil {
+		result["title"] = title
+	}
+	return result, nil
+}
+
 func (m *ToolsManager) connect(args map[string]interface{}) (interface{}, error) {
 	if m.browser != nil {
 		m.browser.Close()
 	}
 
-	port := 9222
+	// Sans port explicite: découvrir un débogueur actif
+	discovered := false
+	var port int
 	if p, ok := args["port"].(float64); ok {
 		port = int(p)
+	} else {
+		found, tried, err := m.discoverDebugPort()
+		if err != nil {
+			return nil, fmt.Errorf("%w (ports tried: %v)", err, tried)
+		}
+		port = found
+		discovered = true
 	}
 
-	browser, err := Connect(port)
+	browser, err := m.connectFn(port)
 	if err != nil {
 		return nil, err
 	}
 
 	m.browser = browser
 
+	// Mémoriser le port effectif pour qu'une reprise vise le même browser
+	session := make(map[string]interface{}, len(args)+1)
+	for k, v := range args {
+		session[k] = v
+	}
+	session["port"] = float64(port)
+	m.rememberSession("connect", session)
+
 	return map[string]interface{}{
-		"success": true,
-		"message": "Connected to browser",
-		"port":    port,
+		"success":    true,
+		"message":    "Connected to browser",
+		"port":       port,
+		"discovered": discovered,
 	}, nil
 }
 
+// ensure fournit un browser utilisable: l'actif s'il répond, sinon connect (port fourni
+// ou découvert), sinon launch; "path" indique la voie retenue (existing, connect, launch)
+func (m *ToolsManager) ensure(args map[string]interface{}) (interface{}, error) {
+	if m.browser != nil && m.browser.Alive() {
+		if v, ok := args["auto_recover"].(bool); ok {
+			m.autoRecover = v
+		}
+		return map[string]interface{}{
+			"success": true,
+			"message": "Browser already active",
+			"path":    "existing",
+			"port":    m.browser.DebugPort(),
+		}, nil
+	}
+
+	connected, connectErr := m.connect(args)
+	if connectErr == nil {
+		result := connected.(map[string]interface{})
+		result["path"] = "connect"
+		return result, nil
+	}
+
+	launched, err := m.launch(args)
+	if err != nil {
+		return nil, fmt.Errorf("connect failed (%v), launch failed: %w", connectErr, err)
+	}
+	result := launched.(map[string]interface{})
+	result["path"] = "launch"
+	result["connect_error"] = connectErr.Error()
+	return result, nil
+}
+
+// commonDebugPorts ports de débogage usuels sondés par connect sans port
+var commonDebugPorts = []int{9222, 9223, 9224, 9225, 9229, 9333}
+
+// discoverDebugPort sonde le dernier port utilisé (cdp_session_state), le port
+// de Discovery puis les ports usuels, et retourne le premier qui répond sur /json/version
+func (m *ToolsManager) discoverDebugPort() (int, []int, error) {
+	candidates := []int{}
+	if m.sessionDB != nil {
+		var lastPort sql.NullInt64
+		m.sessionDB.QueryRow(`SELECT debug_port FROM cdp_session_state WHERE id = 1`).Scan(&lastPort)
+		if lastPort.Valid && lastPort.Int64 > 0 {
+			candidates = append(candidates, int(lastPort.Int64))
+		}
+	}
+	candidates = append(candidates, m.defaultPort)
+	candidates = append(candidates, commonDebugPorts...)
+
+	seen := make(map[int]bool, len(candidates))
+	tried := []int{}
+	for _, port := range candidates {
+		if seen[port] {
+			continue
+		}
+		seen[port] = true
+		tried = append(tried, port)
+		if probeDebugPort(port) {
+			return port, tried, nil
+		}
+	}
+	return 0, tried, fmt.Errorf("no running browser with remote debugging found")
+}
+
 func (m *ToolsManager) navigate(args map[string]interface{}) (interface{}, error) {
 	if m.browser == nil {
 		return nil, fmt.Errorf("browser not started - use action 'launch' first")
@@ -279,18 +955,33 @@ func (m *ToolsManager) navigate(args map[string]interface{}) (interface{}, error
 		return nil, fmt.Errorf("url is required for navigate")
 	}
 
-	if err := m.browser.Navigate(url); err != nil {
+	timeout := defaultNavigateTimeout
+	if t, ok := args["timeout"].(float64); ok && t > 0 {
+		timeout = time.Duration(t) * time.Second
+	}
+
+	loaded, err := m.browser.NavigateWithTimeout(url, timeout)
+	if err != nil {
 		return nil, err
 	}
 
-	time.Sleep(500 * time.Millisecond)
+	// URL finale après redirections
+	finalURL, err := m.browser.GetURL()
+	if err != nil {
+		finalURL = url
+	}
 	title, _ := m.browser.GetTitle()
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"success": true,
-		"url":     url,
+		"url":     finalURL,
 		"title":   title,
-	}, nil
+		"loaded":  loaded,
+	}
+	if finalURL != url {
+		result["requested_url"] = url
+	}
+	return result, nil
 }
 
 func (m *ToolsManager) screenshot(args map[string]interface{}) (interface{}, error) {
@@ -304,32 +995,194 @@ func (m *ToolsManager) screenshot(args map[string]interface{}) (interface{}, err
 	}
 
 	fullPage := false
-	if fp, ok := args["fullPage"].(bool); ok {
+	if fp, ok := args["full_page"].(bool); ok {
 		fullPage = fp
 	}
+	mode, _ := args["mode"].(string)
+	if mode != "" && mode != "native" && mode != "reliable" {
+		return nil, fmt.Errorf("invalid mode: %s (expected native or reliable)", mode)
+	}
+
+	selector, _ := args["selector"].(string)
+	if selector != "" && fullPage {
+		return nil, fmt.Errorf("selector cannot be combined with full_page")
+	}
 
-	data, err := m.browser.Screenshot(format, 80, fullPage)
+	var data []byte
+	var err error
+	height := 0
+	if selector != "" {
+		data, err = m.browser.ScreenshotElement(selector, format, 80)
+	} else if fullPage && mode == "reliable" {
+		data, height, err = m.browser.ScreenshotFullPage(format, 80)
+	} else {
+		data, err = m.browser.Screenshot(format, 80, fullPage)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	savePath := ""
-	if sp, ok := args["path"].(string); ok && sp != "" {
-		savePath = sp
-	} else {
-		savePath = filepath.Join(m.screenshotDir, fmt.Sprintf("screenshot_%d.%s", time.Now().Unix(), format))
+	result := map[string]interface{}{
+		"success":  true,
+		"format":   format,
+		"size":     len(data),
+		"mimeType": "image/" + format,
+		"base64":   base64.StdEncoding.EncodeToString(data),
+	}
+	if selector != "" {
+		result["selector"] = selector
+	}
+	if height > 0 {
+		result["mode"] = "reliable"
+		result["height"] = height
+		result["truncated"] = height >= maxFullPageHeight
+	}
+
+	// L'écriture sur disque est opt-in: implicite si path est fourni,
+	// sinon uniquement avec save: true (dans le répertoire de captures)
+	savePath, _ := args["path"].(string)
+	save := savePath != ""
+	if sv, ok := args["save"].(bool); ok {
+		save = sv
+	}
+	if !save {
+		return result, nil
 	}
 
+	if savePath == "" {
+		savePath = filepath.Join(m.screenshotDir, fmt.Sprintf("screenshot_%d.%s", time.Now().UnixNano(), format))
+	}
 	if err := os.WriteFile(savePath, data, 0644); err != nil {
 		return nil, err
 	}
+	result["path"] = savePath
+
+	m.pruneScreenshots()
+
+	return result, nil
+}
+
+// screenshotAll capture tous les onglets ouverts (retournés en base64, jamais écrits sur disque)
+func (m *ToolsManager) screenshotAll(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	format := "png"
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = f
+	}
+	if format != "png" && format != "jpeg" {
+		return nil, fmt.Errorf("invalid format: %s (expected png or jpeg)", format)
+	}
+
+	shots, err := m.browser.ScreenshotAll(format, 80)
+	if err != nil {
+		return nil, err
+	}
+
+	tabs := make([]map[string]interface{}, 0, len(shots))
+	captured := 0
+	for _, shot := range shots {
+		tab := map[string]interface{}{
+			"targetId": shot.TargetID,
+			"url":      shot.URL,
+			"title":    shot.Title,
+		}
+		if shot.Err != nil {
+			tab["error"] = shot.Err.Error()
+		} else {
+			tab["mimeType"] = "image/" + format
+			tab["size"] = len(shot.Data)
+			tab["base64"] = base64.StdEncoding.EncodeToString(shot.Data)
+			captured++
+		}
+		tabs = append(tabs, tab)
+	}
+
+	return map[string]interface{}{
+		"success":  true,
+		"format":   format,
+		"count":    len(tabs),
+		"captured": captured,
+		"tabs":     tabs,
+	}, nil
+}
+
+// listScreenshots retourne les captures du répertoire, des plus anciennes aux plus récentes
+func (m *ToolsManager) listScreenshots() ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(m.screenshotDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "screenshot_") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	return files, nil
+}
+
+// pruneScreenshots applique la politique de rétention (âge puis nombre max)
+// Seules les captures auto-nommées du répertoire de captures sont concernées
+func (m *ToolsManager) pruneScreenshots() int {
+	files, err := m.listScreenshots()
+	if err != nil {
+		return 0
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-m.maxAge)
+	kept := files[:0]
+	for _, f := range files {
+		if m.maxAge > 0 && f.ModTime().Before(cutoff) {
+			if os.Remove(filepath.Join(m.screenshotDir, f.Name())) == nil {
+				removed++
+			}
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if m.maxFiles > 0 && len(kept) > m.maxFiles {
+		for _, f := range kept[:len(kept)-m.maxFiles] {
+			if os.Remove(filepath.Join(m.screenshotDir, f.Name())) == nil {
+				removed++
+			}
+		}
+	}
+
+	return removed
+}
+
+func (m *ToolsManager) clearScreenshots() (interface{}, error) {
+	files, err := m.listScreenshots()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list screenshots: %w", err)
+	}
+
+	removed := 0
+	for _, f := range files {
+		if os.Remove(filepath.Join(m.screenshotDir, f.Name())) == nil {
+			removed++
+		}
+	}
 
 	return map[string]interface{}{
 		"success": true,
-		"path":    savePath,
-		"format":  format,
-		"size":    len(data),
-		"base64":  base64.StdEncoding.EncodeToString(data),
+		"removed": removed,
+		"dir":     m.screenshotDir,
 	}, nil
 }
 
@@ -343,15 +1196,26 @@ func (m *ToolsManager) evaluate(args map[string]interface{}) (interface{}, error
 		return nil, fmt.Errorf("expression is required for evaluate")
 	}
 
-	result, err := m.browser.Evaluate(expr)
+	awaitPromise := true
+	if ap, ok := args["await_promise"].(bool); ok {
+		awaitPromise = ap
+	}
+
+	frame, leave, err := m.enterFrame(args)
 	if err != nil {
 		return nil, err
 	}
+	defer leave()
 
-	return map[string]interface{}{
+	result, err := m.browser.EvaluateWithOptions(expr, awaitPromise)
+	if err != nil {
+		return nil, err
+	}
+
+	return withFrame(map[string]interface{}{
 		"success": true,
 		"result":  result,
-	}, nil
+	}, frame), nil
 }
 
 func (m *ToolsManager) click(args map[string]interface{}) (interface{}, error) {
@@ -364,14 +1228,20 @@ func (m *ToolsManager) click(args map[string]interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("selector is required for click")
 	}
 
+	frame, leave, err := m.enterFrame(args)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
 	if err := m.browser.Click(selector); err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
+	return withFrame(map[string]interface{}{
 		"success":  true,
 		"selector": selector,
-	}, nil
+	}, frame), nil
 }
 
 func (m *ToolsManager) typeText(args map[string]interface{}) (interface{}, error) {
@@ -389,15 +1259,162 @@ func (m *ToolsManager) typeText(args map[string]interface{}) (interface{}, error
 		return nil, fmt.Errorf("text is required for type")
 	}
 
+	frame, leave, err := m.enterFrame(args)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
 	if err := m.browser.Type(selector, text); err != nil {
 		return nil, err
 	}
+	pressEnter, _ := args["press_enter"].(bool)
+	if pressEnter {
+		if err := m.browser.PressKey("Enter", nil); err != nil {
+			return nil, err
+		}
+	}
 
-	return map[string]interface{}{
+	return withFrame(map[string]interface{}{
+		"success":     true,
+		"selector":    selector,
+		"length":      len(text),
+		"press_enter": pressEnter,
+	}, frame), nil
+}
+
+func (m *ToolsManager) selectOption(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	selector, ok := args["selector"].(string)
+	if !ok {
+		return nil, fmt.Errorf("selector is required for select")
+	}
+	value, ok := args["value"].(string)
+	if !ok {
+		return nil, fmt.Errorf("value is required for select")
+	}
+
+	frame, leave, err := m.enterFrame(args)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	if err := m.browser.SelectOption(selector, value); err != nil {
+		return nil, err
+	}
+
+	return withFrame(map[string]interface{}{
 		"success":  true,
 		"selector": selector,
-		"length":   len(text),
-	}, nil
+		"value":    value,
+	}, frame), nil
+}
+
+func (m *ToolsManager) fillForm(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	raw, ok := args["fields"].(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, fmt.Errorf("fields is required for fill_form (object mapping CSS selectors to values)")
+	}
+	fields := make(map[string]string, len(raw))
+	for selector, v := range raw {
+		switch value := v.(type) {
+		case string:
+			fields[selector] = value
+		case bool:
+			fields[selector] = strconv.FormatBool(value)
+		case float64:
+			fields[selector] = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("invalid value for %s: expected string, number or boolean", selector)
+		}
+	}
+
+	frame, leave, err := m.enterFrame(args)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	results := m.browser.FillForm(fields)
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+	}
+
+	// Remplissage partiel: pas d'erreur, le détail par champ permet de corriger les sélecteurs fautifs
+	return withFrame(map[string]interface{}{
+		"success": failed == 0,
+		"filled":  len(results) - failed,
+		"failed":  failed,
+		"fields":  results,
+	}, frame), nil
+}
+
+func (m *ToolsManager) pressKey(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	key, ok := args["key"].(string)
+	if !ok || key == "" {
+		return nil, fmt.Errorf("key is required for press_key")
+	}
+	var modifiers []string
+	if list, ok := args["modifiers"].([]interface{}); ok {
+		for _, v := range list {
+			mod, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("modifiers must be strings")
+			}
+			modifiers = append(modifiers, mod)
+		}
+	}
+
+	if err := m.browser.PressKey(key, modifiers); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"success": true,
+		"key":     key,
+	}
+	if len(modifiers) > 0 {
+		result["modifiers"] = modifiers
+	}
+	return result, nil
+}
+
+// enterFrame cible le frame de l'argument frame (id, nom ou fragment d'URL) jusqu'à l'appel de leave
+// À appeler sous m.mu (ExecuteContext), tenu jusqu'à leave: une autre requête ne peut pas évaluer
+// dans ce frame ni changer de frame entre-temps
+func (m *ToolsManager) enterFrame(args map[string]interface{}) (*FrameInfo, func(), error) {
+	name, _ := args["frame"].(string)
+	if name == "" {
+		return nil, func() {}, nil
+	}
+	frame, err := m.browser.SetFrame(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &frame, func() { m.browser.SetFrame("") }, nil
+}
+
+// withFrame ajoute au résultat le frame ciblé, s'il y en a un
+func withFrame(result map[string]interface{}, frame *FrameInfo) map[string]interface{} {
+	if frame != nil {
+		result["frame"] = frame
+	}
+	return result
 }
 
 func (m *ToolsManager) wait(args map[string]interface{}) (interface{}, error) {
@@ -425,20 +1442,270 @@ func (m *ToolsManager) wait(args map[string]interface{}) (interface{}, error) {
 	}, nil
 }
 
-func (m *ToolsManager) getHTML() (interface{}, error) {
+func (m *ToolsManager) waitFunction(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	expression, ok := args["expression"].(string)
+	if !ok || expression == "" {
+		return nil, fmt.Errorf("expression is required for wait_function")
+	}
+
+	timeout := 30 * time.Second
+	if t, ok := args["timeout"].(float64); ok && t > 0 {
+		timeout = time.Duration(t) * time.Second
+	}
+
+	start := time.Now()
+	if err := m.browser.WaitForFunction(expression, timeout); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success":    true,
+		"expression": expression,
+		"elapsed_ms": time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// defaultNetworkQuiet période sans requête considérée comme réseau au repos
+const defaultNetworkQuiet = 500 * time.Millisecond
+
+func (m *ToolsManager) waitNetworkIdle(args map[string]interface{}) (interface{}, error) {
 	if m.browser == nil {
 		return nil, fmt.Errorf("browser not started")
 	}
 
-	html, err := m.browser.GetHTML()
+	quiet := defaultNetworkQuiet
+	if q, ok := args["quiet_ms"].(float64); ok && q > 0 {
+		quiet = time.Duration(q) * time.Millisecond
+	}
+	timeout := 30 * time.Second
+	if t, ok := args["timeout"].(float64); ok && t > 0 {
+		timeout = time.Duration(t) * time.Second
+	}
+
+	start := time.Now()
+	requests, err := m.browser.WaitNetworkIdle(quiet, timeout)
 	if err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
+		"success":    true,
+		"quiet_ms":   quiet.Milliseconds(),
+		"requests":   requests,
+		"elapsed_ms": time.Since(start).Milliseconds(),
+	}, nil
+}
+
+func (m *ToolsManager) getConsole(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	level, _ := args["level"].(string)
+	switch level {
+	case "", "error", "warning", "log":
+	default:
+		return nil, fmt.Errorf("invalid level %q: expected error, warning or log", level)
+	}
+	clear, _ := args["clear"].(bool)
+
+	if err := m.browser.EnableMonitoring(); err != nil {
+		return nil, err
+	}
+	logs := m.browser.GetConsoleLogs(level, clear)
+
+	result := map[string]interface{}{
 		"success": true,
-		"html":    html,
-		"length":  len(html),
+		"logs":    logs,
+		"count":   len(logs),
+		"cleared": clear,
+	}
+	if level != "" {
+		result["level"] = level
+	}
+	return result, nil
+}
+
+func (m *ToolsManager) setViewport(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	width, _ := args["width"].(float64)
+	height, _ := args["height"].(float64)
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("width and height are required for set_viewport (positive CSS pixels)")
+	}
+	scale := 1.0
+	if s, ok := args["scale"].(float64); ok {
+		if s <= 0 || s > maxDeviceScaleFactor {
+			return nil, fmt.Errorf("invalid scale: %v (expected a device pixel ratio up to %d)", s, maxDeviceScaleFactor)
+		}
+		scale = s
+	}
+	mobile, _ := args["mobile"].(bool)
+
+	if err := m.browser.SetViewport(int(width), int(height), scale, mobile); err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"success": true,
+		"width":   int(width),
+		"height":  int(height),
+		"scale":   scale,
+		"mobile":  mobile,
+	}
+	if ua, _ := args["user_agent"].(string); ua != "" {
+		if err := m.browser.SetUserAgent(ua); err != nil {
+			return nil, err
+		}
+		result["user_agent"] = ua
+	}
+	return result, nil
+}
+
+func (m *ToolsManager) getHTML(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	var html string
+	var err error
+	selector, _ := args["selector"].(string)
+	if selector != "" {
+		html, err = m.browser.GetElementHTML(selector)
+	} else {
+		html, err = m.browser.GetHTML()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(html)
+	offset, end := pageBounds(html, args)
+
+	result := map[string]interface{}{
+		"success":   true,
+		"html":      html[offset:end],
+		"length":    end - offset,
+		"total":     total,
+		"truncated": end < total,
+	}
+	if selector != "" {
+		result["selector"] = selector
+	}
+	if end < total {
+		result["next_offset"] = end
+	}
+	return result, nil
+}
+
+// runeStart recule i jusqu'au début d'un caractère UTF-8 pour ne pas couper un rune
+func (m *ToolsManager) getMarkdown(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	var html string
+	var err error
+	selector, _ := args["selector"].(string)
+	if selector != "" {
+		html, err = m.browser.GetElementHTML(selector)
+	} else {
+		html, err = m.browser.GetHTML()
+	}
+	if err != nil {
+		return nil, err
+	}
+	pageURL, _ := m.browser.GetURL()
+
+	// Sélecteur ou full: tout le fragment est converti, sans recherche du contenu principal
+	full, _ := args["full"].(bool)
+	markdown, title, err := htmlToMarkdown(html, pageURL, selector == "" && !full)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(markdown)
+	offset, end := pageBounds(markdown, args)
+	result := map[string]interface{}{
+		"success":    true,
+		"markdown":   markdown[offset:end],
+		"title":      title,
+		"url":        pageURL,
+		"length":     end - offset,
+		"total":      total,
+		"html_bytes": len(html),
+		"truncated":  end < total,
+	}
+	if selector != "" {
+		result["selector"] = selector
+	}
+	if end < total {
+		result["next_offset"] = end
+	}
+	return result, nil
+}
+
+// pageBounds retourne la tranche [offset, end) de text demandée par offset et max_bytes,
+// alignée sur des caractères UTF-8 complets
+func pageBounds(text string, args map[string]interface{}) (int, int) {
+	total := len(text)
+	offset := 0
+	if n, ok := args["offset"].(float64); ok && n > 0 {
+		offset = int(n)
+	}
+	if offset > total {
+		offset = total
+	}
+	offset = runeStart(text, offset)
+
+	end := total
+	if n, ok := args["max_bytes"].(float64); ok && n > 0 && offset+int(n) < total {
+		end = runeStart(text, offset+int(n))
+		if end == offset {
+			// max_bytes plus petit qu'un caractère: avancer d'un rune complet
+			_, size := utf8.DecodeRuneInString(text[offset:])
+			end = offset + size
+		}
+	}
+	return offset, end
+}
+
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+func (m *ToolsManager) describe(args map[string]interface{}) (interface{}, error) {
+	if m.browser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	maxElements := 100
+	if n, ok := args["max_elements"].(float64); ok && n > 0 {
+		maxElements = int(n)
+	}
+	if maxElements > 500 {
+		maxElements = 500
+	}
+
+	elements, truncated, err := m.browser.Describe(maxElements)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success":   true,
+		"elements":  elements,
+		"count":     len(elements),
+		"truncated": truncated,
 	}, nil
 }
 
@@ -496,23 +1763,68 @@ func (m *ToolsManager) setCookie(args map[string]interface{}) (interface{}, erro
 		return nil, fmt.Errorf("browser not started")
 	}
 
-	name, _ := args["name"].(string)
-	value, _ := args["value"].(string)
-	domain, _ := args["domain"].(string)
-	path := "/"
+	cookie := Cookie{Path: "/"}
+	cookie.Name, _ = args["name"].(string)
+	cookie.Value, _ = args["value"].(string)
+	cookie.Domain, _ = args["domain"].(string)
 	if p, ok := args["path"].(string); ok {
-		path = p
+		cookie.Path = p
+	}
+	cookie.Secure, _ = args["secure"].(bool)
+	cookie.HTTPOnly, _ = args["http_only"].(bool)
+
+	if s, ok := args["same_site"].(string); ok && s != "" {
+		sameSite, valid := sameSiteValues[strings.ToLower(s)]
+		if !valid {
+			return nil, fmt.Errorf("invalid same_site: %s (expected Strict, Lax or None)", s)
+		}
+		if sameSite == "None" && !cookie.Secure {
+			return nil, fmt.Errorf("same_site None requires secure: true")
+		}
+		cookie.SameSite = sameSite
 	}
 
-	if err := m.browser.SetCookie(name, value, domain, path); err != nil {
+	expires, hasExpires := args["expires"].(float64)
+	maxAge, hasMaxAge := args["max_age"].(float64)
+	switch {
+	case hasExpires && hasMaxAge:
+		return nil, fmt.Errorf("expires and max_age are mutually exclusive")
+	case hasMaxAge && maxAge <= 0:
+		return nil, fmt.Errorf("max_age must be positive")
+	case hasMaxAge:
+		cookie.Expires = float64(time.Now().Unix()) + maxAge
+	case hasExpires && expires <= 0:
+		return nil, fmt.Errorf("expires must be a positive Unix timestamp")
+	case hasExpires:
+		cookie.Expires = expires
+	}
+
+	if err := m.browser.SetCookie(cookie); err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"success": true,
-		"name":    name,
-		"domain":  domain,
-	}, nil
+	result := map[string]interface{}{
+		"success":   true,
+		"name":      cookie.Name,
+		"domain":    cookie.Domain,
+		"path":      cookie.Path,
+		"secure":    cookie.Secure,
+		"http_only": cookie.HTTPOnly,
+	}
+	if cookie.SameSite != "" {
+		result["same_site"] = cookie.SameSite
+	}
+	if cookie.Expires > 0 {
+		result["expires"] = int64(cookie.Expires)
+	}
+	return result, nil
+}
+
+// sameSiteValues valeurs SameSite acceptées par Network.setCookie (clé en minuscules)
+var sameSiteValues = map[string]string{
+	"strict": "Strict",
+	"lax":    "Lax",
+	"none":   "None",
 }
 
 func (m *ToolsManager) pdf(args map[string]interface{}) (interface{}, error) {
@@ -520,7 +1832,11 @@ func (m *ToolsManager) pdf(args map[string]interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("browser not started")
 	}
 
-	data, err := m.browser.PDF()
+	opts, err := pdfOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	data, err := m.browser.PDF(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -543,6 +1859,47 @@ func (m *ToolsManager) pdf(args map[string]interface{}) (interface{}, error) {
 	}, nil
 }
 
+// pdfOptions lit les options d'impression du pdf (et de render) dans args
+func pdfOptions(args map[string]interface{}) (PDFOptions, error) {
+	var opts PDFOptions
+	opts.Landscape, _ = args["landscape"].(bool)
+	opts.DisplayHeaderFooter, _ = args["display_header_footer"].(bool)
+	opts.PageRanges, _ = args["page_ranges"].(string)
+	opts.HeaderTemplate, _ = args["header_template"].(string)
+	opts.FooterTemplate, _ = args["footer_template"].(string)
+
+	for key, dst := range map[string]*float64{
+		"paper_width":  &opts.PaperWidth,
+		"paper_height": &opts.PaperHeight,
+		"scale":        &opts.Scale,
+	} {
+		if v, ok := args[key].(float64); ok {
+			if v <= 0 {
+				return opts, fmt.Errorf("invalid %s: %v (must be positive)", key, v)
+			}
+			*dst = v
+		}
+	}
+	if opts.Scale != 0 && (opts.Scale < 0.1 || opts.Scale > 2) {
+		return opts, fmt.Errorf("invalid scale: %v (expected 0.1 to 2)", opts.Scale)
+	}
+
+	for key, dst := range map[string]**float64{
+		"margin_top":    &opts.MarginTop,
+		"margin_bottom": &opts.MarginBottom,
+		"margin_left":   &opts.MarginLeft,
+		"margin_right":  &opts.MarginRight,
+	} {
+		if v, ok := args[key].(float64); ok {
+			if v < 0 {
+				return opts, fmt.Errorf("invalid %s: %v (must not be negative)", key, v)
+			}
+			*dst = &v
+		}
+	}
+	return opts, nil
+}
+
 func (m *ToolsManager) close() (interface{}, error) {
 	if m.browser == nil {
 		return map[string]interface{}{
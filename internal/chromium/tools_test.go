@@ -0,0 +1,221 @@
+package chromium
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// newFakeManager ToolsManager branché sur un FakeBrowser déjà démarré
+func newFakeManager(t *testing.T, fake *FakeBrowser) *ToolsManager {
+	t.Helper()
+	m := NewToolsManager(&ToolsConfig{ScreenshotDir: t.TempDir()})
+	m.SetBrowser(fake)
+	return m
+}
+
+func TestTypeText(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		wantCalls []string
+	}{
+		{
+			name:      "without enter",
+			args:      map[string]interface{}{"selector": "#q", "text": "holow"},
+			wantCalls: []string{"Type #q holow"},
+		},
+		{
+			name:      "press enter",
+			args:      map[string]interface{}{"selector": "#q", "text": "holow", "press_enter": true},
+			wantCalls: []string{"Type #q holow", "PressKey Enter []"},
+		},
+		{
+			name:      "press enter inside frame",
+			args:      map[string]interface{}{"selector": "#q", "text": "holow", "press_enter": true, "frame": "search"},
+			wantCalls: []string{"SetFrame search", "Type #q holow", "PressKey Enter []", "SetFrame "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &FakeBrowser{Frames: []FrameInfo{{ID: "main"}, {ID: "f1", Name: "search"}}}
+			m := newFakeManager(t, fake)
+
+			args := map[string]interface{}{"action": "type"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			result, err := m.Execute("browser", args)
+			if err != nil {
+				t.Fatalf("type: %v", err)
+			}
+			res := result.(map[string]interface{})
+			if res["success"] != true || res["length"] != len("holow") {
+				t.Errorf("result = %v", res)
+			}
+			if want, _ := tt.args["press_enter"].(bool); res["press_enter"] != want {
+				t.Errorf("press_enter = %v, want %v", res["press_enter"], want)
+			}
+			if !reflect.DeepEqual(fake.Calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", fake.Calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTypeTextErrorSkipsEnter(t *testing.T) {
+	fake := &FakeBrowser{Err: errors.New("element not found: #q")}
+	m := newFakeManager(t, fake)
+
+	_, err := m.Execute("browser", map[string]interface{}{
+		"action": "type", "selector": "#q", "text": "holow", "press_enter": true,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if want := []string{"Type #q holow"}; !reflect.DeepEqual(fake.Calls, want) {
+		t.Errorf("calls = %q, want %q", fake.Calls, want)
+	}
+}
+
+func TestFillFormPartialFailure(t *testing.T) {
+	fake := &FakeBrowser{FieldErrors: map[string]error{"#missing": errors.New("element not found: #missing")}}
+	m := newFakeManager(t, fake)
+
+	result, err := m.Execute("browser", map[string]interface{}{
+		"action": "fill_form",
+		"fields": map[string]interface{}{
+			"#email":   "a@example.com",
+			"#missing": "x",
+			"#age":     float64(42),
+			"#terms":   true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("partial failure must not be an error: %v", err)
+	}
+	res := result.(map[string]interface{})
+	if res["success"] != false || res["filled"] != 3 || res["failed"] != 1 {
+		t.Fatalf("result = %v", res)
+	}
+
+	fields := res["fields"].([]FormFieldResult)
+	byID := make(map[string]FormFieldResult, len(fields))
+	for _, f := range fields {
+		byID[f.Selector] = f
+	}
+	if f := byID["#missing"]; f.Success || f.Error != "element not found: #missing" {
+		t.Errorf("#missing = %+v", f)
+	}
+	for selector, want := range map[string]string{"#email": "a@example.com", "#age": "42", "#terms": "true"} {
+		if f := byID[selector]; !f.Success || f.Value != want {
+			t.Errorf("%s = %+v, want value %q", selector, f, want)
+		}
+	}
+}
+
+func TestFillFormRejectsInvalidValue(t *testing.T) {
+	fake := &FakeBrowser{}
+	m := newFakeManager(t, fake)
+
+	_, err := m.Execute("browser", map[string]interface{}{
+		"action": "fill_form",
+		"fields": map[string]interface{}{"#x": []interface{}{"a"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for array value")
+	}
+	if len(fake.Calls) != 0 {
+		t.Errorf("no browser call expected, got %q", fake.Calls)
+	}
+}
+
+func TestRenderUsesScratchPage(t *testing.T) {
+	fake := &FakeBrowser{URL: "https://example.com/app", HTML: "<p>user page</p>", Image: []byte("%PDF")}
+	m := newFakeManager(t, fake)
+
+	result, err := m.Execute("browser", map[string]interface{}{"action": "render", "markdown": "# Report"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if res := result.(map[string]interface{}); res["action"] != "render" || res["source"] != "markdown" {
+		t.Errorf("result = %v", res)
+	}
+	if fake.URL != "https://example.com/app" || fake.HTML != "<p>user page</p>" {
+		t.Errorf("user page overwritten: url=%q html=%q", fake.URL, fake.HTML)
+	}
+	if n := len(fake.Calls); n < 2 || fake.Calls[0] != "OpenScratchPage" || fake.Calls[n-1] != "CloseScratchPage" {
+		t.Errorf("calls = %q, want the render inside OpenScratchPage/CloseScratchPage", fake.Calls)
+	}
+}
+
+func TestSnakeCaseOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		wantCall string
+	}{
+		{"evaluate await_promise", map[string]interface{}{"action": "evaluate", "expression": "1", "await_promise": false}, "Evaluate 1 false"},
+		{"screenshot full_page", map[string]interface{}{"action": "screenshot", "full_page": true}, "Screenshot png 80 true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &FakeBrowser{}
+			m := newFakeManager(t, fake)
+			if _, err := m.Execute("browser", tt.args); err != nil {
+				t.Fatalf("%s: %v", tt.args["action"], err)
+			}
+			if want := []string{tt.wantCall}; !reflect.DeepEqual(fake.Calls, want) {
+				t.Errorf("calls = %q, want %q", fake.Calls, want)
+			}
+		})
+	}
+}
+
+func TestSetCookieOptions(t *testing.T) {
+	fake := &FakeBrowser{}
+	m := newFakeManager(t, fake)
+
+	result, err := m.Execute("browser", map[string]interface{}{
+		"action": "set_cookie", "name": "sid", "value": "x", "domain": "example.com",
+		"secure": true, "http_only": true, "same_site": "lax", "max_age": float64(60),
+	})
+	if err != nil {
+		t.Fatalf("set_cookie: %v", err)
+	}
+	res := result.(map[string]interface{})
+	if res["http_only"] != true || res["same_site"] != "Lax" || res["expires"] == nil {
+		t.Errorf("result = %v", res)
+	}
+	if c := fake.Cookies[0]; c["httpOnly"] != true || c["sameSite"] != "Lax" {
+		t.Errorf("cookie = %v", c)
+	}
+
+	if _, err := m.Execute("browser", map[string]interface{}{
+		"action": "set_cookie", "name": "sid", "value": "x", "same_site": "None",
+	}); err == nil {
+		t.Error("expected error for same_site None without secure")
+	}
+}
+
+func TestPDFOptions(t *testing.T) {
+	opts, err := pdfOptions(map[string]interface{}{
+		"paper_width": 8.27, "paper_height": 11.69, "margin_top": float64(0),
+		"page_ranges": "1-2", "display_header_footer": true, "footer_template": "<span class=pageNumber></span>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.PaperWidth != 8.27 || opts.PaperHeight != 11.69 || opts.MarginTop == nil || *opts.MarginTop != 0 {
+		t.Errorf("paper/margins = %+v", opts)
+	}
+	if opts.PageRanges != "1-2" || !opts.DisplayHeaderFooter || opts.FooterTemplate == "" {
+		t.Errorf("pages/templates = %+v", opts)
+	}
+
+	if _, err := pdfOptions(map[string]interface{}{"margin_left": float64(-1)}); err == nil {
+		t.Error("expected error for negative margin_left")
+	}
+}
@@ -44,19 +44,35 @@ type Breaker struct {
 
 // Manager gère tous les circuit breakers
 type Manager struct {
-	db       *sql.DB
-	breakers map[string]*Breaker
-	mu       sync.RWMutex
+	db               *sql.DB
+	breakers         map[string]*Breaker
+	failureThreshold int // Seuil des breakers créés par Get (circuit_breaker.failure_threshold)
+	mu               sync.RWMutex
 }
 
+// defaultFailureThreshold seuil d'échecs d'un nouveau breaker sans configuration
+const defaultFailureThreshold = 5
+
 // NewManager crée un nouveau gestionnaire de circuit breakers
 func NewManager(db *sql.DB) *Manager {
 	return &Manager{
-		db:       db,
-		breakers: make(map[string]*Breaker),
+		db:               db,
+		breakers:         make(map[string]*Breaker),
+		failureThreshold: defaultFailureThreshold,
 	}
 }
 
+// SetFailureThreshold fixe le seuil d'échecs des breakers créés ensuite (n <= 0 = inchangé)
+// Les breakers déjà persistés gardent le seuil de leur ligne circuit_breakers
+func (m *Manager) SetFailureThreshold(n int) {
+	if n <= 0 {
+		return
+	}
+	m.mu.Lock()
+	m.failureThreshold = n
+	m.mu.Unlock()
+}
+
 // LoadAll charge tous les circuit breakers depuis la base
 func (m *Manager) LoadAll() error {
 	rows, err := m.db.Query(`
@@ -115,7 +131,7 @@ func (m *Manager) Get(name string) *Breaker {
 	b = &Breaker{
 		name:             name,
 		state:            StateClosed,
-		failureThreshold: 5,
+		failureThreshold: m.failureThreshold,
 		successThreshold: 3,
 		timeoutSeconds:   60,
 		lastStateChange:  time.Now(),
@@ -127,12 +143,30 @@ func (m *Manager) Get(name string) *Breaker {
 		INSERT INTO circuit_breakers
 		(name, state, failure_count, success_count, failure_threshold,
 		 success_threshold, timeout_seconds, last_state_change_at, half_open_max_calls)
-		VALUES (?, 'closed', 0, 0, 5, 3, 60, strftime('%s', 'now'), 3)`, name)
+		VALUES (?, 'closed', 0, 0, ?, 3, 60, strftime('%s', 'now'), 3)`, name, b.failureThreshold)
 
 	m.breakers[name] = b
 	return b
 }
 
+// Remove oublie le circuit breaker d'un service supprimé (mémoire et base)
+// Un breaker encore référencé par une exécution en cours n'est plus persisté
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	delete(m.breakers, name)
+	m.mu.Unlock()
+
+	execOrLog(m.db, `DELETE FROM circuit_breakers WHERE name = ?`, name)
+}
+
+// Reset ferme le circuit d'un service et retourne son état précédent
+func (m *Manager) Reset(name string) State {
+	b := m.Get(name)
+	previous := b.State()
+	b.Reset(m.db)
+	return previous
+}
+
 // CanExecute vérifie si le circuit permet l'exécution
 func (b *Breaker) CanExecute() (bool, error) {
 	b.mu.Lock()
@@ -227,6 +261,17 @@ func (b *Breaker) RecordFailure(db *sql.DB) {
 		b.lastStateChange.Unix(), b.name)
 }
 
+// RecordIgnored clôt un appel sans le compter (erreur de l'appelant, annulation)
+// En half-open, l'appel de test est rendu pour ne pas épuiser halfOpenMaxCalls
+func (b *Breaker) RecordIgnored() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.halfOpenCalls > 0 {
+		b.halfOpenCalls--
+	}
+}
+
 // State retourne l'état actuel
 func (b *Breaker) State() State {
 	b.mu.RLock()
@@ -251,6 +296,22 @@ func (b *Breaker) Reset(db *sql.DB) {
 		WHERE name = ?`, b.name)
 }
 
+// RetryAfter retourne le temps restant avant que le circuit ouvert passe en half-open
+// Retourne 0 si le circuit accepte déjà des appels (ou attend la fin des appels de test)
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.state != StateOpen {
+		return 0
+	}
+	remaining := time.Duration(b.timeoutSeconds)*time.Second - time.Since(b.lastStateChange)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
 // Stats retourne les statistiques du circuit breaker
 func (b *Breaker) Stats() map[string]interface{} {
 	b.mu.RLock()
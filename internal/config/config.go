@@ -1,36 +1,115 @@
 // Package config gère la configuration du serveur
+// Source unique: table config de lifecycle-core (lue et écrite uniquement via ce package)
 package config
 
 import (
 	"database/sql"
+	"fmt"
+	"os"
 	"strconv"
 )
 
 // Config représente la configuration du serveur
 type Config struct {
-	ServerName            string
-	ServerVersion         string
-	PollingIntervalMs     int
-	HeartbeatIntervalSecs int
-	ShutdownTimeoutSecs   int
-	CacheDefaultTTLSecs   int
-	RetryMaxAttempts      int
+	ServerName              string
+	ServerVersion           string
+	PollingIntervalMs       int
+	HeartbeatIntervalSecs   int
+	ShutdownTimeoutSecs     int
+	RetryMaxAttempts        int
 	CircuitBreakerThreshold int
+	IdempotenceEnabled      bool // Déduplication des requêtes via processed_log
+	SafeMode                bool // Browser et actions de génération LLM désactivés
+	IdleShutdownSecs        int  // Arrêt après ce délai sans requête (0 = jamais)
 }
 
-// Load charge la configuration depuis la base
+// Variables d'environnement prioritaires sur la table config
+const (
+	EnvIdempotence = "HOLOW_MCP_IDEMPOTENCE" // idempotence.enabled
+	EnvSafeMode    = "HOLOW_MCP_SAFE_MODE"   // server.safe_mode
+)
+
+// Default représente une clé de configuration et sa valeur initiale
+type Default struct {
+	Key         string
+	Value       string
+	ValueType   string // string, number, boolean, json
+	Description string
+}
+
+// Defaults liste les clés serveur garanties présentes dans la table config
+// Doit rester aligné avec les INSERT OR IGNORE de schemas/lifecycle-core.sql
+var Defaults = []Default{
+	{"server.name", "holow-mcp", "string", "Nom du serveur MCP"},
+	{"server.version", "1.0.0", "string", "Version du serveur"},
+	{"polling.interval_ms", "2000", "number", "Intervalle hot reload tools"},
+	{"heartbeat.interval_seconds", "15", "number", "Intervalle heartbeat"},
+	{"shutdown.timeout_seconds", "60", "number", "Timeout graceful shutdown"},
+	{"server.safe_mode", "false", "boolean", "Mode restreint: tool browser et génération LLM désactivés (surchargeable par HOLOW_MCP_SAFE_MODE)"},
+	{"server.idle_shutdown_seconds", "0", "number", "Arrêt gracieux après ce délai sans requête MCP, pour les hébergements à la demande (0 = jamais)"},
+	{"server.max_tool_wall_time_seconds", "120", "number", "Durée max d'un tools/call complet (0 = illimité)"},
+	{"cdp.commands_retention_seconds", "3600", "number", "Durée de conservation des cdp_commands traitées (0 = illimité)"},
+	{"cdp.max_result_bytes", "1048576", "number", "Taille max d'un résultat cdp_call; au-delà, enveloppe {truncated, size, path, preview} (0 = illimité)"},
+	{"cdp.max_pending_commands", "1000", "number", "Nombre max de cdp_commands en attente; au-delà, l'INSERT échoue (0 = illimité)"},
+	{"cdp.spill_large_results", "true", "boolean", "Copier les résultats cdp_call tronqués en entier dans cdp-results/ du chemin de base (chemin dans path)"},
+	{"idempotence.enabled", "true", "boolean", "Déduplication des requêtes déjà traitées (surchargeable par HOLOW_MCP_IDEMPOTENCE)"},
+	{"idempotence.retention_seconds", "604800", "number", "Durée de conservation de processed_log (0 = illimité)"},
+	{"idempotence.max_rows", "100000", "number", "Nombre max d'entrées processed_log conservées (0 = illimité)"},
+	{"telemetry.log_level", "info", "string", "Niveau minimal écrit dans telemetry_logs (debug, info, warn, error)"},
+	{"telemetry.logs_retention_seconds", "604800", "number", "Durée de conservation de telemetry_logs (0 = illimité)"},
+	{"cache.default_ttl_seconds", "3600", "number", "TTL cache par défaut"},
+	{"retry.max_attempts", "3", "number", "Nombre max retries"},
+	{"retry.base_delay_ms", "200", "number", "Délai avant le premier retry en processus d'un tool (fixed: constant, exponential: doublé, plafonné à 5 s)"},
+	{"circuit_breaker.failure_threshold", "5", "number", "Seuil échecs circuit breaker"},
+	{"db.pragmas", "", "json", "Surcharges de pragmas par base {input|lifecycle-tools|lifecycle-execution|lifecycle-core|output|metadata: {busy_timeout, wal_autocheckpoint}} (vide = 5000 ms et 10000 pages partout)"},
+	{"disk.min_free_mb", "500", "number", "Seuil d'alerte espace disque libre (Mo)"},
+	{"disk.poison_pill_on_low", "false", "boolean", "Arrêt gracieux si espace disque sous le seuil"},
+	{"brainloop.secret_patterns", "", "string", "Motifs de secrets additionnels pour read_config (séparés par des virgules)"},
+	{"llm.provider_order", "claude,gemini,cerebras", "string", "Ordre de repli des providers LLM (séparés par des virgules)"},
+	{"llm.prices", "", "json", "Tarifs LLM en USD par million de tokens {provider: {prompt, completion}} (vide = tarifs par défaut)"},
+	{"templates.env_allowlist", "", "string", "Variables d'environnement autorisées dans {{env:NAME}} (séparées par des virgules)"},
+}
+
+// EnsureDefaults insère les clés de Defaults absentes sans écraser les valeurs existantes
+// Rattrape les bases créées avant l'ajout d'une clé au schéma
+func EnsureDefaults(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO config (key, value, value_type, description)
+		VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, d := range Defaults {
+		if _, err := stmt.Exec(d.Key, d.Value, d.ValueType, d.Description); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Load charge la configuration depuis la base puis applique les surcharges d'environnement
+// Une valeur invalide conserve la valeur par défaut
 func Load(db *sql.DB) (*Config, error) {
 	cfg := &Config{
 		// Valeurs par défaut
-		ServerName:            "holow-mcp",
-		ServerVersion:         "1.0.0",
-		PollingIntervalMs:     2000,
-		HeartbeatIntervalSecs: 15,
-		ShutdownTimeoutSecs:   60,
-		CacheDefaultTTLSecs:   3600,
-		RetryMaxAttempts:      3,
+		ServerName:              "holow-mcp",
+		ServerVersion:           "1.0.0",
+		PollingIntervalMs:       2000,
+		HeartbeatIntervalSecs:   15,
+		ShutdownTimeoutSecs:     60,
+		RetryMaxAttempts:        3,
 		CircuitBreakerThreshold: 5,
+		IdempotenceEnabled:      true,
 	}
+	defer applyEnv(cfg)
 
 	rows, err := db.Query(`SELECT key, value FROM config`)
 	if err != nil {
@@ -50,31 +129,94 @@ func Load(db *sql.DB) (*Config, error) {
 		case "server.version":
 			cfg.ServerVersion = value
 		case "polling.interval_ms":
-			cfg.PollingIntervalMs, _ = strconv.Atoi(value)
+			setPositiveInt(&cfg.PollingIntervalMs, value)
 		case "heartbeat.interval_seconds":
-			cfg.HeartbeatIntervalSecs, _ = strconv.Atoi(value)
+			setPositiveInt(&cfg.HeartbeatIntervalSecs, value)
 		case "shutdown.timeout_seconds":
-			cfg.ShutdownTimeoutSecs, _ = strconv.Atoi(value)
-		case "cache.default_ttl_seconds":
-			cfg.CacheDefaultTTLSecs, _ = strconv.Atoi(value)
+			setPositiveInt(&cfg.ShutdownTimeoutSecs, value)
 		case "retry.max_attempts":
-			cfg.RetryMaxAttempts, _ = strconv.Atoi(value)
+			setPositiveInt(&cfg.RetryMaxAttempts, value)
 		case "circuit_breaker.failure_threshold":
-			cfg.CircuitBreakerThreshold, _ = strconv.Atoi(value)
+			setPositiveInt(&cfg.CircuitBreakerThreshold, value)
+		case "idempotence.enabled":
+			setBool(&cfg.IdempotenceEnabled, value)
+		case "server.safe_mode":
+			setBool(&cfg.SafeMode, value)
+		case "server.idle_shutdown_seconds":
+			setPositiveInt(&cfg.IdleShutdownSecs, value)
 		}
 	}
 
-	return cfg, nil
+	return cfg, rows.Err()
+}
+
+// setPositiveInt remplace *dst si value est un entier strictement positif
+func setPositiveInt(dst *int, value string) {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		*dst = n
+	}
 }
 
-// Save sauvegarde une valeur de configuration
+// setBool remplace *dst si value est un booléen valide (true/false, 1/0)
+func setBool(dst *bool, value string) {
+	if b, err := strconv.ParseBool(value); err == nil {
+		*dst = b
+	}
+}
+
+// applyEnv applique les variables d'environnement, prioritaires sur la table config
+func applyEnv(cfg *Config) {
+	if value, ok := os.LookupEnv(EnvIdempotence); ok {
+		setBool(&cfg.IdempotenceEnabled, value)
+	}
+	if value, ok := os.LookupEnv(EnvSafeMode); ok {
+		setBool(&cfg.SafeMode, value)
+	}
+}
+
+// Save sauvegarde une valeur de configuration (crée la clé si absente)
 func Save(db *sql.DB, key, value string) error {
 	_, err := db.Exec(`
-		UPDATE config SET value = ?, updated_at = strftime('%s', 'now')
-		WHERE key = ?`, value, key)
+		INSERT INTO config (key, value, updated_at)
+		VALUES (?, ?, strftime('%s', 'now'))
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at`, key, value)
 	return err
 }
 
+// SaveAll sauvegarde plusieurs clés dans une transaction
+// ValueType (string si vide) et Description ne sont posés qu'à la création de la clé
+func SaveAll(db *sql.DB, entries []Default) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO config (key, value, value_type, description, updated_at)
+		VALUES (?, ?, ?, ?, strftime('%s', 'now'))
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		valueType := e.ValueType
+		if valueType == "" {
+			valueType = "string"
+		}
+		if _, err := stmt.Exec(e.Key, e.Value, valueType, e.Description); err != nil {
+			return fmt.Errorf("save %s: %w", e.Key, err)
+		}
+	}
+	return tx.Commit()
+}
+
 // Get récupère une valeur de configuration
 func Get(db *sql.DB, key string) (string, error) {
 	var value string
@@ -14,6 +14,8 @@ import (
 const HolowAppID = 0x484F4C57
 
 // horosPragmas contient les pragmas optimisés pour HOROS
+// Hors journal_mode (persistant dans le fichier), ils valent par connexion: lockedConnector
+// les applique à chaque connexion ouverte par le pool
 var horosPragmas = []string{
 	"PRAGMA journal_mode = WAL",
 	"PRAGMA synchronous = NORMAL",
@@ -27,44 +29,27 @@ var horosPragmas = []string{
 // Note: avec modernc, les custom functions sont enregistrées globalement
 type ConnCallback func(db *sql.DB) error
 
-// applyPragmas applique les pragmas HOROS à une base
-func applyPragmas(db *sql.DB) error {
-	for _, pragma := range horosPragmas {
-		if _, err := db.Exec(pragma); err != nil {
-			return fmt.Errorf("failed to set pragma %s: %w", pragma, err)
-		}
-	}
-	return nil
-}
-
 // openDBWithConnector ouvre une base SQLite avec un callback optionnel
 // C'est la méthode unifiée pour TOUTES les bases holow-mcp
-func openDBWithConnector(path string, callback ConnCallback) (*sql.DB, error) {
-	// Ouvrir la base avec modernc.org/sqlite
-	db, err := sql.Open("sqlite", path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Appliquer les pragmas HOROS
-	if err := applyPragmas(db); err != nil {
-		db.Close()
-		return nil, err
-	}
+// Le connecteur retourné permet de modifier les pragmas des connexions (ApplyPragmas)
+func openDBWithConnector(path string, callback ConnCallback) (*sql.DB, *lockedConnector, error) {
+	// Ouvrir la base avec modernc.org/sqlite, écritures sérialisées dans le processus (writelock.go)
+	// et pragmas HOROS appliqués à chaque connexion
+	db, connector := openLocked(path, horosPragmas)
 
 	// Appeler le callback custom si fourni
 	if callback != nil {
 		if err := callback(db); err != nil {
 			db.Close()
-			return nil, fmt.Errorf("custom callback failed: %w", err)
+			return nil, nil, fmt.Errorf("custom callback failed: %w", err)
 		}
 	}
 
-	// Tester la connexion
+	// Tester la connexion (et les pragmas de la première connexion)
 	if err := db.Ping(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return db, nil
+	return db, connector, nil
 }
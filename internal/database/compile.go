@@ -0,0 +1,116 @@
+// Package database - Compilation SQL sans exécution (EXPLAIN)
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// SplitStatements découpe un script SQL en instructions
+// Les ";" dans les chaînes, identifiants quotés, commentaires et corps de CREATE TRIGGER sont ignorés
+func SplitStatements(script string) []string {
+	var statements []string
+	var word strings.Builder
+	start := 0
+	first := ""      // Premier mot-clé de l'instruction courante
+	depth := 0       // Blocs BEGIN/CASE ... END ouverts dans un corps de trigger
+	trigger := false // L'instruction courante est un CREATE TRIGGER
+
+	flushWord := func() {
+		w := strings.ToUpper(word.String())
+		word.Reset()
+		if first == "" {
+			first = w
+		}
+		switch {
+		case w == "TRIGGER" && first == "CREATE":
+			trigger = true
+		case !trigger:
+		case w == "BEGIN" || w == "CASE":
+			depth++
+		case w == "END" && depth > 0:
+			depth--
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			word.WriteByte(c)
+			continue
+		}
+		flushWord()
+
+		if end := SkipLiteral(script, i); end > i {
+			i = end - 1
+			continue
+		}
+
+		if c == ';' && depth == 0 {
+			if stmt := strings.TrimSpace(script[start:i]); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			start = i + 1
+			first, trigger = "", false
+		}
+	}
+	flushWord()
+
+	if start < len(script) {
+		if stmt := strings.TrimSpace(script[start:]); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// SkipLiteral retourne la fin (exclusive) de la chaîne ou de l'identifiant quoté (délimité par ', ", ` ou [ ])
+// ou du commentaire (--, /* */) qui commence à s[i], ou i si s[i] n'en ouvre aucun
+// Un littéral non terminé s'étend jusqu'à la fin de s
+func SkipLiteral(s string, i int) int {
+	c := s[i]
+	switch {
+	case c == '\'' || c == '"' || c == '`' || c == '[':
+		closing := c
+		if c == '[' {
+			closing = ']'
+		}
+		for j := i + 1; j < len(s); j++ {
+			if s[j] != closing {
+				continue
+			}
+			// Quote doublée ('' ou "") = caractère échappé
+			if closing != ']' && j+1 < len(s) && s[j+1] == closing {
+				j++
+				continue
+			}
+			return j + 1
+		}
+		return len(s)
+	case c == '-' && i+1 < len(s) && s[i+1] == '-':
+		if end := strings.IndexByte(s[i:], '\n'); end != -1 {
+			return i + end + 1
+		}
+		return len(s)
+	case c == '/' && i+1 < len(s) && s[i+1] == '*':
+		if end := strings.Index(s[i+2:], "*/"); end != -1 {
+			return i + 2 + end + 2
+		}
+		return len(s)
+	}
+	return i
+}
+
+// CompileStatement compile une instruction sur db sans l'exécuter (EXPLAIN)
+// Détecte erreurs de syntaxe, tables, colonnes et fonctions inconnues
+func CompileStatement(ctx context.Context, db *sql.DB, stmt string) error {
+	if !strings.HasPrefix(strings.ToUpper(stmt), "EXPLAIN") {
+		stmt = "EXPLAIN " + stmt
+	}
+	rows, err := db.QueryContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSkipLiteral(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		i    int
+		want int
+	}{
+		{"not a literal", "SELECT 1", 0, 0},
+		{"string", "'a;b' x", 0, 5},
+		{"doubled quote", "'it''s' x", 0, 7},
+		{"quoted identifier", `"a""b" x`, 0, 6},
+		{"bracket identifier", "[a]] x", 0, 3},
+		{"backtick identifier", "`a` x", 0, 3},
+		{"line comment", "-- c;\nx", 0, 6},
+		{"block comment", "/* ; */x", 0, 7},
+		{"unterminated string", "x 'abc", 2, 6},
+		{"unterminated block comment", "/* abc", 0, 6},
+		{"single dash", "- 1", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SkipLiteral(tt.s, tt.i); got != tt.want {
+				t.Errorf("SkipLiteral(%q, %d) = %d, want %d", tt.s, tt.i, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `CREATE TABLE t (v TEXT DEFAULT ';'); -- fin; de ligne
+		/* ; */ INSERT INTO "a;b" VALUES ('x;y');
+		CREATE TRIGGER tr AFTER INSERT ON t BEGIN SELECT CASE WHEN 1 THEN 1 END; END;
+		SELECT 1`
+	want := []string{
+		`CREATE TABLE t (v TEXT DEFAULT ';')`,
+		"-- fin; de ligne\n\t\t/* ; */ INSERT INTO \"a;b\" VALUES ('x;y')",
+		`CREATE TRIGGER tr AFTER INSERT ON t BEGIN SELECT CASE WHEN 1 THEN 1 END; END`,
+		`SELECT 1`,
+	}
+	if got := SplitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitStatements() = %q, want %q", got, want)
+	}
+}
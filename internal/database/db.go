@@ -2,11 +2,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Manager gère les connexions aux 6 bases de données
@@ -20,6 +23,9 @@ type Manager struct {
 	Output            *sql.DB
 	Metadata          *sql.DB
 
+	connectors map[*sql.DB]*lockedConnector // Pragmas par connexion de chaque base (ApplyPragmas)
+	pinned     []*sql.Conn                  // Connexions tenues ouvertes (bases en mémoire, cf. NewMemoryManager)
+
 	mu sync.RWMutex
 }
 
@@ -40,42 +46,102 @@ var DBNames = struct {
 	Metadata:       "holow-mcp.metadata.db",
 }
 
+// NamedDB base identifiée par son nom court (input, lifecycle-tools, ...)
+type NamedDB struct {
+	Name string
+	DB   *sql.DB
+}
+
+// Named retourne les 6 bases dans l'ordre du pattern 6-BDD
+func (m *Manager) Named() []NamedDB {
+	return []NamedDB{
+		{"input", m.Input},
+		{"lifecycle-tools", m.LifecycleTools},
+		{"lifecycle-execution", m.LifecycleExec},
+		{"lifecycle-core", m.LifecycleCore},
+		{"output", m.Output},
+		{"metadata", m.Metadata},
+	}
+}
+
+// memoryManagerSeq distingue les jeux de bases en mémoire d'un même processus
+var memoryManagerSeq int64
+
 // NewManager crée un nouveau gestionnaire de bases de données
 // cdpCallback est un callback optionnel pour LifecycleTools (fonctions SQL CDP)
 func NewManager(basePath string, cdpCallback ConnCallback) (*Manager, error) {
-	m := &Manager{basePath: basePath}
+	return openManager(basePath, func(name string) string {
+		return filepath.Join(basePath, name)
+	}, cdpCallback)
+}
+
+// NewMemoryManager ouvre les 6 bases en mémoire (tests, exécutions éphémères)
+// Le cache partagé permet à toutes les connexions du pool de voir les mêmes données;
+// chaque appel obtient un jeu de bases isolé (nom unique dans le processus).
+// Une base en mémoire disparaît avec sa dernière connexion: une connexion par base
+// reste ouverte jusqu'à Close. Les schémas restent à initialiser.
+func NewMemoryManager(cdpCallback ConnCallback) (*Manager, error) {
+	seq := atomic.AddInt64(&memoryManagerSeq, 1)
+	m, err := openManager("", func(name string) string {
+		return fmt.Sprintf("file:holow-%d-%d-%s?mode=memory&cache=shared", os.Getpid(), seq, name)
+	}, cdpCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, named := range m.Named() {
+		conn, err := named.DB.Conn(context.Background())
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("failed to pin %s: %w", named.Name, err)
+		}
+		m.pinned = append(m.pinned, conn)
+	}
+	return m, nil
+}
+
+// openManager ouvre les 6 bases, dsn traduisant un nom de fichier en source SQLite
+func openManager(basePath string, dsn func(name string) string, cdpCallback ConnCallback) (*Manager, error) {
+	m := &Manager{basePath: basePath, connectors: map[*sql.DB]*lockedConnector{}}
+	open := func(name string, callback ConnCallback) (*sql.DB, error) {
+		db, connector, err := openDBWithConnector(dsn(name), callback)
+		if err == nil {
+			m.connectors[db] = connector
+		}
+		return db, err
+	}
 
 	var err error
 
 	// Ouvrir toutes les bases avec la méthode unifiée
 	// Input, Exec, Core, Output, Metadata : pas de callback (nil)
-	m.Input, err = openDBWithConnector(filepath.Join(basePath, DBNames.Input), nil)
+	m.Input, err = open(DBNames.Input, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open input.db: %w", err)
 	}
 
 	// LifecycleTools : avec callback CDP si fourni
-	m.LifecycleTools, err = openDBWithConnector(filepath.Join(basePath, DBNames.LifecycleTools), cdpCallback)
+	m.LifecycleTools, err = open(DBNames.LifecycleTools, cdpCallback)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open lifecycle-tools.db: %w", err)
 	}
 
-	m.LifecycleExec, err = openDBWithConnector(filepath.Join(basePath, DBNames.LifecycleExec), nil)
+	m.LifecycleExec, err = open(DBNames.LifecycleExec, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open lifecycle-execution.db: %w", err)
 	}
 
-	m.LifecycleCore, err = openDBWithConnector(filepath.Join(basePath, DBNames.LifecycleCore), nil)
+	m.LifecycleCore, err = open(DBNames.LifecycleCore, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open lifecycle-core.db: %w", err)
 	}
 
-	m.Output, err = openDBWithConnector(filepath.Join(basePath, DBNames.Output), nil)
+	m.Output, err = open(DBNames.Output, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open output.db: %w", err)
 	}
 
-	m.Metadata, err = openDBWithConnector(filepath.Join(basePath, DBNames.Metadata), nil)
+	m.Metadata, err = open(DBNames.Metadata, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open metadata.db: %w", err)
 	}
@@ -193,10 +259,49 @@ func (m *Manager) MarkProcessed(hash, requestID, toolName, status, resultHash st
 	return err
 }
 
+// PruneProcessed supprime les entrées processed_log plus anciennes que maxAge,
+// puis les plus anciennes au-delà de maxRows (0 = pas de limite pour l'un ou l'autre)
+// Une requête purgée n'est plus dédupliquée: elle sera réexécutée si elle revient
+func (m *Manager) PruneProcessed(maxAge time.Duration, maxRows int) (int64, error) {
+	var pruned int64
+	if maxAge > 0 {
+		res, err := m.LifecycleExec.Exec(`
+			DELETE FROM processed_log WHERE created_at < ?`,
+			time.Now().Add(-maxAge).Unix())
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		pruned += n
+	}
+
+	if maxRows > 0 {
+		res, err := m.LifecycleExec.Exec(`
+			DELETE FROM processed_log WHERE hash IN (
+				SELECT hash FROM processed_log
+				ORDER BY created_at DESC
+				LIMIT -1 OFFSET ?
+			)`, maxRows)
+		if err != nil {
+			return pruned, err
+		}
+		n, _ := res.RowsAffected()
+		pruned += n
+	}
+	return pruned, nil
+}
+
 // Close ferme toutes les connexions
 func (m *Manager) Close() error {
 	var errs []error
 
+	for _, conn := range m.pinned {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	m.pinned = nil
+
 	if err := m.Input.Close(); err != nil {
 		errs = append(errs, err)
 	}
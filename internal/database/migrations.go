@@ -0,0 +1,87 @@
+// Package database - État des migrations de schéma par base (migrations_status, -migrations-status)
+package database
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+)
+
+// MigrationStatus version d'une base et migrations disponibles, appliquées ou en attente
+// Une migration NNN est appliquée quand user_version >= NNN
+type MigrationStatus struct {
+	Database      string   `json:"database"`
+	Version       int      `json:"version"`        // PRAGMA user_version
+	TargetVersion int      `json:"target_version"` // SchemaVersion du binaire
+	Available     []string `json:"available"`
+	Applied       []string `json:"applied"`
+	Pending       []string `json:"pending"`
+	UpToDate      bool     `json:"up_to_date"` // Rien à faire au prochain démarrage
+	Error         string   `json:"error,omitempty"`
+}
+
+// MigrationsStatus rapporte l'état des migrations de bases ouvertes
+// migrationsPath est le dossier des schémas (migrations/{base}/NNN_x.sql)
+func MigrationsStatus(dbs []NamedDB, migrationsPath string) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(dbs))
+	for _, n := range dbs {
+		statuses = append(statuses, migrationStatus(n.Name, n.DB, migrationsPath))
+	}
+	return statuses
+}
+
+// MigrationsStatusAt rapporte l'état des migrations des 6 bases de basePath, ouvertes en lecture seule
+// Utilisable avant le démarrage (les migrations ne sont appliquées qu'au boot du serveur)
+func MigrationsStatusAt(basePath, migrationsPath string) []MigrationStatus {
+	files := []struct{ name, file string }{
+		{"input", DBNames.Input},
+		{"lifecycle-tools", DBNames.LifecycleTools},
+		{"lifecycle-execution", DBNames.LifecycleExec},
+		{"lifecycle-core", DBNames.LifecycleCore},
+		{"output", DBNames.Output},
+		{"metadata", DBNames.Metadata},
+	}
+
+	statuses := make([]MigrationStatus, 0, len(files))
+	for _, f := range files {
+		db, err := OpenReadOnly(filepath.Join(basePath, f.file))
+		if err != nil {
+			statuses = append(statuses, MigrationStatus{Database: f.name, TargetVersion: SchemaVersion, Error: err.Error()})
+			continue
+		}
+		statuses = append(statuses, migrationStatus(f.name, db, migrationsPath))
+		db.Close()
+	}
+	return statuses
+}
+
+// migrationStatus lit user_version et classe les migrations de la base name
+func migrationStatus(name string, db *sql.DB, migrationsPath string) MigrationStatus {
+	status := MigrationStatus{
+		Database:      name,
+		TargetVersion: SchemaVersion,
+		Available:     []string{},
+		Applied:       []string{},
+		Pending:       []string{},
+	}
+	if err := db.QueryRow("PRAGMA user_version").Scan(&status.Version); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	migrations, err := listMigrations(filepath.Join(migrationsPath, "migrations", name))
+	if err != nil && !os.IsNotExist(err) {
+		status.Error = err.Error()
+		return status
+	}
+	for _, mig := range migrations {
+		status.Available = append(status.Available, mig.Name)
+		if mig.Version > status.Version {
+			status.Pending = append(status.Pending, mig.Name)
+		} else {
+			status.Applied = append(status.Applied, mig.Name)
+		}
+	}
+	status.UpToDate = status.Version >= SchemaVersion && len(status.Pending) == 0
+	return status
+}
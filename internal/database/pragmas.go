@@ -0,0 +1,95 @@
+// Package database - Surcharges de pragmas par base (contention, checkpoints WAL)
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Pragmas surcharge pour une base les valeurs de horosPragmas (0 = valeur par défaut)
+type Pragmas struct {
+	BusyTimeout       int `json:"busy_timeout"`       // ms (défaut 5000)
+	WALAutocheckpoint int `json:"wal_autocheckpoint"` // pages (défaut 10000)
+}
+
+// ParsePragmaOverrides parse la clé de config db.pragmas, indexée par nom court de base
+// Ex: {"output": {"busy_timeout": 15000}, "lifecycle-execution": {"wal_autocheckpoint": 2000}}
+func ParsePragmaOverrides(value string) (map[string]Pragmas, error) {
+	overrides := map[string]Pragmas{}
+	if strings.TrimSpace(value) == "" {
+		return overrides, nil
+	}
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid db.pragmas: %w", err)
+	}
+
+	for name, p := range overrides {
+		if _, ok := dbShortNames[name]; !ok {
+			return nil, fmt.Errorf("invalid db.pragmas: unknown database %q (expected %s)", name, strings.Join(shortNames(), ", "))
+		}
+		if p.BusyTimeout < 0 || p.WALAutocheckpoint < 0 {
+			return nil, fmt.Errorf("invalid db.pragmas: %s: negative value", name)
+		}
+	}
+	return overrides, nil
+}
+
+// dbShortNames noms courts des 6 bases (clés de db.pragmas)
+var dbShortNames = map[string]func(m *Manager) *sql.DB{
+	"input":               func(m *Manager) *sql.DB { return m.Input },
+	"lifecycle-tools":     func(m *Manager) *sql.DB { return m.LifecycleTools },
+	"lifecycle-execution": func(m *Manager) *sql.DB { return m.LifecycleExec },
+	"lifecycle-core":      func(m *Manager) *sql.DB { return m.LifecycleCore },
+	"output":              func(m *Manager) *sql.DB { return m.Output },
+	"metadata":            func(m *Manager) *sql.DB { return m.Metadata },
+}
+
+// shortNames retourne les noms courts triés (messages d'erreur)
+func shortNames() []string {
+	names := make([]string, 0, len(dbShortNames))
+	for name := range dbShortNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPragmas applique les surcharges par-dessus les pragmas par défaut (horosPragmas)
+// busy_timeout et wal_autocheckpoint valant par connexion, les surcharges sont posées sur le connecteur:
+// les connexions inactives du pool sont fermées pour que toutes les suivantes les reçoivent
+func (m *Manager) ApplyPragmas(overrides map[string]Pragmas) error {
+	for name, p := range overrides {
+		get, ok := dbShortNames[name]
+		if !ok {
+			return fmt.Errorf("unknown database: %s", name)
+		}
+		db := get(m)
+		connector, ok := m.connectors[db]
+		if !ok {
+			return fmt.Errorf("%s: database not opened by this manager", name)
+		}
+
+		pragmas := append([]string{}, horosPragmas...)
+		if p.BusyTimeout > 0 {
+			pragmas = append(pragmas, fmt.Sprintf("PRAGMA busy_timeout = %d", p.BusyTimeout))
+		}
+		if p.WALAutocheckpoint > 0 {
+			pragmas = append(pragmas, fmt.Sprintf("PRAGMA wal_autocheckpoint = %d", p.WALAutocheckpoint))
+		}
+		connector.setPragmas(pragmas)
+
+		// Vider le pool inactif puis vérifier les pragmas sur une connexion neuve
+		db.SetMaxIdleConns(0)
+		db.SetMaxIdleConns(defaultMaxIdleConns)
+		if err := db.Ping(); err != nil {
+			return fmt.Errorf("%s: failed to apply pragmas: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// defaultMaxIdleConns valeur par défaut de database/sql, rétablie après ApplyPragmas
+const defaultMaxIdleConns = 2
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// pragmaOnConns lit un pragma sur n connexions distinctes du pool, tenues ouvertes simultanément
+func pragmaOnConns(t *testing.T, db *sql.DB, pragma string, n int) []int {
+	t.Helper()
+	ctx := context.Background()
+	values := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("conn %d: %v", i, err)
+		}
+		defer conn.Close()
+
+		var v int
+		if err := conn.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(&v); err != nil {
+			t.Fatalf("conn %d: PRAGMA %s: %v", i, pragma, err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestApplyPragmasEveryConnection(t *testing.T) {
+	m, err := NewManager(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	for i, v := range pragmaOnConns(t, m.Output, "busy_timeout", 4) {
+		if v != 5000 {
+			t.Errorf("default busy_timeout on conn %d = %d, want 5000", i, v)
+		}
+	}
+
+	overrides, err := ParsePragmaOverrides(`{"output": {"busy_timeout": 15000, "wal_autocheckpoint": 2000}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ApplyPragmas(overrides); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, v := range pragmaOnConns(t, m.Output, "busy_timeout", 4) {
+		if v != 15000 {
+			t.Errorf("busy_timeout on conn %d = %d, want 15000", i, v)
+		}
+	}
+	for i, v := range pragmaOnConns(t, m.Output, "wal_autocheckpoint", 4) {
+		if v != 2000 {
+			t.Errorf("wal_autocheckpoint on conn %d = %d, want 2000", i, v)
+		}
+	}
+	for i, v := range pragmaOnConns(t, m.Input, "busy_timeout", 4) {
+		if v != 5000 {
+			t.Errorf("input busy_timeout on conn %d = %d, want 5000 (not overridden)", i, v)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package database - Connexion en lecture seule à une base arbitraire
+package database
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OpenReadOnly ouvre path en lecture seule (mode=ro): la base n'est jamais modifiée ni créée
+// Une base WAL sans fichier -shm est ouverte immutable, sinon SQLite créerait -wal/-shm
+// (qu'une connexion lecture seule ne peut pas supprimer à la fermeture)
+func OpenReadOnly(path string) (*sql.DB, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrInvalid}
+	}
+
+	query := "mode=ro"
+	if isWAL(path) {
+		if _, err := os.Stat(path + "-shm"); os.IsNotExist(err) {
+			query += "&immutable=1"
+		}
+	}
+
+	db, err := sql.Open("sqlite", readOnlyDSN(path, query))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// readOnlyDSN construit l'URI SQLite file: de path (espaces, # et ? échappés)
+func readOnlyDSN(path, query string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // C:/x -> /C:/x
+	}
+	u := url.URL{Scheme: "file", Path: p, RawQuery: query}
+	return u.String()
+}
+
+// isWAL indique si l'en-tête de la base déclare le mode WAL (octets 18-19 = 2)
+func isWAL(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, 20)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return false
+	}
+	return string(header[:16]) == "SQLite format 3\x00" && header[18] == 2 && header[19] == 2
+}
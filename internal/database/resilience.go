@@ -11,7 +11,7 @@ import (
 )
 
 // SchemaVersion actuelle (incrémenter à chaque migration)
-const SchemaVersion = 1
+const SchemaVersion = 2
 
 // RecoverAndMigrate exécute la récupération et migrations au démarrage
 // Appelé une seule fois au boot, pas de goroutine
@@ -73,38 +73,23 @@ func applyMigrations(dbName string, db *sql.DB, migrationsPath string, currentVe
 		return err
 	}
 
-	// Lister les fichiers de migration
-	files, err := os.ReadDir(dbMigrationsPath)
+	migrations, err := listMigrations(dbMigrationsPath)
 	if err != nil {
 		return err
 	}
 
-	// Trier par nom (001_, 002_, etc.)
-	var migrations []string
-	for _, f := range files {
-		if strings.HasSuffix(f.Name(), ".sql") {
-			migrations = append(migrations, f.Name())
-		}
-	}
-	sort.Strings(migrations)
-
 	// Appliquer les migrations manquantes
 	for _, mig := range migrations {
-		// Extraire le numéro de version (001_xxx.sql -> 1)
-		var migVersion int
-		fmt.Sscanf(mig, "%d_", &migVersion)
-
-		if migVersion > currentVersion {
-			migPath := filepath.Join(dbMigrationsPath, mig)
-			content, err := os.ReadFile(migPath)
+		if mig.Version > currentVersion {
+			content, err := os.ReadFile(filepath.Join(dbMigrationsPath, mig.Name))
 			if err != nil {
-				return fmt.Errorf("read %s: %w", mig, err)
+				return fmt.Errorf("read %s: %w", mig.Name, err)
 			}
 
-			fmt.Fprintf(os.Stderr, "[migrate] %s: applying %s\n", dbName, mig)
+			fmt.Fprintf(os.Stderr, "[migrate] %s: applying %s\n", dbName, mig.Name)
 
 			if _, err := db.Exec(string(content)); err != nil {
-				return fmt.Errorf("exec %s: %w", mig, err)
+				return fmt.Errorf("exec %s: %w", mig.Name, err)
 			}
 		}
 	}
@@ -114,6 +99,32 @@ func applyMigrations(dbName string, db *sql.DB, migrationsPath string, currentVe
 	return err
 }
 
+// migrationFile fichier de migration NNN_description.sql
+type migrationFile struct {
+	Name    string
+	Version int // NNN
+}
+
+// listMigrations liste les migrations d'un dossier, triées par nom (001_, 002_, etc.)
+func listMigrations(dir string) ([]migrationFile, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migrationFile
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".sql") {
+			mig := migrationFile{Name: f.Name()}
+			// Extraire le numéro de version (001_xxx.sql -> 1)
+			fmt.Sscanf(mig.Name, "%d_", &mig.Version)
+			migrations = append(migrations, mig)
+		}
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Name < migrations[j].Name })
+	return migrations, nil
+}
+
 // QuickHealthCheck vérifie rapidement la santé des bases (sans réparer)
 func (m *Manager) QuickHealthCheck() (healthy bool, issues []string) {
 	healthy = true
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecoverAndMigrateUpgradesV1(t *testing.T) {
+	m, err := NewManager(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	schemas := filepath.Join("..", "..", "schemas")
+	if err := m.InitSchemas(schemas); err != nil {
+		t.Fatal(err)
+	}
+
+	// Installation v1: tables ajoutées depuis absentes
+	if _, err := m.LifecycleTools.Exec(`DROP TABLE cdp_commands`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Output.Exec(`DROP TABLE llm_usage`); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range m.Named() {
+		if _, err := n.DB.Exec(`PRAGMA user_version = 1`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.RecoverAndMigrate(schemas); err != nil {
+		t.Fatalf("RecoverAndMigrate: %v", err)
+	}
+
+	tests := []struct {
+		db    *sql.DB
+		name  string
+		table string
+	}{
+		{m.LifecycleTools, "lifecycle-tools", "cdp_commands"},
+		{m.Output, "output", "llm_usage"},
+	}
+	for _, tt := range tests {
+		var count int
+		if err := tt.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, tt.table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("%s.%s missing after migration", tt.name, tt.table)
+		}
+		var version int
+		if err := tt.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+			t.Fatal(err)
+		}
+		if version != SchemaVersion {
+			t.Errorf("%s user_version = %d, want %d", tt.name, version, SchemaVersion)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Package database - Copie cohérente d'une base en cours d'utilisation
+package database
+
+import (
+	"fmt"
+	"os"
+)
+
+// Snapshot écrit dans dest une copie cohérente de la base path (VACUUM INTO)
+// La source est ouverte en lecture seule: la copie reflète un instant unique, WAL inclus,
+// sans arrêter le serveur ni bloquer ses écritures. dest ne doit pas exister
+func Snapshot(path, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("snapshot destination already exists: %s", dest)
+	}
+
+	db, err := OpenReadOnly(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("snapshot of %s failed: %w", path, err)
+	}
+	return nil
+}
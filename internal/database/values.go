@@ -0,0 +1,52 @@
+// Package database - Normalisation des valeurs SQLite pour la sortie JSON
+package database
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Blob représente un BLOB binaire (non UTF-8) encodé en base64 pour JSON
+type Blob struct {
+	Type     string `json:"type"`     // Toujours "blob"
+	Encoding string `json:"encoding"` // Toujours "base64"
+	Data     string `json:"data"`
+	Size     int    `json:"size"`
+}
+
+// JSONValue convertit une valeur scannée en valeur sérialisable sans perte
+// NULL reste nil (null JSON), entiers et réels gardent leur type,
+// les []byte UTF-8 deviennent des chaînes et les vrais binaires un Blob base64
+func JSONValue(v interface{}) interface{} {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return Blob{
+		Type:     "blob",
+		Encoding: "base64",
+		Data:     base64.StdEncoding.EncodeToString(b),
+		Size:     len(b),
+	}
+}
+
+// TextValue formate une valeur scannée pour un affichage texte (shell SQL)
+// NULL s'affiche NULL et les binaires en littéral hexadécimal X'..'
+func TextValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(val) {
+			return string(val)
+		}
+		return "X'" + hex.EncodeToString(val) + "'"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,238 @@
+// Package database - Écrivain unique par base dans le processus
+// SQLite n'accepte qu'un écrivain à la fois: sans verrou, les connexions du pool d'un *sql.DB
+// se disputent le verrou du fichier et une requête concurrente peut échouer en SQLITE_BUSY.
+// Les exécutions (Exec) et transactions en écriture d'une même base sont sérialisées ici;
+// les lectures (Query, transactions ReadOnly) restent concurrentes.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// writeLockTimeout attente max du verrou d'écriture, alignée sur busy_timeout
+const writeLockTimeout = 5 * time.Second
+
+// errWriteLockTimeout reprend le libellé SQLite pour rester reconnu comme erreur transitoire
+var errWriteLockTimeout = errors.New("database is locked (write lock timeout)")
+
+// writeLock verrou d'écriture d'une base (sémaphore de capacité 1, attente bornée)
+type writeLock chan struct{}
+
+// acquire prend le verrou, au plus writeLockTimeout ou jusqu'à l'expiration de ctx
+func (l writeLock) acquire(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	default:
+	}
+	timer := time.NewTimer(writeLockTimeout)
+	defer timer.Stop()
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return errWriteLockTimeout
+	}
+}
+
+func (l writeLock) release() { <-l }
+
+var (
+	writeLocksMu sync.Mutex
+	writeLocks   = map[string]writeLock{} // Par DSN: partagé par tous les *sql.DB ouverts sur le même fichier
+)
+
+// writeLockFor retourne le verrou d'écriture de la base dsn
+func writeLockFor(dsn string) writeLock {
+	writeLocksMu.Lock()
+	defer writeLocksMu.Unlock()
+	l, ok := writeLocks[dsn]
+	if !ok {
+		l = make(writeLock, 1)
+		writeLocks[dsn] = l
+	}
+	return l
+}
+
+// openLocked ouvre dsn avec le driver modernc, écritures sérialisées par writeLockFor(dsn)
+// pragmas est exécuté sur chaque nouvelle connexion du pool
+func openLocked(dsn string, pragmas []string) (*sql.DB, *lockedConnector) {
+	c := &lockedConnector{dsn: dsn, lock: writeLockFor(dsn), pragmas: pragmas}
+	return sql.OpenDB(c), c
+}
+
+// lockedConnector ouvre des connexions partageant le verrou d'écriture de leur base
+type lockedConnector struct {
+	dsn  string
+	lock writeLock
+
+	mu      sync.Mutex
+	pragmas []string // Pragmas par connexion (busy_timeout, wal_autocheckpoint...), dans l'ordre
+}
+
+// setPragmas remplace les pragmas appliqués aux connexions ouvertes ensuite
+func (c *lockedConnector) setPragmas(pragmas []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pragmas = pragmas
+}
+
+func (c *lockedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	raw, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	base, ok := raw.(sqliteConn)
+	if !ok {
+		raw.Close()
+		return nil, errors.New("sqlite driver connection lacks context methods")
+	}
+
+	c.mu.Lock()
+	pragmas := c.pragmas
+	c.mu.Unlock()
+	for _, pragma := range pragmas {
+		if _, err := base.ExecContext(ctx, pragma, nil); err != nil {
+			base.Close()
+			return nil, fmt.Errorf("failed to set pragma %s: %w", pragma, err)
+		}
+	}
+	return &lockedConn{sqliteConn: base, lock: c.lock}, nil
+}
+
+func (c *lockedConnector) Driver() driver.Driver { return sqliteDriver }
+
+// sqliteDriver instance enregistrée par modernc sous "sqlite" (porte les fonctions custom CDP)
+var sqliteDriver = func() driver.Driver {
+	db, _ := sql.Open("sqlite", "") // Aucune connexion ouverte: sert seulement à retrouver le driver
+	defer db.Close()
+	return db.Driver()
+}()
+
+// sqliteStmt méthodes du statement modernc utilisées par lockedStmt
+type sqliteStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+// sqliteConn méthodes de la connexion modernc utilisées par lockedConn
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+}
+
+// lockedConn prend le verrou pour chaque Exec hors transaction et pour toute la durée
+// d'une transaction en écriture (Begin -> Commit/Rollback)
+type lockedConn struct {
+	sqliteConn
+	lock writeLock
+	inTx bool // Verrou détenu par la transaction en cours
+}
+
+func (c *lockedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !c.inTx {
+		if err := c.lock.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer c.lock.release()
+	}
+	return c.sqliteConn.ExecContext(ctx, query, args)
+}
+
+func (c *lockedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	raw, err := c.sqliteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := raw.(sqliteStmt)
+	if !ok {
+		raw.Close()
+		return nil, errors.New("sqlite driver statement lacks context methods")
+	}
+	return &lockedStmt{sqliteStmt: s, conn: c}, nil
+}
+
+func (c *lockedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *lockedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		return c.sqliteConn.BeginTx(ctx, opts)
+	}
+	if err := c.lock.acquire(ctx); err != nil {
+		return nil, err
+	}
+	tx, err := c.sqliteConn.BeginTx(ctx, opts)
+	if err != nil {
+		c.lock.release()
+		return nil, err
+	}
+	c.inTx = true
+	return &lockedTx{Tx: tx, conn: c}, nil
+}
+
+func (c *lockedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// Close libère le verrou d'une transaction jamais terminée (connexion invalidée)
+func (c *lockedConn) Close() error {
+	if c.inTx {
+		c.inTx = false
+		c.lock.release()
+	}
+	return c.sqliteConn.Close()
+}
+
+// lockedTx libère le verrou de la connexion à la fin de la transaction
+type lockedTx struct {
+	driver.Tx
+	conn *lockedConn
+}
+
+func (t *lockedTx) Commit() error {
+	defer t.end()
+	return t.Tx.Commit()
+}
+
+func (t *lockedTx) Rollback() error {
+	defer t.end()
+	return t.Tx.Rollback()
+}
+
+func (t *lockedTx) end() {
+	if t.conn.inTx {
+		t.conn.inTx = false
+		t.conn.lock.release()
+	}
+}
+
+// lockedStmt sérialise l'exécution d'un statement préparé hors transaction
+type lockedStmt struct {
+	sqliteStmt
+	conn *lockedConn
+}
+
+func (s *lockedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if !s.conn.inTx {
+		if err := s.conn.lock.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer s.conn.lock.release()
+	}
+	return s.sqliteStmt.ExecContext(ctx, args)
+}
@@ -8,8 +8,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/horos/holow-mcp/internal/config"
 )
 
 // ConfigKey représente une clé de configuration système
@@ -24,11 +27,14 @@ const (
 	KeyPlatform        = "system.platform"
 	KeyArch            = "system.arch"
 	KeyDiscoveredAt    = "system.discovered_at"
+	KeyDiskPath        = "system.disk.path"
+	KeyDiskFreeMB      = "system.disk.free_mb"
 )
 
 // Discovery gère la détection des ressources système
 type Discovery struct {
-	db *sql.DB
+	db       *sql.DB
+	basePath string // Répertoire des bases (pour l'espace disque)
 }
 
 // New crée une nouvelle instance de Discovery
@@ -36,6 +42,11 @@ func New(db *sql.DB) *Discovery {
 	return &Discovery{db: db}
 }
 
+// SetBasePath définit le répertoire dont l'espace disque libre est surveillé
+func (d *Discovery) SetBasePath(path string) {
+	d.basePath = path
+}
+
 // Run exécute la découverte complète et stocke dans config
 func (d *Discovery) Run() error {
 	discoveries := make(map[string]string)
@@ -71,6 +82,14 @@ func (d *Discovery) Run() error {
 		discoveries[KeyGitPath] = gitPath
 	}
 
+	// Espace disque libre sur le système de fichiers des bases
+	if d.basePath != "" {
+		if freeMB, err := FreeDiskSpaceMB(d.basePath); err == nil {
+			discoveries[KeyDiskPath] = d.basePath
+			discoveries[KeyDiskFreeMB] = fmt.Sprintf("%d", freeMB)
+		}
+	}
+
 	// Stocker en base
 	return d.storeConfig(discoveries)
 }
@@ -161,28 +180,8 @@ func (d *Discovery) setupTempDir() string {
 	return mcpDir
 }
 
-// storeConfig stocke les découvertes dans la table config
+// storeConfig stocke les découvertes dans la table config (via le package config)
 func (d *Discovery) storeConfig(discoveries map[string]string) error {
-	// Utiliser une transaction
-	tx, err := d.db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Préparer l'upsert
-	stmt, err := tx.Prepare(`
-		INSERT INTO config (key, value, description, updated_at)
-		VALUES (?, ?, ?, strftime('%s', 'now'))
-		ON CONFLICT(key) DO UPDATE SET
-			value = excluded.value,
-			updated_at = excluded.updated_at
-	`)
-	if err != nil {
-		return fmt.Errorf("prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
 	// Descriptions pour chaque clé
 	descriptions := map[string]string{
 		KeyChromiumPath:  "Chemin vers l'exécutable Chromium/Chrome",
@@ -195,26 +194,26 @@ func (d *Discovery) storeConfig(discoveries map[string]string) error {
 		KeyPlatform:      "Système d'exploitation",
 		KeyArch:          "Architecture processeur",
 		KeyDiscoveredAt:  "Date de dernière découverte",
+		KeyDiskPath:      "Répertoire surveillé pour l'espace disque",
+		KeyDiskFreeMB:    "Espace disque libre (Mo)",
 	}
 
-	// Insérer chaque découverte
+	// Une seule transaction pour toutes les découvertes
+	entries := make([]config.Default, 0, len(discoveries))
 	for key, value := range discoveries {
 		desc := descriptions[key]
 		if desc == "" {
 			desc = "Auto-discovered"
 		}
-		if _, err := stmt.Exec(key, value, desc); err != nil {
-			return fmt.Errorf("insert %s: %w", key, err)
-		}
+		entries = append(entries, config.Default{Key: key, Value: value, ValueType: "string", Description: desc})
 	}
 
-	return tx.Commit()
+	return config.SaveAll(d.db, entries)
 }
 
 // Get récupère une valeur de configuration
 func (d *Discovery) Get(key string) (string, error) {
-	var value string
-	err := d.db.QueryRow("SELECT value FROM config WHERE key = ?", key).Scan(&value)
+	value, err := config.Get(d.db, key)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -251,6 +250,41 @@ func (d *Discovery) GetDefaultPort() int {
 	return port
 }
 
+// Info résultats de la découverte, lus depuis la table config
+type Info struct {
+	Platform      string `json:"platform"`
+	Arch          string `json:"arch"`
+	ChromiumPath  string `json:"chromium_path"`
+	ChromiumFound bool   `json:"chromium_found"`
+	UserDataDir   string `json:"user_data_dir"`
+	DefaultPort   int    `json:"default_port"`
+	SQLite3Path   string `json:"sqlite3_path"`
+	GitPath       string `json:"git_path"`
+	TempDir       string `json:"temp_dir"`
+	DiskPath      string `json:"disk_path"`
+	DiskFreeMB    uint64 `json:"disk_free_mb"`
+	DiscoveredAt  string `json:"discovered_at"` // Vide si la découverte n'a jamais tourné
+}
+
+// Info retourne les ressources découvertes (valeurs par défaut pour les clés absentes)
+func (d *Discovery) Info() Info {
+	freeMB, _ := strconv.ParseUint(d.GetWithDefault(KeyDiskFreeMB, "0"), 10, 64)
+	return Info{
+		Platform:      d.GetWithDefault(KeyPlatform, ""),
+		Arch:          d.GetWithDefault(KeyArch, ""),
+		ChromiumPath:  d.GetChromiumPath(),
+		ChromiumFound: d.IsChromiumAvailable(),
+		UserDataDir:   d.GetUserDataDir(),
+		DefaultPort:   d.GetDefaultPort(),
+		SQLite3Path:   d.GetWithDefault(KeySQLite3Path, ""),
+		GitPath:       d.GetWithDefault(KeyGitPath, ""),
+		TempDir:       d.GetWithDefault(KeyTempDir, ""),
+		DiskPath:      d.GetWithDefault(KeyDiskPath, ""),
+		DiskFreeMB:    freeMB,
+		DiscoveredAt:  d.GetWithDefault(KeyDiscoveredAt, ""),
+	}
+}
+
 // IsChromiumAvailable vérifie si Chromium est disponible
 func (d *Discovery) IsChromiumAvailable() bool {
 	value := d.GetWithDefault(KeyChromiumFound, "false")
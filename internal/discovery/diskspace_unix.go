@@ -0,0 +1,14 @@
+//go:build !windows
+
+package discovery
+
+import "syscall"
+
+// FreeDiskSpaceMB retourne l'espace libre (en Mo) du système de fichiers contenant path
+func FreeDiskSpaceMB(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize) / (1024 * 1024), nil
+}
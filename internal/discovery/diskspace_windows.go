@@ -0,0 +1,32 @@
+//go:build windows
+
+package discovery
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// FreeDiskSpaceMB retourne l'espace libre (en Mo) du volume contenant path
+func FreeDiskSpaceMB(path string) (uint64, error) {
+	kernel32, err := syscall.LoadDLL("kernel32.dll")
+	if err != nil {
+		return 0, err
+	}
+	proc, err := kernel32.FindProc("GetDiskFreeSpaceExW")
+	if err != nil {
+		return 0, err
+	}
+
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeAvailable uint64
+	ret, _, callErr := proc.Call(uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&freeAvailable)), 0, 0)
+	if ret == 0 {
+		return 0, callErr
+	}
+	return freeAvailable / (1024 * 1024), nil
+}
@@ -0,0 +1,84 @@
+// Package initcli - Résolution du chemin de base commun à tous les modes du CLI
+package initcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BasePathMode indique ce que le mode du CLI fera du dossier de base
+type BasePathMode int
+
+const (
+	// BasePathCreate crée le dossier s'il manque (init, import de bundle)
+	BasePathCreate BasePathMode = iota
+	// BasePathWrite exige un dossier existant accessible en écriture (serveur, sql)
+	BasePathWrite
+	// BasePathReadOnly exige seulement un dossier existant, sans rien y écrire (-config, -healthcheck...)
+	BasePathReadOnly
+)
+
+// ResolveBasePath résout le chemin de base utilisé par tous les modes (init, serveur, sql, config)
+// Ordre: path explicite, sinon BasePath du config.json par défaut, sinon ~/.holow-mcp
+// Le chemin retourné est absolu et hors des dossiers système; mode décide s'il peut être créé
+// et si l'accès en écriture est vérifié
+func ResolveBasePath(path string, mode BasePathMode) (string, error) {
+	if path == "" {
+		path = getDefaultBasePath()
+		if ConfigExists(path) {
+			if cfg, err := LoadAppConfig(path); err == nil && cfg.BasePath != "" {
+				path = cfg.BasePath
+			}
+		}
+	}
+
+	absPath, err := normalizePath(path)
+	if err != nil {
+		return "", err
+	}
+	if err := checkSafePath(absPath); err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(absPath)
+	switch {
+	case os.IsNotExist(err) && mode != BasePathCreate:
+		return "", fmt.Errorf("dossier de base introuvable: %s (lancez d'abord: holow-mcp -setup)", absPath)
+	case err != nil && !os.IsNotExist(err):
+		return "", fmt.Errorf("dossier de base inaccessible: %w", err)
+	case err == nil && !info.IsDir():
+		return "", fmt.Errorf("le chemin de base n'est pas un dossier: %s", absPath)
+	}
+
+	// Les modes en lecture seule ne créent ni ne sondent rien dans le dossier
+	if mode == BasePathReadOnly {
+		return absPath, nil
+	}
+	if err := validatePath(absPath); err != nil {
+		return "", err
+	}
+	return absPath, nil
+}
+
+// normalizePath développe ~ et rend le chemin absolu
+func normalizePath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("chemin de base vide")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("impossible de développer ~: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("chemin invalide %s: %w", path, err)
+	}
+	return absPath, nil
+}
@@ -0,0 +1,388 @@
+// Package initcli - Export/import d'une installation complète (flags -export-bundle, -import-bundle)
+package initcli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/database"
+)
+
+// bundleFormat version du format d'archive produit par ExportBundle
+const bundleFormat = 1
+
+const manifestFileName = "manifest.json"
+
+// BundleManifest description d'un bundle: origine et empreinte de chaque fichier
+type BundleManifest struct {
+	Format            int          `json:"format"`
+	CreatedAt         int64        `json:"created_at"`
+	SourcePath        string       `json:"source_path"`
+	SchemaVersion     int          `json:"schema_version"`
+	CredentialsDB     string       `json:"credentials_db"`
+	CredentialBackend string       `json:"credential_backend,omitempty"`
+	Files             []BundleFile `json:"files"`
+}
+
+// BundleFile fichier d'un bundle (bases et config.json)
+type BundleFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ImportResult résultat d'un ImportBundle
+type ImportResult struct {
+	Manifest      *BundleManifest
+	Credentials   int      // Clés API rechiffrées (sqlite) ou recopiées (trousseau) pour le nouveau chemin
+	ClientConfigs []string // Configs MCP des clients repointées vers le nouveau chemin
+}
+
+// ExportBundle écrit dans file une archive tar.gz portable de l'installation basePath:
+// manifest.json, config.json et une copie cohérente de chaque base (database.Snapshot),
+// utilisable pendant que le serveur tourne
+func ExportBundle(basePath, file string) (*BundleManifest, error) {
+	if !ConfigExists(basePath) {
+		return nil, fmt.Errorf("aucune installation dans %s (config.json absent)", basePath)
+	}
+	cfg, err := LoadAppConfig(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp("", "holow-bundle-")
+	if err != nil {
+		return nil, fmt.Errorf("impossible de créer le dossier temporaire: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest := &BundleManifest{
+		Format:            bundleFormat,
+		CreatedAt:         time.Now().Unix(),
+		SourcePath:        basePath,
+		SchemaVersion:     database.SchemaVersion,
+		CredentialsDB:     cfg.CredentialsDB,
+		CredentialBackend: cfg.CredentialBackend,
+	}
+
+	// config.json tel quel, puis un snapshot de chaque base
+	paths := []string{filepath.Join(basePath, configFileName)}
+	dbFiles, err := filepath.Glob(filepath.Join(basePath, "*.db"))
+	if err != nil {
+		return nil, err
+	}
+	for _, dbFile := range dbFiles {
+		snapshot := filepath.Join(staging, filepath.Base(dbFile))
+		if err := database.Snapshot(dbFile, snapshot); err != nil {
+			return nil, fmt.Errorf("erreur snapshot %s: %w", filepath.Base(dbFile), err)
+		}
+		paths = append(paths, snapshot)
+	}
+
+	for _, p := range paths {
+		entry, err := describeFile(p)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	out, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("impossible de créer le bundle: %w", err)
+	}
+	if err := writeBundle(out, manifest, paths); err != nil {
+		out.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// writeBundle écrit l'archive: manifest.json en tête, puis les fichiers
+func writeBundle(w io.Writer, manifest *BundleManifest, paths []string) error {
+	gzWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{
+		Name:    manifestFileName,
+		Size:    int64(len(data)),
+		Mode:    0600,
+		ModTime: time.Unix(manifest.CreatedAt, 0),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		if err := addFileToTar(tarWriter, p, filepath.Base(p)); err != nil {
+			return fmt.Errorf("erreur ajout %s: %w", filepath.Base(p), err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzWriter.Close()
+}
+
+// describeFile calcule taille et SHA-256 d'un fichier du bundle
+func describeFile(path string) (BundleFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return BundleFile{}, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return BundleFile{}, err
+	}
+	return BundleFile{Name: filepath.Base(path), Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// ImportBundle restaure un bundle dans destPath (qui ne doit pas contenir d'installation),
+// vérifie les empreintes du manifest, met à jour config.json, rechiffre les clés API
+// (la clé de chiffrement dérive du chemin de base) et repointe les configs MCP des clients
+func ImportBundle(file, destPath string) (*ImportResult, error) {
+	if ConfigExists(destPath) {
+		return nil, fmt.Errorf("une installation existe déjà dans %s", destPath)
+	}
+	if existing, _ := filepath.Glob(filepath.Join(destPath, "*.db")); len(existing) > 0 {
+		return nil, fmt.Errorf("%s contient déjà des bases (%s)", destPath, filepath.Base(existing[0]))
+	}
+	if err := os.MkdirAll(destPath, 0700); err != nil {
+		return nil, fmt.Errorf("impossible de créer le dossier: %w", err)
+	}
+
+	written, manifest, err := extractBundle(file, destPath)
+	result := &ImportResult{Manifest: manifest}
+	if err == nil {
+		err = restoreBundle(result, destPath)
+	}
+	if err != nil {
+		for _, p := range written {
+			os.Remove(p)
+		}
+		return nil, err
+	}
+
+	if manifest.SourcePath != destPath {
+		if result.ClientConfigs, err = RepointMCPConfigs(manifest.SourcePath, destPath); err != nil {
+			return result, fmt.Errorf("mise à jour des configs MCP: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// restoreBundle vérifie les fichiers extraits, migre les credentials puis réécrit config.json pour destPath
+func restoreBundle(result *ImportResult, destPath string) error {
+	if err := verifyBundle(result.Manifest, destPath); err != nil {
+		return err
+	}
+
+	cfg, err := LoadAppConfig(destPath) // BasePath forcé sur destPath
+	if err != nil {
+		return err
+	}
+	if result.Manifest.SourcePath != destPath {
+		if result.Credentials, err = moveCredentials(cfg, result.Manifest.SourcePath); err != nil {
+			return fmt.Errorf("migration des credentials: %w", err)
+		}
+	}
+	return SaveAppConfig(cfg)
+}
+
+// extractBundle extrait les fichiers de l'archive à plat dans destPath et retourne son manifest
+func extractBundle(file, destPath string) ([]string, *BundleManifest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	gzReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bundle invalide: %w", err)
+	}
+	defer gzReader.Close()
+
+	var written []string
+	var manifest *BundleManifest
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, nil, fmt.Errorf("bundle invalide: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || header.Name != filepath.Base(header.Name) || strings.HasPrefix(header.Name, ".") {
+			return written, nil, fmt.Errorf("entrée inattendue dans le bundle: %s", header.Name)
+		}
+
+		if header.Name == manifestFileName {
+			manifest = &BundleManifest{}
+			if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+				return written, nil, fmt.Errorf("manifest invalide: %w", err)
+			}
+			if manifest.Format > bundleFormat {
+				return written, nil, fmt.Errorf("format de bundle non supporté: %d (max %d)", manifest.Format, bundleFormat)
+			}
+			continue
+		}
+
+		destFile := filepath.Join(destPath, header.Name)
+		out, err := os.OpenFile(destFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return written, nil, err
+		}
+		written = append(written, destFile)
+		_, err = io.Copy(out, tarReader)
+		out.Close()
+		if err != nil {
+			return written, nil, err
+		}
+	}
+
+	if manifest == nil {
+		return written, nil, fmt.Errorf("bundle invalide: %s absent", manifestFileName)
+	}
+	return written, manifest, nil
+}
+
+// verifyBundle contrôle la présence et l'empreinte de chaque fichier listé par le manifest
+func verifyBundle(manifest *BundleManifest, destPath string) error {
+	hasConfig := false
+	for _, expected := range manifest.Files {
+		got, err := describeFile(filepath.Join(destPath, expected.Name))
+		if err != nil {
+			return fmt.Errorf("fichier manquant dans le bundle: %s", expected.Name)
+		}
+		if got.SHA256 != expected.SHA256 || got.Size != expected.Size {
+			return fmt.Errorf("empreinte invalide pour %s (bundle corrompu)", expected.Name)
+		}
+		hasConfig = hasConfig || expected.Name == configFileName
+	}
+	if !hasConfig {
+		return fmt.Errorf("bundle invalide: %s absent", configFileName)
+	}
+	return nil
+}
+
+// moveCredentials rend les clés API lisibles depuis le nouveau chemin de base:
+// rechiffrement dans la base credentials (sqlite) ou copie sous le nouveau service (trousseau)
+func moveCredentials(cfg *AppConfig, oldPath string) (int, error) {
+	if cfg.CredentialBackend == BackendKeychain {
+		oldStore, err := newCredentialStore(BackendKeychain, oldPath, cfg.CredentialsDB)
+		if err != nil {
+			return 0, err
+		}
+		newStore, err := newCredentialStore(BackendKeychain, cfg.BasePath, cfg.CredentialsDB)
+		if err != nil {
+			return 0, err
+		}
+		providers, err := oldStore.List()
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range providers {
+			apiKey, err := oldStore.Get(p)
+			if err != nil {
+				return 0, err
+			}
+			if err := newStore.Set(p, apiKey); err != nil {
+				return 0, err
+			}
+		}
+		return len(providers), nil
+	}
+
+	if !cfg.CredentialsAvailable() {
+		return 0, nil
+	}
+	return rekeyCredentials(cfg.CredentialsDBPath(), oldPath, cfg.BasePath, cfg.CredentialsDB)
+}
+
+// rekeyCredentials rechiffre les clés API de la base dbPath, dérivées de oldPath, avec la clé de newPath
+func rekeyCredentials(dbPath, oldPath, newPath, credentialsDB string) (int, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var salt []byte
+	if err := db.QueryRow(`SELECT salt FROM encryption_meta WHERE id = 1`).Scan(&salt); err != nil {
+		return 0, fmt.Errorf("sel non trouvé: %w", err)
+	}
+	oldKey := deriveKey(oldPath, credentialsDB, salt)
+	newKey := deriveKey(newPath, credentialsDB, salt)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT provider, api_key_encrypted, iv FROM credentials`)
+	if err != nil {
+		return 0, err
+	}
+	type credential struct {
+		provider      string
+		encrypted, iv []byte
+	}
+	var creds []credential
+	for rows.Next() {
+		var c credential
+		if err := rows.Scan(&c.provider, &c.encrypted, &c.iv); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		creds = append(creds, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range creds {
+		plaintext, err := decrypt(c.encrypted, oldKey, c.iv)
+		if err != nil {
+			return 0, fmt.Errorf("déchiffrement échoué pour %s: %w", c.provider, err)
+		}
+		encrypted, iv, err := encrypt(plaintext, newKey)
+		if err != nil {
+			return 0, fmt.Errorf("chiffrement échoué pour %s: %w", c.provider, err)
+		}
+		if _, err := tx.Exec(`UPDATE credentials SET api_key_encrypted = ?, iv = ? WHERE provider = ?`,
+			encrypted, iv, c.provider); err != nil {
+			return 0, err
+		}
+	}
+	return len(creds), tx.Commit()
+}
@@ -10,11 +10,17 @@ import (
 
 // AppConfig configuration globale de l'application (fichier config.json)
 type AppConfig struct {
-	BasePath       string `json:"base_path"`
-	CredentialsDB  string `json:"credentials_db"`  // Nom de la base credentials (sans extension)
-	BackupEnabled  bool   `json:"backup_enabled"`
-	BackupMaxCount int    `json:"backup_max_count"`
-	DebugPort      int    `json:"debug_port"`      // Port CDP par défaut
+	BasePath          string `json:"base_path"`
+	CredentialsDB     string `json:"credentials_db"`               // Nom de la base credentials (sans extension)
+	CredentialBackend string `json:"credential_backend,omitempty"` // sqlite (défaut) ou keychain
+	BackupEnabled     bool   `json:"backup_enabled"`
+	BackupMaxCount    int    `json:"backup_max_count"`
+	DebugPort         int    `json:"debug_port"` // Port CDP par défaut
+
+	// Captures d'écran (vide/0 = valeurs par défaut du browser)
+	ScreenshotDir         string `json:"screenshot_dir,omitempty"`
+	ScreenshotMaxFiles    int    `json:"screenshot_max_files,omitempty"`
+	ScreenshotMaxAgeHours int    `json:"screenshot_max_age_hours,omitempty"`
 }
 
 const configFileName = "config.json"
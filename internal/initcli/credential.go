@@ -0,0 +1,72 @@
+// Package initcli - Mise à jour ciblée d'un credential (sans relancer le setup)
+package initcli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findProvider retourne le provider connu nommé name
+func findProvider(name string) (Provider, error) {
+	names := make([]string, 0, len(defaultProviders))
+	for _, p := range defaultProviders {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return Provider{}, fmt.Errorf("provider inconnu: %s (attendu: %s)", name, strings.Join(names, ", "))
+}
+
+// ReadAPIKey lit la nouvelle clé d'un provider: variable d'environnement du provider si définie,
+// sinon saisie sur stdin sans écho. source décrit l'origine de la clé
+func ReadAPIKey(provider string) (key, source string, err error) {
+	p, err := findProvider(provider)
+	if err != nil {
+		return "", "", err
+	}
+	if envVal := strings.TrimSpace(os.Getenv(p.EnvVar)); envVal != "" {
+		return envVal, "$" + p.EnvVar, nil
+	}
+
+	fmt.Printf("Nouvelle clé API %s: ", p.Description)
+	key, err = readSecret(bufio.NewReader(os.Stdin))
+	if err != nil {
+		return "", "", fmt.Errorf("lecture de la clé: %w", err)
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("clé vide, rien n'a été modifié")
+	}
+	return key, "stdin", nil
+}
+
+// UpdateCredential chiffre et enregistre la clé d'un seul provider, puis retourne son nouveau hint
+// Les autres providers et les configurations des clients IA ne sont pas modifiés
+func UpdateCredential(basePath, credentialsDB, provider, apiKey string) (string, error) {
+	if _, err := findProvider(provider); err != nil {
+		return "", err
+	}
+	dbPath := filepath.Join(basePath, fmt.Sprintf("holow-mcp.%s.db", credentialsDB))
+	if _, err := os.Stat(dbPath); err != nil {
+		return "", fmt.Errorf("base credentials introuvable: %s (lancez d'abord: holow-mcp -setup)", dbPath)
+	}
+
+	appCfg, err := LoadAppConfig(basePath)
+	if err != nil {
+		return "", err
+	}
+
+	config := &Config{
+		BasePath:          basePath,
+		CredentialsDB:     credentialsDB,
+		CredentialBackend: appCfg.CredentialBackend,
+		Providers:         map[string]string{provider: apiKey},
+	}
+	if err := saveCredentials(config); err != nil {
+		return "", err
+	}
+	return CredentialHint(basePath, credentialsDB, provider), nil
+}
@@ -0,0 +1,177 @@
+// Package initcli - Backends de stockage des credentials (SQLite chiffré ou trousseau du système)
+package initcli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Backends de stockage des clés API (champ credential_backend de config.json)
+const (
+	BackendSQLite   = "sqlite"   // AES-256-GCM dans la base credentials (défaut)
+	BackendKeychain = "keychain" // Trousseau du système: Keychain macOS, Secret Service, Credential Manager Windows
+)
+
+// CredentialStore stocke les clés API par provider
+type CredentialStore interface {
+	Get(provider string) (string, error)
+	Set(provider, apiKey string) error
+	List() ([]string, error)
+	Hint(provider string) string
+}
+
+// newCredentialStore retourne le backend nommé pour la base credentials de basePath
+func newCredentialStore(backend, basePath, credentialsDB string) (CredentialStore, error) {
+	switch backend {
+	case "", BackendSQLite:
+		return &sqliteStore{basePath: basePath, credentialsDB: credentialsDB}, nil
+	case BackendKeychain:
+		return &keychainStore{service: keychainService(basePath, credentialsDB)}, nil
+	default:
+		return nil, fmt.Errorf("backend credentials inconnu: %s (attendu: %s, %s)", backend, BackendSQLite, BackendKeychain)
+	}
+}
+
+// storeFor retourne le backend configuré dans le config.json de basePath
+func storeFor(basePath, credentialsDB string) (CredentialStore, error) {
+	cfg, err := LoadAppConfig(basePath)
+	if err != nil {
+		return nil, err
+	}
+	return newCredentialStore(cfg.CredentialBackend, basePath, credentialsDB)
+}
+
+// GetCredential récupère une clé API depuis le backend configuré
+func GetCredential(basePath, credentialsDB, provider string) (string, error) {
+	store, err := storeFor(basePath, credentialsDB)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(provider)
+}
+
+// ListProviders liste les providers configurés
+func ListProviders(basePath, credentialsDB string) ([]string, error) {
+	store, err := storeFor(basePath, credentialsDB)
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+// CredentialHint retourne un hint pour une clé API (pour affichage)
+func CredentialHint(basePath, credentialsDB, provider string) string {
+	store, err := storeFor(basePath, credentialsDB)
+	if err != nil {
+		return ""
+	}
+	return store.Hint(provider)
+}
+
+// keyHint retourne les 4 derniers caractères d'une clé API
+func keyHint(apiKey string) string {
+	if len(apiKey) > 4 {
+		return "..." + apiKey[len(apiKey)-4:]
+	}
+	return ""
+}
+
+// keychainIndex compte du trousseau listant les providers et leurs hints
+// (les trousseaux ne permettent pas d'énumérer les comptes d'un service de façon portable)
+const keychainIndex = "_providers"
+
+// keychainStore stocke chaque clé API dans le trousseau du système (service par installation, compte = provider)
+type keychainStore struct {
+	service string
+}
+
+// keychainService nom du service, propre à chaque installation
+func keychainService(basePath, credentialsDB string) string {
+	return "holow-mcp:" + filepath.Join(basePath, credentialsDB)
+}
+
+// CheckKeychain vérifie que le trousseau du système est accessible (écriture, lecture et suppression d'une entrée de test)
+func CheckKeychain() error {
+	const service, account = "holow-mcp", "_probe"
+	if err := keyring.Set(service, account, "probe"); err != nil {
+		return fmt.Errorf("trousseau indisponible: %w", err)
+	}
+	defer keyring.Delete(service, account)
+	if _, err := keyring.Get(service, account); err != nil {
+		return fmt.Errorf("trousseau illisible: %w", err)
+	}
+	return nil
+}
+
+func (k *keychainStore) Get(provider string) (string, error) {
+	secret, err := keyring.Get(k.service, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("credential non trouvé: %s", provider)
+	}
+	if err != nil {
+		return "", fmt.Errorf("trousseau: %w", err)
+	}
+	return secret, nil
+}
+
+func (k *keychainStore) Set(provider, apiKey string) error {
+	if err := keyring.Set(k.service, provider, apiKey); err != nil {
+		return fmt.Errorf("sauvegarde échouée pour %s: trousseau: %w", provider, err)
+	}
+
+	index, err := k.index()
+	if err != nil {
+		return err
+	}
+	index[provider] = keyHint(apiKey)
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(k.service, keychainIndex, string(data)); err != nil {
+		return fmt.Errorf("mise à jour de l'index du trousseau: %w", err)
+	}
+	return nil
+}
+
+func (k *keychainStore) List() ([]string, error) {
+	index, err := k.index()
+	if err != nil {
+		return nil, err
+	}
+	providers := make([]string, 0, len(index))
+	for p := range index {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	return providers, nil
+}
+
+func (k *keychainStore) Hint(provider string) string {
+	index, err := k.index()
+	if err != nil {
+		return ""
+	}
+	return index[provider]
+}
+
+// index lit l'index provider -> hint (vide s'il n'existe pas encore)
+func (k *keychainStore) index() (map[string]string, error) {
+	index := map[string]string{}
+	data, err := keyring.Get(k.service, keychainIndex)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("trousseau: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &index); err != nil {
+		return nil, fmt.Errorf("index du trousseau invalide: %w", err)
+	}
+	return index, nil
+}
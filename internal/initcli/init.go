@@ -7,6 +7,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/hex"
 	"fmt"
@@ -21,9 +22,10 @@ import (
 
 // Config représente la configuration d'initialisation
 type Config struct {
-	BasePath      string
-	CredentialsDB string
-	Providers     map[string]string // provider -> api_key (non chiffré en mémoire)
+	BasePath          string
+	CredentialsDB     string
+	CredentialBackend string            // BackendSQLite (défaut) ou BackendKeychain
+	Providers         map[string]string // provider -> api_key (non chiffré en mémoire)
 }
 
 // Provider représente un fournisseur d'API
@@ -40,11 +42,46 @@ var defaultProviders = []Provider{
 	{"github", "GITHUB_TOKEN", "GitHub"},
 }
 
+// Options configure l'exécution du setup interactif
+type Options struct {
+	DryRun bool // Poser les mêmes questions sans rien écrire, afficher les actions prévues
+}
+
 // Run exécute le CLI d'initialisation interactif
 func Run() (*Config, error) {
+	return RunWithOptions(Options{})
+}
+
+// apply exécute fn, ou se contente d'annoncer l'action en dry-run
+func apply(dryRun bool, description string, fn func() error) error {
+	if dryRun {
+		printDryRun(description)
+		return nil
+	}
+	return fn()
+}
+
+// printDryRun annonce une action sautée en dry-run
+func printDryRun(description string) {
+	fmt.Printf("    [DRY-RUN] would: %s\n", description)
+}
+
+// reportOK affiche un succès [OK], sauf en dry-run où l'action n'a pas eu lieu
+func reportOK(dryRun bool, format string, args ...interface{}) {
+	if dryRun {
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// RunWithOptions exécute le CLI d'initialisation interactif selon opts
+func RunWithOptions(opts Options) (*Config, error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	printBanner()
+	if opts.DryRun {
+		fmt.Println("\n[DRY-RUN] Aucune modification ne sera effectuée")
+	}
 
 	// Étape 1: Détecter et valider installation existante
 	defaultPath := getDefaultBasePath()
@@ -70,10 +107,13 @@ func Run() (*Config, error) {
 		// Nettoyer les WAL orphelins automatiquement
 		if validation.HasOrphanWAL {
 			fmt.Println("\n[*] Nettoyage des fichiers WAL/SHM orphelins...")
-			cleaned, _ := database.CleanOrphanWAL(defaultPath)
-			for _, f := range cleaned {
-				fmt.Printf("    Supprimé: %s\n", f)
-			}
+			apply(opts.DryRun, "suppression des fichiers WAL/SHM orphelins de "+defaultPath, func() error {
+				cleaned, err := database.CleanOrphanWAL(defaultPath)
+				for _, f := range cleaned {
+					fmt.Printf("    Supprimé: %s\n", f)
+				}
+				return err
+			})
 		}
 
 		// Proposer les options
@@ -95,10 +135,13 @@ func Run() (*Config, error) {
 				// Marquer les bases comme HOLOW si pas déjà fait
 				for _, db := range validation.Databases {
 					if db.Exists && !db.IsHolow {
-						database.SetApplicationID(db.Path)
+						path := db.Path
+						apply(opts.DryRun, "marquage HOLOW de "+path, func() error {
+							return database.SetApplicationID(path)
+						})
 					}
 				}
-				fmt.Println("\n[OK] Bases conservées et marquées")
+				reportOK(opts.DryRun, "\n[OK] Bases conservées et marquées\n")
 				config = &Config{BasePath: defaultPath, CredentialsDB: "credentials", Providers: make(map[string]string)}
 			} else {
 				// Tenter de réparer
@@ -109,13 +152,19 @@ func Run() (*Config, error) {
 						fmt.Printf("    [X] %s: impossible de réparer (corrompue)\n", db.Name)
 						repairOK = false
 					} else if db.Exists {
-						database.SetApplicationID(db.Path)
-						fmt.Printf("    [OK] %s: marquée HOLOW\n", db.Name)
+						path := db.Path
+						apply(opts.DryRun, "marquage HOLOW de "+path, func() error {
+							return database.SetApplicationID(path)
+						})
+						reportOK(opts.DryRun, "    [OK] %s: marquée HOLOW\n", db.Name)
 					}
 				}
 				if !repairOK {
 					if promptYesNo(reader, "Bases corrompues détectées. Purger et réinstaller?", true) {
-						purgeInstall(defaultPath)
+						apply(opts.DryRun, "purge des bases de "+defaultPath, func() error {
+							purgeInstall(defaultPath)
+							return nil
+						})
 						config = &Config{BasePath: defaultPath, Providers: make(map[string]string)}
 					} else {
 						return nil, fmt.Errorf("bases corrompues non réparables")
@@ -125,7 +174,10 @@ func Run() (*Config, error) {
 				}
 			}
 		case "2":
-			purgeInstall(defaultPath)
+			apply(opts.DryRun, "purge des bases de "+defaultPath, func() error {
+				purgeInstall(defaultPath)
+				return nil
+			})
 			config = &Config{BasePath: defaultPath, Providers: make(map[string]string)}
 		case "3":
 			return nil, fmt.Errorf("annulé par l'utilisateur")
@@ -142,9 +194,20 @@ func Run() (*Config, error) {
 		if input == "" {
 			input = defaultPath
 		}
+		input, err := normalizePath(input)
+		if err != nil {
+			return nil, fmt.Errorf("chemin invalide: %w", err)
+		}
 
 		// Valider le chemin
-		if err := validatePath(input); err != nil {
+		if opts.DryRun {
+			if err := checkSafePath(input); err != nil {
+				return nil, fmt.Errorf("chemin invalide: %w", err)
+			}
+			if _, err := os.Stat(input); os.IsNotExist(err) {
+				printDryRun("création du dossier " + input)
+			}
+		} else if err := validatePath(input); err != nil {
 			return nil, fmt.Errorf("chemin invalide: %w", err)
 		}
 		config.BasePath = input
@@ -155,6 +218,9 @@ func Run() (*Config, error) {
 		config.CredentialsDB = "credentials"
 	}
 
+	// Étape 3b: Stockage des clés API
+	config.CredentialBackend = chooseCredentialBackend(reader, config.BasePath, opts.DryRun)
+
 	// Étape 4: Setup credentials
 	fmt.Println("\n--- Configuration des API Keys ---")
 	for _, p := range defaultProviders {
@@ -164,30 +230,35 @@ func Run() (*Config, error) {
 	// Étape 5: Créer les bases si nécessaire
 	if !hasExisting {
 		fmt.Println("\n[*] Création des bases de données...")
-		if err := createCredentialsDB(config); err != nil {
+		credsPath := filepath.Join(config.BasePath, fmt.Sprintf("holow-mcp.%s.db", config.CredentialsDB))
+		if err := apply(opts.DryRun, "création de la base credentials "+credsPath, func() error {
+			return createCredentialsDB(config)
+		}); err != nil {
 			return nil, fmt.Errorf("erreur création credentials DB: %w", err)
 		}
-		fmt.Println("[OK] Base credentials créée")
+		reportOK(opts.DryRun, "[OK] Base credentials créée\n")
 	}
 
 	// Sauvegarder les credentials
 	if len(config.Providers) > 0 {
 		fmt.Println("\n[*] Sauvegarde des credentials...")
-		if err := saveCredentials(config); err != nil {
+		if err := apply(opts.DryRun, fmt.Sprintf("chiffrement et enregistrement de %d credential(s)", len(config.Providers)), func() error {
+			return saveCredentials(config)
+		}); err != nil {
 			return nil, fmt.Errorf("erreur sauvegarde credentials: %w", err)
 		}
-		fmt.Println("[OK] Credentials sauvegardées")
+		reportOK(opts.DryRun, "[OK] Credentials sauvegardées\n")
 	}
 
 	// Étape 6: Configuration MCP pour les AI clients
 	if promptYesNo(reader, "\nConfigurer les AI clients (Claude Code, Gemini CLI, OpenCode)?", true) {
-		if err := RunMCPConfigSetup(reader, config.BasePath); err != nil {
+		if err := runMCPConfigSetup(reader, config.BasePath, opts.DryRun); err != nil {
 			fmt.Printf("\n[!] Erreur configuration MCP: %v\n", err)
 		}
 	}
 
 	// Résumé
-	printSummary(config)
+	printSummary(config, opts.DryRun)
 
 	return config, nil
 }
@@ -269,11 +340,17 @@ func validatePath(path string) error {
 	}
 	os.Remove(testFile)
 
-	// Vérifier que ce n'est pas un chemin dangereux
+	return checkSafePath(path)
+}
+
+// checkSafePath vérifie que ce n'est pas un chemin dangereux (sans rien créer)
+// Les dossiers sensibles sont refusés avec tout leur contenu, après nettoyage du chemin
+func checkSafePath(path string) error {
 	absPath, _ := filepath.Abs(path)
+	absPath = filepath.Clean(absPath)
 	dangerous := []string{"/tmp", "/var/tmp", "/dev", "/proc", "/sys"}
 	for _, d := range dangerous {
-		if absPath == d {
+		if absPath == d || strings.HasPrefix(absPath, d+string(filepath.Separator)) {
 			return fmt.Errorf("chemin non sécurisé: %s", absPath)
 		}
 	}
@@ -281,6 +358,33 @@ func validatePath(path string) error {
 	return nil
 }
 
+// chooseCredentialBackend demande où stocker les clés API (base SQLite chiffrée ou trousseau du système)
+// Le choix par défaut est celui du config.json existant; retombe sur SQLite si le trousseau est indisponible
+func chooseCredentialBackend(reader *bufio.Reader, basePath string, dryRun bool) string {
+	defaultChoice := "1"
+	if cfg, err := LoadAppConfig(basePath); err == nil && cfg.CredentialBackend == BackendKeychain {
+		defaultChoice = "2"
+	}
+
+	fmt.Println("\n--- Stockage des API Keys ---")
+	fmt.Println("    1. Base SQLite chiffrée (AES-256-GCM)")
+	fmt.Println("    2. Trousseau du système (Keychain, Secret Service, Credential Manager)")
+	if promptChoice(reader, "Choix", []string{"1", "2"}, defaultChoice) == "1" {
+		return BackendSQLite
+	}
+
+	if dryRun {
+		printDryRun("vérification de l'accès au trousseau")
+		return BackendKeychain
+	}
+	if err := CheckKeychain(); err != nil {
+		fmt.Printf("    [!] %v\n", err)
+		fmt.Println("    [!] Repli sur la base SQLite chiffrée")
+		return BackendSQLite
+	}
+	return BackendKeychain
+}
+
 func setupProvider(reader *bufio.Reader, config *Config, p Provider) {
 	// Vérifier variable d'environnement
 	if envVal := os.Getenv(p.EnvVar); envVal != "" {
@@ -294,8 +398,7 @@ func setupProvider(reader *bufio.Reader, config *Config, p Provider) {
 	// Demander à l'utilisateur
 	if promptYesNo(reader, fmt.Sprintf("Configurer %s?", p.Description), false) {
 		fmt.Printf("  Clé API %s: ", p.Name)
-		key, _ := reader.ReadString('\n')
-		key = strings.TrimSpace(key)
+		key, _ := readSecret(reader)
 		if key != "" {
 			config.Providers[p.Name] = key
 			fmt.Printf("  [OK] %s configuré\n", p.Description)
@@ -390,47 +493,34 @@ func createCredentialsDB(config *Config) error {
 }
 
 func saveCredentials(config *Config) error {
-	dbPath := filepath.Join(config.BasePath, fmt.Sprintf("holow-mcp.%s.db", config.CredentialsDB))
-
-	db, err := sql.Open("sqlite", dbPath)
+	store, err := newCredentialStore(config.CredentialBackend, config.BasePath, config.CredentialsDB)
 	if err != nil {
 		return err
 	}
-	defer db.Close()
-
-	// Récupérer le sel
-	var salt []byte
-	err = db.QueryRow(`SELECT salt FROM encryption_meta WHERE id = 1`).Scan(&salt)
-	if err != nil {
-		return fmt.Errorf("sel non trouvé: %w", err)
-	}
-
-	// Dériver la clé de chiffrement
-	key := deriveKey(config.BasePath, config.CredentialsDB, salt)
 
 	// Sauvegarder chaque credential
 	for provider, apiKey := range config.Providers {
-		encrypted, iv, err := encrypt([]byte(apiKey), key)
-		if err != nil {
-			return fmt.Errorf("chiffrement échoué pour %s: %w", provider, err)
-		}
-
-		// Hint: 4 derniers caractères
-		hint := ""
-		if len(apiKey) > 4 {
-			hint = "..." + apiKey[len(apiKey)-4:]
+		if err := store.Set(provider, apiKey); err != nil {
+			return err
 		}
+	}
 
-		_, err = db.Exec(`
-			INSERT OR REPLACE INTO credentials (provider, api_key_encrypted, iv, key_hint, updated_at)
-			VALUES (?, ?, ?, ?, strftime('%s', 'now'))
-		`, provider, encrypted, iv, hint)
+	// Vérifier immédiatement que chaque clé se relit (détecte un problème de dérivation ou de trousseau)
+	return verifyCredentials(store, config)
+}
 
+// verifyCredentials relit chaque credential depuis store comme GetCredential
+// et le compare à la valeur en mémoire
+func verifyCredentials(store CredentialStore, config *Config) error {
+	for provider, apiKey := range config.Providers {
+		stored, err := store.Get(provider)
 		if err != nil {
-			return fmt.Errorf("sauvegarde échouée pour %s: %w", provider, err)
+			return fmt.Errorf("vérification échouée pour %s: %w", provider, err)
+		}
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(apiKey)) != 1 {
+			return fmt.Errorf("vérification échouée pour %s: la valeur déchiffrée ne correspond pas", provider)
 		}
 	}
-
 	return nil
 }
 
@@ -479,13 +569,16 @@ func decrypt(ciphertext, key, iv []byte) ([]byte, error) {
 	return gcm.Open(nil, iv, ciphertext, nil)
 }
 
-func printSummary(config *Config) {
+func printSummary(config *Config, dryRun bool) {
 	fmt.Println(`
 ╔═══════════════════════════════════════════════════════════╗
 ║                       RÉSUMÉ                              ║
 ╚═══════════════════════════════════════════════════════════╝`)
 	fmt.Printf("  Chemin: %s\n", config.BasePath)
 	fmt.Printf("  Base credentials: holow-mcp.%s.db\n", config.CredentialsDB)
+	if config.CredentialBackend == BackendKeychain {
+		fmt.Println("  Clés API: trousseau du système")
+	}
 	fmt.Println("\n  Providers configurés:")
 	if len(config.Providers) == 0 {
 		fmt.Println("    (aucun)")
@@ -493,13 +586,58 @@ func printSummary(config *Config) {
 	for provider := range config.Providers {
 		fmt.Printf("    - %s\n", provider)
 	}
+	if dryRun {
+		return
+	}
 	fmt.Println("\n[OK] Initialisation terminée!")
 	fmt.Println("     Lancez: holow-mcp -path " + config.BasePath)
 }
 
-// GetCredential récupère une clé API déchiffrée
-func GetCredential(basePath, credentialsDB, provider string) (string, error) {
-	dbPath := filepath.Join(basePath, fmt.Sprintf("holow-mcp.%s.db", credentialsDB))
+// sqliteStore stocke les clés API chiffrées (AES-256-GCM) dans la base credentials
+type sqliteStore struct {
+	basePath      string
+	credentialsDB string
+}
+
+// Set chiffre et enregistre la clé API d'un provider
+func (s *sqliteStore) Set(provider, apiKey string) error {
+	dbPath := filepath.Join(s.basePath, fmt.Sprintf("holow-mcp.%s.db", s.credentialsDB))
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Récupérer le sel
+	var salt []byte
+	err = db.QueryRow(`SELECT salt FROM encryption_meta WHERE id = 1`).Scan(&salt)
+	if err != nil {
+		return fmt.Errorf("sel non trouvé: %w", err)
+	}
+
+	// Dériver la clé de chiffrement
+	key := deriveKey(s.basePath, s.credentialsDB, salt)
+
+	encrypted, iv, err := encrypt([]byte(apiKey), key)
+	if err != nil {
+		return fmt.Errorf("chiffrement échoué pour %s: %w", provider, err)
+	}
+
+	_, err = db.Exec(`
+		INSERT OR REPLACE INTO credentials (provider, api_key_encrypted, iv, key_hint, updated_at)
+		VALUES (?, ?, ?, ?, strftime('%s', 'now'))
+	`, provider, encrypted, iv, keyHint(apiKey))
+
+	if err != nil {
+		return fmt.Errorf("sauvegarde échouée pour %s: %w", provider, err)
+	}
+	return nil
+}
+
+// Get récupère une clé API déchiffrée
+func (s *sqliteStore) Get(provider string) (string, error) {
+	dbPath := filepath.Join(s.basePath, fmt.Sprintf("holow-mcp.%s.db", s.credentialsDB))
 
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
@@ -524,7 +662,7 @@ func GetCredential(basePath, credentialsDB, provider string) (string, error) {
 	}
 
 	// Dériver la clé et déchiffrer
-	key := deriveKey(basePath, credentialsDB, salt)
+	key := deriveKey(s.basePath, s.credentialsDB, salt)
 	plaintext, err := decrypt(encrypted, key, iv)
 	if err != nil {
 		return "", fmt.Errorf("déchiffrement échoué: %w", err)
@@ -533,9 +671,9 @@ func GetCredential(basePath, credentialsDB, provider string) (string, error) {
 	return string(plaintext), nil
 }
 
-// ListProviders liste les providers configurés
-func ListProviders(basePath, credentialsDB string) ([]string, error) {
-	dbPath := filepath.Join(basePath, fmt.Sprintf("holow-mcp.%s.db", credentialsDB))
+// List liste les providers configurés
+func (s *sqliteStore) List() ([]string, error) {
+	dbPath := filepath.Join(s.basePath, fmt.Sprintf("holow-mcp.%s.db", s.credentialsDB))
 
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
@@ -560,9 +698,9 @@ func ListProviders(basePath, credentialsDB string) ([]string, error) {
 	return providers, nil
 }
 
-// CredentialHint retourne un hint pour une clé API (pour affichage)
-func CredentialHint(basePath, credentialsDB, provider string) string {
-	dbPath := filepath.Join(basePath, fmt.Sprintf("holow-mcp.%s.db", credentialsDB))
+// Hint retourne le hint enregistré pour une clé API
+func (s *sqliteStore) Hint(provider string) string {
+	dbPath := filepath.Join(s.basePath, fmt.Sprintf("holow-mcp.%s.db", s.credentialsDB))
 
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
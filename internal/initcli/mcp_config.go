@@ -248,6 +248,11 @@ func GetDefaultConfigPath(provider MCPProvider) string {
 
 // RunMCPConfigSetup exécute le setup interactif des configs MCP
 func RunMCPConfigSetup(reader *bufio.Reader, holowPath string) error {
+	return runMCPConfigSetup(reader, holowPath, false)
+}
+
+// runMCPConfigSetup exécute le setup MCP; en dry-run les fichiers ne sont pas écrits
+func runMCPConfigSetup(reader *bufio.Reader, holowPath string, dryRun bool) error {
 	fmt.Println("\n--- Configuration MCP pour AI Clients ---")
 
 	providers := []struct {
@@ -284,10 +289,12 @@ func RunMCPConfigSetup(reader *bufio.Reader, holowPath string) error {
 						mergeConfigs(p.Provider, config, info.Config)
 					}
 
-					if err := SaveMCPConfig(info.ConfigPath, config); err != nil {
+					if err := apply(dryRun, "réécriture de "+info.ConfigPath+" (serveurs existants conservés)", func() error {
+						return SaveMCPConfig(info.ConfigPath, config)
+					}); err != nil {
 						fmt.Printf("  [X] Erreur: %v\n", err)
 					} else {
-						fmt.Println("  [OK] Configuration corrigée")
+						reportOK(dryRun, "  [OK] Configuration corrigée\n")
 					}
 				}
 			} else if info.HasHolow {
@@ -295,10 +302,12 @@ func RunMCPConfigSetup(reader *bufio.Reader, holowPath string) error {
 			} else {
 				if promptYesNo(reader, "  Ajouter holow-mcp à la configuration?", true) {
 					AddHolowToConfig(p.Provider, info.Config, holowPath)
-					if err := SaveMCPConfig(info.ConfigPath, info.Config); err != nil {
+					if err := apply(dryRun, "ajout de holow-mcp dans "+info.ConfigPath, func() error {
+						return SaveMCPConfig(info.ConfigPath, info.Config)
+					}); err != nil {
 						fmt.Printf("  [X] Erreur: %v\n", err)
 					} else {
-						fmt.Println("  [OK] holow-mcp ajouté")
+						reportOK(dryRun, "  [OK] holow-mcp ajouté\n")
 					}
 				}
 			}
@@ -309,10 +318,12 @@ func RunMCPConfigSetup(reader *bufio.Reader, holowPath string) error {
 				configPath := GetDefaultConfigPath(p.Provider)
 				config := CreateDefaultConfig(p.Provider, holowPath)
 
-				if err := SaveMCPConfig(configPath, config); err != nil {
+				if err := apply(dryRun, "création de "+configPath, func() error {
+					return SaveMCPConfig(configPath, config)
+				}); err != nil {
 					fmt.Printf("  [X] Erreur: %v\n", err)
 				} else {
-					fmt.Printf("  [OK] Configuration créée: %s\n", configPath)
+					reportOK(dryRun, "  [OK] Configuration créée: %s\n", configPath)
 				}
 			}
 		}
@@ -343,10 +354,79 @@ func mergeConfigs(provider MCPProvider, dest, src *MCPConfigFile) {
 	}
 }
 
-// PrintMCPConfigStatus affiche le statut des configurations MCP
-func PrintMCPConfigStatus() {
-	fmt.Println("\n--- Statut des configurations MCP ---")
+// RepointMCPConfigs remplace oldPath par newPath dans les serveurs holow-mcp des configs MCP
+// détectées (argument -path, binaire présent sous newPath); retourne les fichiers réécrits
+func RepointMCPConfigs(oldPath, newPath string) ([]string, error) {
+	var updated []string
+	for _, provider := range []MCPProvider{ProviderClaudeCode, ProviderGeminiCLI, ProviderOpenCode} {
+		info := DetectProviderConfig(provider)
+		if !info.HasHolow {
+			continue
+		}
+
+		servers := info.Config.MCPServers
+		if provider == ProviderOpenCode {
+			servers = info.Config.MCP
+		}
+		changed := false
+		for name, server := range servers {
+			if !strings.Contains(strings.ToLower(name), "holow") {
+				continue
+			}
+			if repointServer(&server, oldPath, newPath) {
+				servers[name] = server
+				changed = true
+			}
+		}
+
+		if changed {
+			if err := SaveMCPConfig(info.ConfigPath, info.Config); err != nil {
+				return updated, fmt.Errorf("%s: %w", info.ConfigPath, err)
+			}
+			updated = append(updated, info.ConfigPath)
+		}
+	}
+	return updated, nil
+}
+
+// repointServer réécrit -path oldPath et un binaire sous oldPath; indique si server a changé
+func repointServer(server *MCPServerConfig, oldPath, newPath string) bool {
+	changed := false
+	for i := 0; i+1 < len(server.Args); i++ {
+		if server.Args[i] == "-path" && filepath.Clean(server.Args[i+1]) == filepath.Clean(oldPath) {
+			server.Args[i+1] = newPath
+			changed = true
+		}
+	}
+	// Le binaire n'est pas dans le bundle: repointé seulement s'il a été copié sous newPath
+	if rel, err := filepath.Rel(oldPath, server.Command); err == nil && filepath.IsAbs(server.Command) && !strings.HasPrefix(rel, "..") {
+		if _, err := os.Stat(filepath.Join(newPath, rel)); err == nil {
+			server.Command = filepath.Join(newPath, rel)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Statuts d'un client MCP (champ status de MCPClientStatus)
+const (
+	MCPStatusNotConfigured = "not_configured" // Aucun fichier de config trouvé
+	MCPStatusConfigured    = "configured"     // holow-mcp déclaré
+	MCPStatusHolowMissing  = "holow_missing"  // Config conforme sans holow-mcp
+	MCPStatusNonConformant = "non_conformant" // Config illisible ou mal formée
+)
 
+// MCPClientStatus statut de la configuration MCP d'un client (Claude Code, Gemini CLI, OpenCode)
+type MCPClientStatus struct {
+	Provider   MCPProvider `json:"provider"`
+	Name       string      `json:"name"`
+	Status     string      `json:"status"`
+	ConfigPath string      `json:"config_path,omitempty"`
+	Issues     []string    `json:"issues,omitempty"`
+}
+
+// MCPConfigStatus détecte le statut de la configuration MCP de chaque client
+func MCPConfigStatus() []MCPClientStatus {
 	providers := []struct {
 		Provider MCPProvider
 		Name     string
@@ -356,23 +436,43 @@ func PrintMCPConfigStatus() {
 		{ProviderOpenCode, "OpenCode"},
 	}
 
+	statuses := make([]MCPClientStatus, 0, len(providers))
 	for _, p := range providers {
 		info := DetectProviderConfig(p.Provider)
 
-		status := "❌ Non configuré"
+		st := MCPClientStatus{Provider: p.Provider, Name: p.Name, Status: MCPStatusNotConfigured}
 		if info.Exists {
-			if info.HasHolow {
-				status = "✅ holow-mcp configuré"
-			} else if info.IsConformant {
-				status = "⚠️  Config existe, holow-mcp absent"
-			} else {
-				status = "⚠️  Config non conforme"
+			st.ConfigPath = info.ConfigPath
+			st.Issues = info.Issues
+			switch {
+			case info.HasHolow:
+				st.Status = MCPStatusConfigured
+			case info.IsConformant:
+				st.Status = MCPStatusHolowMissing
+			default:
+				st.Status = MCPStatusNonConformant
 			}
 		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
 
-		fmt.Printf("  %s: %s\n", p.Name, status)
-		if info.Exists {
-			fmt.Printf("    Fichier: %s\n", info.ConfigPath)
+// PrintMCPConfigStatus affiche le statut des configurations MCP
+func PrintMCPConfigStatus() {
+	fmt.Println("\n--- Statut des configurations MCP ---")
+
+	labels := map[string]string{
+		MCPStatusNotConfigured: "❌ Non configuré",
+		MCPStatusConfigured:    "✅ holow-mcp configuré",
+		MCPStatusHolowMissing:  "⚠️  Config existe, holow-mcp absent",
+		MCPStatusNonConformant: "⚠️  Config non conforme",
+	}
+
+	for _, st := range MCPConfigStatus() {
+		fmt.Printf("  %s: %s\n", st.Name, labels[st.Status])
+		if st.ConfigPath != "" {
+			fmt.Printf("    Fichier: %s\n", st.ConfigPath)
 		}
 	}
 }
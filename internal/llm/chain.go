@@ -0,0 +1,97 @@
+// Package llm - Chaîne de repli entre providers
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Chain essaie ses clients dans l'ordre et passe au suivant sur erreur transitoire
+// Response.Provider indique le provider qui a finalement servi la requête
+type Chain struct {
+	clients []Client
+}
+
+// NewChain crée une chaîne de repli (ordre de préférence)
+func NewChain(clients ...Client) *Chain {
+	return &Chain{clients: clients}
+}
+
+// Complete interroge les providers jusqu'au premier succès
+// Une erreur non transitoire (requête invalide, clé refusée) arrête la chaîne
+func (c *Chain) Complete(ctx context.Context, prompt string, opts Options) (*Response, error) {
+	return c.try(opts.Provider, func(client Client) (*Response, error) {
+		return client.Complete(ctx, prompt, opts)
+	}, IsRetryable)
+}
+
+// Stream diffuse depuis le premier provider disponible
+// Le repli n'a lieu que si aucun fragment n'a encore été livré
+func (c *Chain) Stream(ctx context.Context, prompt string, opts Options, onDelta DeltaFunc) (*Response, error) {
+	emitted := false
+	deliver := func(text string) {
+		emitted = true
+		if onDelta != nil {
+			onDelta(text)
+		}
+	}
+	return c.try(opts.Provider, func(client Client) (*Response, error) {
+		return CompleteStream(ctx, client, prompt, opts, deliver)
+	}, func(err error) bool {
+		return !emitted && IsRetryable(err)
+	})
+}
+
+// Providers retourne les providers de la chaîne, dans l'ordre
+func (c *Chain) Providers() []string {
+	var names []string
+	for _, client := range c.clients {
+		if n, ok := client.(Named); ok {
+			names = append(names, n.Provider())
+		}
+	}
+	return names
+}
+
+// candidates clients éligibles: tous, ou seulement celui de provider s'il est demandé
+func (c *Chain) candidates(provider string) ([]Client, error) {
+	if len(c.clients) == 0 {
+		return nil, fmt.Errorf("no LLM provider configured")
+	}
+	if provider == "" {
+		return c.clients, nil
+	}
+	var matched []Client
+	for _, client := range c.clients {
+		if n, ok := client.(Named); ok && n.Provider() == provider {
+			matched = append(matched, client)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("LLM provider %s not configured (available: %s)", provider, strings.Join(c.Providers(), ", "))
+	}
+	return matched, nil
+}
+
+// try applique call à chaque client éligible tant que fallback(err) l'autorise
+func (c *Chain) try(provider string, call func(Client) (*Response, error), fallback func(error) bool) (*Response, error) {
+	clients, err := c.candidates(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, client := range clients {
+		resp, err := call(client)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+		if !fallback(err) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// chainOf mocks nommés dans l'ordre de repli, erreurs scriptées par provider
+func chainOf(errs map[string]error, names ...string) (*Chain, map[string]*Mock) {
+	mocks := make(map[string]*Mock, len(names))
+	clients := make([]Client, 0, len(names))
+	for _, name := range names {
+		m := &Mock{Name: name, Responses: []string{"answer from " + name}, Err: errs[name]}
+		mocks[name] = m
+		clients = append(clients, m)
+	}
+	return NewChain(clients...), mocks
+}
+
+func TestChainFallbackOrder(t *testing.T) {
+	unavailable := &APIError{Provider: ProviderClaude, Status: http.StatusServiceUnavailable}
+	rateLimited := &APIError{Provider: ProviderGemini, Status: http.StatusTooManyRequests}
+	unauthorized := &APIError{Provider: ProviderClaude, Status: http.StatusUnauthorized}
+
+	tests := []struct {
+		name      string
+		order     []string
+		errs      map[string]error
+		provider  string
+		want      string // Provider ayant servi la requête ("" = erreur attendue)
+		wantErr   string
+		wantCalls map[string]int
+	}{
+		{
+			name:      "first provider serves",
+			order:     DefaultProviders,
+			want:      ProviderClaude,
+			wantCalls: map[string]int{ProviderClaude: 1, ProviderGemini: 0, ProviderCerebras: 0},
+		},
+		{
+			name:      "configured order is honored",
+			order:     []string{ProviderCerebras, ProviderClaude},
+			want:      ProviderCerebras,
+			wantCalls: map[string]int{ProviderCerebras: 1, ProviderClaude: 0},
+		},
+		{
+			name:      "server error falls back",
+			order:     DefaultProviders,
+			errs:      map[string]error{ProviderClaude: unavailable},
+			want:      ProviderGemini,
+			wantCalls: map[string]int{ProviderClaude: 1, ProviderGemini: 1, ProviderCerebras: 0},
+		},
+		{
+			name:      "rate limit and network error fall back",
+			order:     DefaultProviders,
+			errs:      map[string]error{ProviderClaude: errors.New("connection reset"), ProviderGemini: rateLimited},
+			want:      ProviderCerebras,
+			wantCalls: map[string]int{ProviderClaude: 1, ProviderGemini: 1, ProviderCerebras: 1},
+		},
+		{
+			name:      "rejected key stops the chain",
+			order:     DefaultProviders,
+			errs:      map[string]error{ProviderClaude: unauthorized},
+			wantErr:   "HTTP 401",
+			wantCalls: map[string]int{ProviderClaude: 1, ProviderGemini: 0, ProviderCerebras: 0},
+		},
+		{
+			name:      "all providers fail",
+			order:     []string{ProviderClaude, ProviderGemini},
+			errs:      map[string]error{ProviderClaude: unavailable, ProviderGemini: rateLimited},
+			wantErr:   "all LLM providers failed",
+			wantCalls: map[string]int{ProviderClaude: 1, ProviderGemini: 1},
+		},
+		{
+			name:      "explicit provider skips the others",
+			order:     DefaultProviders,
+			provider:  ProviderCerebras,
+			want:      ProviderCerebras,
+			wantCalls: map[string]int{ProviderClaude: 0, ProviderGemini: 0, ProviderCerebras: 1},
+		},
+		{
+			name:      "unknown provider",
+			order:     []string{ProviderClaude},
+			provider:  "openai",
+			wantErr:   "LLM provider openai not configured",
+			wantCalls: map[string]int{ProviderClaude: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, mocks := chainOf(tt.errs, tt.order...)
+			resp, err := chain.Complete(context.Background(), "hello", Options{Provider: tt.provider})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Complete: %v", err)
+				}
+				if resp.Provider != tt.want || resp.Text != "answer from "+tt.want {
+					t.Errorf("served by %s (%q), want %s", resp.Provider, resp.Text, tt.want)
+				}
+			}
+			for name, want := range tt.wantCalls {
+				if got := len(mocks[name].Prompts); got != want {
+					t.Errorf("%s called %d times, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChainStreamFallback(t *testing.T) {
+	chain, mocks := chainOf(map[string]error{
+		ProviderClaude: &APIError{Provider: ProviderClaude, Status: http.StatusBadGateway},
+	}, ProviderClaude, ProviderGemini)
+
+	var deltas []string
+	resp, err := chain.Stream(context.Background(), "hello", Options{}, func(text string) {
+		deltas = append(deltas, text)
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if resp.Provider != ProviderGemini {
+		t.Errorf("served by %s, want %s", resp.Provider, ProviderGemini)
+	}
+	if got := strings.Join(deltas, ""); got != resp.Text {
+		t.Errorf("deltas %q do not rebuild %q", got, resp.Text)
+	}
+	if len(mocks[ProviderClaude].Prompts) != 1 {
+		t.Errorf("claude called %d times, want 1", len(mocks[ProviderClaude].Prompts))
+	}
+}
+
+func TestChainCanceledContextDoesNotFallBack(t *testing.T) {
+	chain, mocks := chainOf(nil, ProviderClaude, ProviderGemini)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := chain.Complete(ctx, "hello", Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if len(mocks[ProviderGemini].Prompts) != 0 {
+		t.Error("canceled request must not reach the next provider")
+	}
+}
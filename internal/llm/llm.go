@@ -0,0 +1,352 @@
+// Package llm fournit un client LLM indépendant du provider (Claude, Gemini, Cerebras)
+package llm
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/initcli"
+
+	_ "modernc.org/sqlite"
+)
+
+// Noms des providers (identiques aux clés de la table credentials)
+const (
+	ProviderClaude   = "claude"
+	ProviderGemini   = "gemini"
+	ProviderCerebras = "cerebras"
+)
+
+// Valeurs par défaut des appels
+const (
+	defaultTimeout    = 120 * time.Second
+	defaultMaxRetries = 2
+	defaultRetryDelay = time.Second
+	defaultMaxTokens  = 4096
+)
+
+// Options paramètre un appel Complete
+type Options struct {
+	Provider    string  // Restreint l'appel à ce provider (vide = tout provider configuré)
+	Model       string  // Surcharge le modèle du provider
+	System      string  // Instructions système
+	MaxTokens   int     // 0 = defaultMaxTokens
+	Temperature float64 // 0 = défaut du provider
+}
+
+// Response résultat d'une complétion
+type Response struct {
+	Text             string `json:"text"`
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// Client interface commune aux providers
+type Client interface {
+	Complete(ctx context.Context, prompt string, opts Options) (*Response, error)
+}
+
+// Named client lié à un provider unique (permet à Chain de filtrer sur Options.Provider)
+type Named interface {
+	Provider() string
+}
+
+// ProviderConfig configuration d'un provider (table provider_config de la base credentials)
+type ProviderConfig struct {
+	Name       string
+	BaseURL    string        // Vide = URL publique du provider
+	Model      string        // Vide = modèle par défaut du provider
+	Timeout    time.Duration // 0 = defaultTimeout
+	MaxRetries int           // Tentatives supplémentaires sur erreur transitoire (0 = defaultMaxRetries)
+}
+
+// APIError erreur HTTP renvoyée par un provider
+type APIError struct {
+	Provider string
+	Status   int
+	Body     string
+}
+
+// Error formate l'erreur avec le statut HTTP
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (HTTP %d): %s", e.Provider, e.Status, e.Body)
+}
+
+// Retryable indique une erreur transitoire (rate limit, surcharge, erreur serveur)
+func (e *APIError) Retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// IsRetryable indique si err justifie une nouvelle tentative
+// Les erreurs réseau le sont, pas l'annulation du contexte
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
+
+// provider construit les requêtes et décode les réponses d'une API
+type provider interface {
+	defaults() (baseURL, model string)
+	request(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error)
+	parse(body []byte) (*Response, error)
+}
+
+// providers implémentations disponibles
+var providers = map[string]provider{
+	ProviderClaude:   claudeProvider{},
+	ProviderGemini:   geminiProvider{},
+	ProviderCerebras: cerebrasProvider{},
+}
+
+// httpClient client HTTP commun: timeouts et retries
+type httpClient struct {
+	cfg        ProviderConfig
+	apiKey     string
+	provider   provider
+	http       *http.Client
+	stream     *http.Client // Sans timeout global: la durée d'un flux est bornée par ctx
+	retryDelay time.Duration
+}
+
+// New crée un client pour cfg.Name avec la clé API fournie
+func New(cfg ProviderConfig, apiKey string) (Client, error) {
+	p, ok := providers[cfg.Name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported LLM provider: %s", cfg.Name)
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("missing API key for %s", cfg.Name)
+	}
+
+	baseURL, model := p.defaults()
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = baseURL
+	}
+	if cfg.Model == "" {
+		cfg.Model = model
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+
+	return &httpClient{
+		cfg:      cfg,
+		apiKey:   apiKey,
+		provider: p,
+		http:     &http.Client{Timeout: cfg.Timeout},
+		stream: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: cfg.Timeout,
+		}},
+		retryDelay: defaultRetryDelay,
+	}, nil
+}
+
+// Provider retourne le nom du provider du client
+func (c *httpClient) Provider() string {
+	return c.cfg.Name
+}
+
+// Complete envoie le prompt et réessaie les erreurs transitoires avec backoff exponentiel
+func (c *httpClient) Complete(ctx context.Context, prompt string, opts Options) (*Response, error) {
+	if err := c.checkProvider(opts); err != nil {
+		return nil, err
+	}
+	opts = c.withDefaults(opts)
+	return c.retry(ctx, opts, func() (*Response, error) {
+		return c.do(ctx, prompt, opts)
+	}, IsRetryable)
+}
+
+// checkProvider refuse un appel destiné explicitement à un autre provider
+func (c *httpClient) checkProvider(opts Options) error {
+	if opts.Provider != "" && opts.Provider != c.cfg.Name {
+		return fmt.Errorf("LLM provider %s not configured (using %s)", opts.Provider, c.cfg.Name)
+	}
+	return nil
+}
+
+// withDefaults complète les options avec le modèle et la limite de tokens par défaut
+func (c *httpClient) withDefaults(opts Options) Options {
+	if opts.Model == "" {
+		opts.Model = c.cfg.Model
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = defaultMaxTokens
+	}
+	return opts
+}
+
+// retry exécute attempt jusqu'à MaxRetries fois supplémentaires tant que retryable(err)
+func (c *httpClient) retry(ctx context.Context, opts Options, attempt func() (*Response, error), retryable func(error) bool) (*Response, error) {
+	delay := c.retryDelay
+	var lastErr error
+	for i := 0; i <= c.cfg.MaxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		resp, err := attempt()
+		if err == nil {
+			resp.Provider = c.cfg.Name
+			if resp.Model == "" {
+				resp.Model = opts.Model
+			}
+			return resp, nil
+		}
+		lastErr = err
+		if !retryable(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// do effectue une tentative
+func (c *httpClient) do(ctx context.Context, prompt string, opts Options) (*Response, error) {
+	req, err := c.provider.request(ctx, c.cfg, c.apiKey, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Provider: c.cfg.Name, Status: resp.StatusCode, Body: truncate(string(body), 500)}
+	}
+	return c.provider.parse(body)
+}
+
+// newJSONRequest prépare un POST JSON
+func newJSONRequest(ctx context.Context, url string, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// endpoint joint baseURL et path sans doubler le préfixe de version
+// Les passerelles compatibles sont souvent configurées avec une base_url finissant par /v1
+func endpoint(baseURL, path string) string {
+	base := strings.TrimRight(baseURL, "/")
+	if version := path[:strings.Index(path[1:], "/")+1]; strings.HasSuffix(base, version) {
+		base = strings.TrimSuffix(base, version)
+	}
+	return base + path
+}
+
+// truncate limite la taille d'un corps d'erreur
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+// LoadProviderConfig lit base_url et model_default depuis provider_config (base credentials)
+// Un provider absent de la table utilise les valeurs par défaut
+func LoadProviderConfig(basePath, credentialsDB, name string) (ProviderConfig, error) {
+	cfg := ProviderConfig{Name: name}
+
+	dbPath := filepath.Join(basePath, fmt.Sprintf("holow-mcp.%s.db", credentialsDB))
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return cfg, err
+	}
+	defer db.Close()
+
+	var baseURL, model sql.NullString
+	var enabled sql.NullInt64
+	err = db.QueryRow(`
+		SELECT base_url, model_default, enabled FROM provider_config WHERE provider = ?
+	`, name).Scan(&baseURL, &model, &enabled)
+	if err == sql.ErrNoRows {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if enabled.Valid && enabled.Int64 == 0 {
+		return cfg, fmt.Errorf("LLM provider %s is disabled in provider_config", name)
+	}
+
+	cfg.BaseURL = baseURL.String
+	cfg.Model = model.String
+	return cfg, nil
+}
+
+// FromCredentials crée le client d'un provider depuis la base credentials
+func FromCredentials(basePath, credentialsDB, name string) (Client, error) {
+	cfg, err := LoadProviderConfig(basePath, credentialsDB, name)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := initcli.GetCredential(basePath, credentialsDB, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return New(cfg, apiKey)
+}
+
+// DefaultProviders ordre de préférence des providers LLM
+var DefaultProviders = []string{ProviderClaude, ProviderGemini, ProviderCerebras}
+
+// Configured retourne une chaîne de repli sur les providers de names ayant une clé enregistrée
+// (dans l'ordre de names, DefaultProviders si vide); un seul provider est retourné tel quel
+func Configured(basePath, credentialsDB string, names ...string) (Client, error) {
+	if len(names) == 0 {
+		names = DefaultProviders
+	}
+	var clients []Client
+	var errs []error
+	for _, name := range names {
+		client, err := FromCredentials(basePath, credentialsDB, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clients = append(clients, client)
+	}
+
+	switch len(clients) {
+	case 0:
+		return nil, fmt.Errorf("no LLM provider configured: %w", errors.Join(errs...))
+	case 1:
+		return clients[0], nil
+	}
+	return NewChain(clients...), nil
+}
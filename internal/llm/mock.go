@@ -0,0 +1,76 @@
+// Package llm - Client factice pour tests et développement hors ligne
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Mock client scripté: retourne Responses dans l'ordre puis répète la dernière
+// Err, si défini, est retourné à chaque appel
+type Mock struct {
+	mu        sync.Mutex
+	Name      string // Provider simulé (défaut "mock"), pour tester une Chain
+	Responses []string
+	Err       error
+	Prompts   []string // Prompts reçus, dans l'ordre
+}
+
+// Complete enregistre le prompt et retourne la réponse scriptée suivante
+func (m *Mock) Complete(ctx context.Context, prompt string, opts Options) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Prompts = append(m.Prompts, prompt)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if len(m.Responses) == 0 {
+		return nil, fmt.Errorf("mock: no scripted response")
+	}
+
+	idx := len(m.Prompts) - 1
+	if idx >= len(m.Responses) {
+		idx = len(m.Responses) - 1
+	}
+	model := opts.Model
+	if model == "" {
+		model = "mock"
+	}
+	text := m.Responses[idx]
+	return &Response{
+		Text:             text,
+		Provider:         m.Provider(),
+		Model:            model,
+		PromptTokens:     len(prompt) / 4,
+		CompletionTokens: len(text) / 4,
+	}, nil
+}
+
+// Provider retourne Name, ou "mock"
+func (m *Mock) Provider() string {
+	if m.Name == "" {
+		return "mock"
+	}
+	return m.Name
+}
+
+// Stream livre la réponse scriptée mot par mot
+func (m *Mock) Stream(ctx context.Context, prompt string, opts Options, onDelta DeltaFunc) (*Response, error) {
+	resp, err := m.Complete(ctx, prompt, opts)
+	if err != nil || onDelta == nil {
+		return resp, err
+	}
+	for _, word := range strings.SplitAfter(resp.Text, " ") {
+		if word != "" {
+			onDelta(word)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,210 @@
+// Package llm - Implémentations des APIs Claude, Gemini et Cerebras
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// claudeProvider API Messages d'Anthropic
+type claudeProvider struct{}
+
+func (claudeProvider) defaults() (string, string) {
+	return "https://api.anthropic.com", "claude-3-5-sonnet-latest"
+}
+
+func (claudeProvider) request(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error) {
+	payload := map[string]interface{}{
+		"model":      opts.Model,
+		"max_tokens": opts.MaxTokens,
+		"messages": []map[string]interface{}{
+			{"role": "user", "content": prompt},
+		},
+	}
+	if opts.System != "" {
+		payload["system"] = opts.System
+	}
+	if opts.Temperature > 0 {
+		payload["temperature"] = opts.Temperature
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newJSONRequest(ctx, endpoint(cfg.BaseURL, "/v1/messages"), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	return req, nil
+}
+
+func (claudeProvider) parse(body []byte) (*Response, error) {
+	var resp struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid claude response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return &Response{
+		Text:             text.String(),
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+	}, nil
+}
+
+// geminiProvider API generateContent de Google
+type geminiProvider struct{}
+
+func (geminiProvider) defaults() (string, string) {
+	return "https://generativelanguage.googleapis.com", "gemini-1.5-flash"
+}
+
+func (geminiProvider) request(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error) {
+	generation := map[string]interface{}{
+		"maxOutputTokens": opts.MaxTokens,
+	}
+	if opts.Temperature > 0 {
+		generation["temperature"] = opts.Temperature
+	}
+	payload := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{"role": "user", "parts": []map[string]interface{}{{"text": prompt}}},
+		},
+		"generationConfig": generation,
+	}
+	if opts.System != "" {
+		payload["systemInstruction"] = map[string]interface{}{
+			"parts": []map[string]interface{}{{"text": opts.System}},
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newJSONRequest(ctx, endpoint(cfg.BaseURL, "/v1beta/models/"+url.PathEscape(opts.Model)+":generateContent"), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-goog-api-key", apiKey)
+	return req, nil
+}
+
+func (geminiProvider) parse(body []byte) (*Response, error) {
+	var resp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+		ModelVersion string `json:"modelVersion"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid gemini response: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("gemini returned no candidates")
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return &Response{
+		Text:             text.String(),
+		Model:            resp.ModelVersion,
+		PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+	}, nil
+}
+
+// cerebrasProvider API chat/completions (compatible OpenAI) de Cerebras
+type cerebrasProvider struct{}
+
+func (cerebrasProvider) defaults() (string, string) {
+	return "https://api.cerebras.ai", "llama3.1-8b"
+}
+
+func (cerebrasProvider) request(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error) {
+	messages := []map[string]interface{}{}
+	if opts.System != "" {
+		messages = append(messages, map[string]interface{}{"role": "system", "content": opts.System})
+	}
+	messages = append(messages, map[string]interface{}{"role": "user", "content": prompt})
+
+	payload := map[string]interface{}{
+		"model":      opts.Model,
+		"messages":   messages,
+		"max_tokens": opts.MaxTokens,
+	}
+	if opts.Temperature > 0 {
+		payload["temperature"] = opts.Temperature
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newJSONRequest(ctx, endpoint(cfg.BaseURL, "/v1/chat/completions"), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req, nil
+}
+
+func (cerebrasProvider) parse(body []byte) (*Response, error) {
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid cerebras response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("cerebras returned no choices")
+	}
+	return &Response{
+		Text:             resp.Choices[0].Message.Content,
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}, nil
+}
@@ -0,0 +1,265 @@
+// Package llm - Complétions en streaming (Server-Sent Events)
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DeltaFunc reçoit chaque fragment de texte dès qu'il arrive du provider
+type DeltaFunc func(text string)
+
+// Streamer client capable de livrer la complétion au fil de l'eau
+type Streamer interface {
+	Stream(ctx context.Context, prompt string, opts Options, onDelta DeltaFunc) (*Response, error)
+}
+
+// CompleteStream utilise Stream si c le supporte, sinon Complete (réponse livrée en un seul fragment)
+func CompleteStream(ctx context.Context, c Client, prompt string, opts Options, onDelta DeltaFunc) (*Response, error) {
+	if s, ok := c.(Streamer); ok {
+		return s.Stream(ctx, prompt, opts, onDelta)
+	}
+	resp, err := c.Complete(ctx, prompt, opts)
+	if err == nil && onDelta != nil && resp.Text != "" {
+		onDelta(resp.Text)
+	}
+	return resp, err
+}
+
+// streamProvider extension d'un provider pour le streaming SSE
+type streamProvider interface {
+	streamRequest(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error)
+	// parseEvent applique un événement "data:" à resp et retourne le texte nouveau
+	parseEvent(data []byte, resp *Response) (string, error)
+}
+
+// maxSSELine taille max d'une ligne d'événement SSE
+const maxSSELine = 1024 * 1024
+
+// Stream envoie le prompt en mode streaming
+// Les erreurs transitoires ne sont réessayées que si aucun fragment n'a encore été livré
+func (c *httpClient) Stream(ctx context.Context, prompt string, opts Options, onDelta DeltaFunc) (*Response, error) {
+	if err := c.checkProvider(opts); err != nil {
+		return nil, err
+	}
+	sp, ok := c.provider.(streamProvider)
+	if !ok {
+		return CompleteStream(ctx, nonStreaming{c}, prompt, opts, onDelta)
+	}
+
+	opts = c.withDefaults(opts)
+	emitted := false
+	deliver := func(text string) {
+		emitted = true
+		if onDelta != nil {
+			onDelta(text)
+		}
+	}
+	return c.retry(ctx, opts, func() (*Response, error) {
+		return c.doStream(ctx, sp, prompt, opts, deliver)
+	}, func(err error) bool {
+		return !emitted && IsRetryable(err)
+	})
+}
+
+// nonStreaming masque Stream pour forcer le repli sur Complete
+type nonStreaming struct{ Client }
+
+// doStream effectue une tentative et lit le flux SSE jusqu'à sa fin
+func (c *httpClient) doStream(ctx context.Context, sp streamProvider, prompt string, opts Options, onDelta DeltaFunc) (*Response, error) {
+	req, err := sp.streamRequest(ctx, c.cfg, c.apiKey, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	httpResp, err := c.stream.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		return nil, &APIError{Provider: c.cfg.Name, Status: httpResp.StatusCode, Body: truncate(string(body), 500)}
+	}
+
+	resp := &Response{}
+	var text strings.Builder
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxSSELine)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			break
+		}
+
+		delta, err := sp.parseEvent([]byte(data), resp)
+		if err != nil {
+			return nil, err
+		}
+		if delta != "" {
+			text.WriteString(delta)
+			onDelta(delta)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	resp.Text = text.String()
+	return resp, nil
+}
+
+// streamRequest Claude: même requête avec stream: true
+func (p claudeProvider) streamRequest(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error) {
+	return withStreamFlag(p.request(ctx, cfg, apiKey, prompt, opts))
+}
+
+// parseEvent Claude: message_start (modèle, tokens d'entrée), content_block_delta, message_delta (tokens de sortie)
+func (claudeProvider) parseEvent(data []byte, resp *Response) (string, error) {
+	var event struct {
+		Type    string `json:"type"`
+		Message struct {
+			Model string `json:"model"`
+			Usage struct {
+				InputTokens int `json:"input_tokens"`
+			} `json:"usage"`
+		} `json:"message"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+		Usage struct {
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", fmt.Errorf("invalid claude stream event: %w", err)
+	}
+
+	switch event.Type {
+	case "message_start":
+		resp.Model = event.Message.Model
+		resp.PromptTokens = event.Message.Usage.InputTokens
+	case "content_block_delta":
+		if event.Delta.Type == "text_delta" {
+			return event.Delta.Text, nil
+		}
+	case "message_delta":
+		resp.CompletionTokens = event.Usage.OutputTokens
+	case "error":
+		return "", fmt.Errorf("claude stream error: %s", event.Error.Message)
+	}
+	return "", nil
+}
+
+// streamRequest Gemini: endpoint streamGenerateContent en SSE
+func (p geminiProvider) streamRequest(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error) {
+	req, err := p.request(ctx, cfg, apiKey, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Path = strings.TrimSuffix(req.URL.Path, ":generateContent") + ":streamGenerateContent"
+	req.URL.RawPath = ""
+	req.URL.RawQuery = "alt=sse"
+	return req, nil
+}
+
+// parseEvent Gemini: chaque événement est une réponse partielle complète
+func (p geminiProvider) parseEvent(data []byte, resp *Response) (string, error) {
+	chunk, err := p.parse(data)
+	if err != nil {
+		return "", err
+	}
+	if chunk.Model != "" {
+		resp.Model = chunk.Model
+	}
+	if chunk.PromptTokens > 0 {
+		resp.PromptTokens = chunk.PromptTokens
+	}
+	if chunk.CompletionTokens > 0 {
+		resp.CompletionTokens = chunk.CompletionTokens
+	}
+	return chunk.Text, nil
+}
+
+// streamRequest Cerebras: même requête avec stream: true
+func (p cerebrasProvider) streamRequest(ctx context.Context, cfg ProviderConfig, apiKey, prompt string, opts Options) (*http.Request, error) {
+	return withStreamFlag(p.request(ctx, cfg, apiKey, prompt, opts))
+}
+
+// parseEvent Cerebras: fragments choices[].delta.content, usage dans le dernier événement
+func (cerebrasProvider) parseEvent(data []byte, resp *Response) (string, error) {
+	var chunk struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return "", fmt.Errorf("invalid cerebras stream event: %w", err)
+	}
+
+	if chunk.Model != "" {
+		resp.Model = chunk.Model
+	}
+	if chunk.Usage != nil {
+		resp.PromptTokens = chunk.Usage.PromptTokens
+		resp.CompletionTokens = chunk.Usage.CompletionTokens
+	}
+	if len(chunk.Choices) == 0 {
+		return "", nil
+	}
+	return chunk.Choices[0].Delta.Content, nil
+}
+
+// withStreamFlag ajoute "stream": true au corps JSON d'une requête
+func withStreamFlag(req *http.Request, err error) (*http.Request, error) {
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	payload["stream"] = true
+
+	streamed, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	out, err := newJSONRequest(req.Context(), req.URL.String(), streamed)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range req.Header {
+		out.Header[key] = values
+	}
+	return out, nil
+}
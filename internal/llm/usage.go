@@ -0,0 +1,104 @@
+// Package llm - Comptabilité des tokens et du coût estimé des appels
+package llm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Price tarif d'un provider en USD par million de tokens
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Prices table de tarifs indexée par nom de provider
+type Prices map[string]Price
+
+// DefaultPrices tarifs publics des modèles par défaut de chaque provider
+var DefaultPrices = Prices{
+	ProviderClaude:   {Prompt: 3.00, Completion: 15.00},
+	ProviderGemini:   {Prompt: 0.075, Completion: 0.30},
+	ProviderCerebras: {Prompt: 0.10, Completion: 0.10},
+}
+
+// ParsePrices lit une table JSON {"provider": {"prompt": x, "completion": y}}
+// Les providers absents gardent leur tarif de DefaultPrices
+func ParsePrices(value string) (Prices, error) {
+	prices := Prices{}
+	for name, price := range DefaultPrices {
+		prices[name] = price
+	}
+	if value == "" {
+		return prices, nil
+	}
+
+	var overrides Prices
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return prices, fmt.Errorf("invalid LLM price table: %w", err)
+	}
+	for name, price := range overrides {
+		prices[name] = price
+	}
+	return prices, nil
+}
+
+// Cost estime le coût en USD d'une réponse (0 si provider sans tarif)
+func (p Prices) Cost(resp *Response) float64 {
+	price, ok := p[resp.Provider]
+	if !ok {
+		return 0
+	}
+	return (float64(resp.PromptTokens)*price.Prompt + float64(resp.CompletionTokens)*price.Completion) / 1e6
+}
+
+// metered enregistre chaque appel réussi dans la table llm_usage (output.db)
+type metered struct {
+	client Client
+	db     *sql.DB
+	prices Prices
+}
+
+// WithUsage enveloppe c pour enregistrer tokens et coût estimé de chaque appel dans db
+func WithUsage(c Client, db *sql.DB, prices Prices) Client {
+	if prices == nil {
+		prices = DefaultPrices
+	}
+	return &metered{client: c, db: db, prices: prices}
+}
+
+// Complete délègue puis enregistre l'usage
+func (m *metered) Complete(ctx context.Context, prompt string, opts Options) (*Response, error) {
+	start := time.Now()
+	resp, err := m.client.Complete(ctx, prompt, opts)
+	if err == nil {
+		m.record(resp, time.Since(start))
+	}
+	return resp, err
+}
+
+// Stream délègue (en streaming si possible) puis enregistre l'usage
+func (m *metered) Stream(ctx context.Context, prompt string, opts Options, onDelta DeltaFunc) (*Response, error) {
+	start := time.Now()
+	resp, err := CompleteStream(ctx, m.client, prompt, opts, onDelta)
+	if err == nil {
+		m.record(resp, time.Since(start))
+	}
+	return resp, err
+}
+
+// record insère une ligne llm_usage; un échec d'écriture ne fait pas échouer l'appel
+func (m *metered) record(resp *Response, elapsed time.Duration) {
+	_, err := m.db.Exec(`
+		INSERT INTO llm_usage (provider, model, prompt_tokens, completion_tokens, cost_usd, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		resp.Provider, resp.Model, resp.PromptTokens, resp.CompletionTokens,
+		m.prices.Cost(resp), elapsed.Milliseconds())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] llm usage: %v\n", err)
+	}
+}
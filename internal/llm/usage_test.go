@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newUsageDB base output en mémoire avec le schéma du dépôt
+func newUsageDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1) // Une seule connexion: la base en mémoire lui est propre
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile(filepath.Join("..", "..", "schemas", "output.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	return db
+}
+
+// usageRow ligne llm_usage
+type usageRow struct {
+	provider, model                string
+	promptTokens, completionTokens int
+	cost                           float64
+}
+
+func usageRows(t *testing.T, db *sql.DB) []usageRow {
+	t.Helper()
+	rows, err := db.Query(`SELECT provider, model, prompt_tokens, completion_tokens, cost_usd FROM llm_usage ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var out []usageRow
+	for rows.Next() {
+		var r usageRow
+		if err := rows.Scan(&r.provider, &r.model, &r.promptTokens, &r.completionTokens, &r.cost); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestWithUsageRecordsTokensAndCost(t *testing.T) {
+	db := newUsageDB(t)
+	prices := Prices{ProviderClaude: {Prompt: 3, Completion: 15}}
+	// Mock: tokens = longueur / 4 (prompt 8 octets -> 2, réponse 12 octets -> 3)
+	client := WithUsage(&Mock{Name: ProviderClaude, Responses: []string{"abcdefghijkl"}}, db, prices)
+
+	if _, err := client.Complete(context.Background(), "12345678", Options{Model: "sonnet"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CompleteStream(context.Background(), client, "12345678", Options{}, func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := usageRows(t, db)
+	if len(rows) != 2 {
+		t.Fatalf("got %d usage rows, want 2 (Complete and Stream)", len(rows))
+	}
+	wantCost := (2*3.0 + 3*15.0) / 1e6
+	for i, want := range []string{"sonnet", "mock"} {
+		r := rows[i]
+		if r.provider != ProviderClaude || r.model != want || r.promptTokens != 2 || r.completionTokens != 3 {
+			t.Errorf("row %d = %+v", i, r)
+		}
+		if math.Abs(r.cost-wantCost) > 1e-12 {
+			t.Errorf("row %d cost = %g, want %g", i, r.cost, wantCost)
+		}
+	}
+}
+
+func TestWithUsageSkipsFailedCalls(t *testing.T) {
+	db := newUsageDB(t)
+	client := WithUsage(&Mock{Err: errors.New("boom")}, db, nil)
+
+	if _, err := client.Complete(context.Background(), "hello", Options{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if rows := usageRows(t, db); len(rows) != 0 {
+		t.Errorf("failed call recorded: %+v", rows)
+	}
+}
+
+func TestWithUsageChainRecordsServingProvider(t *testing.T) {
+	db := newUsageDB(t)
+	chain := NewChain(
+		&Mock{Name: ProviderClaude, Err: &APIError{Provider: ProviderClaude, Status: 503}},
+		&Mock{Name: ProviderGemini, Responses: []string{"ok"}},
+	)
+	if _, err := WithUsage(chain, db, nil).Complete(context.Background(), "hello", Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := usageRows(t, db)
+	if len(rows) != 1 || rows[0].provider != ProviderGemini {
+		t.Fatalf("rows = %+v, want one row for %s", rows, ProviderGemini)
+	}
+}
+
+func TestParsePrices(t *testing.T) {
+	prices, err := ParsePrices(`{"claude": {"prompt": 1, "completion": 2}, "local": {"prompt": 0.5, "completion": 0.5}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prices[ProviderClaude] != (Price{Prompt: 1, Completion: 2}) {
+		t.Errorf("claude = %+v, want override", prices[ProviderClaude])
+	}
+	if prices[ProviderGemini] != DefaultPrices[ProviderGemini] {
+		t.Errorf("gemini = %+v, want default", prices[ProviderGemini])
+	}
+	if got := prices.Cost(&Response{Provider: "unknown", PromptTokens: 1000}); got != 0 {
+		t.Errorf("cost without price = %g, want 0", got)
+	}
+
+	if _, err := ParsePrices("{not json"); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
@@ -3,10 +3,14 @@ package observability
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"os"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,19 +24,38 @@ type Collector struct {
 	// Métriques en mémoire pour batch write
 	latencies []float64
 	mu        sync.Mutex
+
+	minLogLevel int32 // Rang du niveau minimal écrit dans telemetry_logs (atomique)
 }
 
+// logLevels rang des niveaux de telemetry_logs, du plus verbeux au plus grave
+var logLevels = map[string]int32{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+// DefaultLogLevel niveau minimal de telemetry_logs quand telemetry.log_level est absent ou invalide
+const DefaultLogLevel = "info"
+
 // NewCollector crée un nouveau collecteur de métriques
 func NewCollector(lifecycleDB, metadataDB, outputDB *sql.DB) *Collector {
 	return &Collector{
-		db:         lifecycleDB,
-		metadataDB: metadataDB,
-		outputDB:   outputDB,
-		stopChan:   make(chan struct{}),
-		latencies:  make([]float64, 0, 1000),
+		db:          lifecycleDB,
+		metadataDB:  metadataDB,
+		outputDB:    outputDB,
+		stopChan:    make(chan struct{}),
+		latencies:   make([]float64, 0, 1000),
+		minLogLevel: logLevels[DefaultLogLevel],
 	}
 }
 
+// SetLogLevel fixe le niveau minimal écrit par Log (debug, info, warn, error)
+func (c *Collector) SetLogLevel(level string) error {
+	rank, ok := logLevels[strings.ToLower(strings.TrimSpace(level))]
+	if !ok {
+		return fmt.Errorf("unknown log level %q: expected debug, info, warn or error", level)
+	}
+	atomic.StoreInt32(&c.minLogLevel, rank)
+	return nil
+}
+
 // Start démarre la collecte de métriques
 func (c *Collector) Start(interval time.Duration) {
 	go c.collectLoop(interval)
@@ -55,17 +78,24 @@ func (c *Collector) collectLoop(interval time.Duration) {
 
 // collectSystemMetrics collecte les métriques système Go
 func (c *Collector) collectSystemMetrics() {
+	c.Flush()
+}
+
+// Flush persiste immédiatement la fenêtre de métriques courante
+// Retourne le nombre de latences incluses dans la fenêtre
+func (c *Collector) Flush() (int, error) {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
 	// Calculer percentiles si on a des latences
 	c.mu.Lock()
+	samples := len(c.latencies)
 	p50, p95, p99 := c.calculatePercentiles()
 	c.latencies = c.latencies[:0] // Reset
 	c.mu.Unlock()
 
 	// Persister en base
-	c.metadataDB.Exec(`
+	_, err := c.metadataDB.Exec(`
 		INSERT INTO system_metrics
 		(cpu_percent, memory_used_mb, heap_alloc_mb, heap_sys_mb,
 		 goroutines, gc_pause_ms, p50_latency_ms, p95_latency_ms, p99_latency_ms)
@@ -77,6 +107,7 @@ func (c *Collector) collectSystemMetrics() {
 		runtime.NumGoroutine(),
 		float64(m.PauseNs[(m.NumGC+255)%256])/1e6, // Dernière pause GC en ms
 		p50, p95, p99)
+	return samples, err
 }
 
 // calculatePercentiles calcule les percentiles des latences
@@ -161,28 +192,17 @@ func (c *Collector) UpdateHeartbeat(status string, requestsProcessed, requestsFa
 }
 
 // Log enregistre un log structuré
+// Les niveaux sous le seuil de SetLogLevel sont ignorés (niveau inconnu: toujours écrit)
 func (c *Collector) Log(level, message, logger string, traceID string, fields map[string]interface{}) {
+	if rank, ok := logLevels[level]; ok && rank < atomic.LoadInt32(&c.minLogLevel) {
+		return
+	}
+
 	fieldsJSON := "{}"
 	if fields != nil {
-		// Simple JSON encoding
-		fieldsJSON = "{"
-		first := true
-		for k, v := range fields {
-			if !first {
-				fieldsJSON += ","
-			}
-			fieldsJSON += `"` + k + `":`
-			switch val := v.(type) {
-			case string:
-				fieldsJSON += `"` + val + `"`
-			case int, int64, float64:
-				fieldsJSON += string(rune(val.(int)))
-			default:
-				fieldsJSON += `"` + string(rune(val.(int))) + `"`
-			}
-			first = false
+		if data, err := json.Marshal(fields); err == nil {
+			fieldsJSON = string(data)
 		}
-		fieldsJSON += "}"
 	}
 
 	c.db.Exec(`
@@ -191,6 +211,20 @@ func (c *Collector) Log(level, message, logger string, traceID string, fields ma
 		level, message, logger, traceID, fieldsJSON)
 }
 
+// PruneLogs supprime les entrées telemetry_logs plus anciennes que maxAge (0 = aucune purge)
+func (c *Collector) PruneLogs(maxAge time.Duration) (int64, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	res, err := c.db.Exec(`
+		DELETE FROM telemetry_logs WHERE created_at < ?`,
+		time.Now().Add(-maxAge).Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // RecordSecurityEvent enregistre un événement de sécurité
 func (c *Collector) RecordSecurityEvent(eventType, severity, sourceIP, userID, details string) {
 	c.db.Exec(`
@@ -228,9 +262,10 @@ func (c *Collector) TriggerPoisonPill(reason, triggeredBy string) error {
 	return err
 }
 
-// Stop arrête le collecteur
+// Stop arrête le collecteur et persiste la dernière fenêtre de latences
 func (c *Collector) Stop() {
 	close(c.stopChan)
+	c.Flush()
 }
 
 // AlertChecker vérifie les règles d'alerte
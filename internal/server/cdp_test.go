@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/horos/holow-mcp/internal/config"
+)
+
+// fakeCDP serveur DevTools minimal: une page, réponses de page retardées de delay
+type fakeCDP struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	methods []string // Commandes de page reçues (hors Target.*)
+}
+
+// startFakeCDP démarre le serveur et y pointe le CDPManager de ts (cdp_session_state.debug_port)
+func startFakeCDP(t *testing.T, ts *testServer, delay time.Duration) *fakeCDP {
+	t.Helper()
+	f := &fakeCDP{delay: delay}
+
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	if _, err := ts.db.LifecycleTools.Exec(`UPDATE cdp_session_state SET debug_port = ? WHERE id = 1`, port); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ts.cdpManager.Disconnect() })
+	return f
+}
+
+// calls retourne les commandes de page reçues
+func (f *fakeCDP) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.methods...)
+}
+
+func (f *fakeCDP) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/json/version" {
+		json.NewEncoder(w).Encode(map[string]string{
+			"webSocketDebuggerUrl": fmt.Sprintf("ws://%s/devtools/browser/fake", r.Host),
+		})
+		return
+	}
+
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	reply := func(id int64, result interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.WriteJSON(map[string]interface{}{"id": id, "result": result})
+	}
+
+	for {
+		var msg struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Method {
+		case "Target.getTargets":
+			reply(msg.ID, map[string]interface{}{
+				"targetInfos": []map[string]string{{"targetId": "page-1", "type": "page"}},
+			})
+		case "Target.attachToTarget":
+			reply(msg.ID, map[string]string{"sessionId": "session-1"})
+		default:
+			f.mu.Lock()
+			f.methods = append(f.methods, msg.Method)
+			f.mu.Unlock()
+			go func(id int64) {
+				time.Sleep(f.delay)
+				reply(id, map[string]interface{}{})
+			}(msg.ID)
+		}
+	}
+}
+
+func TestCDPCallStopsAtWallTimeBudget(t *testing.T) {
+	ts := newTestServer(t)
+	cdp := startFakeCDP(t, ts, 10*time.Second)
+	if err := config.Save(ts.db.LifecycleCore, configMaxToolWallTime, "1"); err != nil {
+		t.Fatal(err)
+	}
+	ts.addSQLTool(t, "slow_cdp", `SELECT cdp_call('Runtime.evaluate', '{"expression":"1"}') AS result`)
+
+	start := time.Now()
+	resp := ts.call(t, "tools/call", map[string]interface{}{"name": "slow_cdp", "arguments": map[string]interface{}{}})
+	elapsed := time.Since(start)
+
+	if resp.Error == nil {
+		t.Fatalf("expected budget error, got %v", resp.Result)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("cdp_call ran %v, budget is 1s", elapsed)
+	}
+	if calls := cdp.calls(); len(calls) != 1 || calls[0] != "Runtime.evaluate" {
+		t.Errorf("page calls = %q, want one Runtime.evaluate", calls)
+	}
+}
+
+func TestCDPStepIsNotRetried(t *testing.T) {
+	ts := newTestServer(t)
+	cdp := startFakeCDP(t, ts, 0)
+	if err := config.Save(ts.db.LifecycleCore, configRetryBaseDelay, "0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Le step 2 échoue avec une erreur classée transitoire, après le cdp_call du step 1
+	if _, err := ts.db.LifecycleTools.Exec(`
+		INSERT INTO tool_definitions (name, description, input_schema, category, retry_policy, max_retries, created_by)
+		VALUES ('click_then_fail', 'test tool', '{"type":"object","properties":{}}', 'data', 'fixed', 3, 'user')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.db.LifecycleTools.Exec(`
+		INSERT INTO tool_implementations (tool_name, step_order, step_name, step_type, sql_template) VALUES
+		('click_then_fail', 1, 'click', 'sql', 'SELECT cdp_call(''Input.dispatchMouseEvent'', ''{}'') AS result'),
+		('click_then_fail', 2, 'read', 'sql', 'SELECT * FROM "database is locked"')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.tools.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := ts.call(t, "tools/call", map[string]interface{}{"name": "click_then_fail", "arguments": map[string]interface{}{}})
+	if resp.Error == nil {
+		t.Fatalf("expected step error, got %v", resp.Result)
+	}
+	if data, _ := resp.Error.Data.(map[string]interface{}); data["attempts"] != nil {
+		t.Errorf("attempts = %v, want no retry", data["attempts"])
+	}
+	if calls := cdp.calls(); len(calls) != 1 {
+		t.Errorf("page calls = %q, want the click sent once", calls)
+	}
+}
+
+func TestCDPCallLoggedWithTraceID(t *testing.T) {
+	ts := newTestServer(t)
+	startFakeCDP(t, ts, 0)
+	if err := ts.metrics.SetLogLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	ts.addSQLTool(t, "eval_cdp", `SELECT cdp_call('Runtime.evaluate', '{"expression":"1"}') AS result`)
+	ts.callTool(t, "eval_cdp", nil)
+
+	var cdpTrace, toolTrace string
+	if err := ts.db.LifecycleCore.QueryRow(`
+		SELECT trace_id FROM telemetry_logs
+		WHERE message = 'cdp call' AND json_extract(fields, '$.method') = 'Runtime.evaluate'`).Scan(&cdpTrace); err != nil {
+		t.Fatalf("cdp call log: %v", err)
+	}
+	if err := ts.db.LifecycleCore.QueryRow(`
+		SELECT trace_id FROM telemetry_logs
+		WHERE message = 'tool call' AND json_extract(fields, '$.tool') = 'eval_cdp'`).Scan(&toolTrace); err != nil {
+		t.Fatalf("tool call log: %v", err)
+	}
+	if cdpTrace == "" || cdpTrace != toolTrace {
+		t.Errorf("cdp call trace_id = %q, want the tools/call trace %q", cdpTrace, toolTrace)
+	}
+}
@@ -0,0 +1,108 @@
+// Package server - Mode compact des résultats de tools (économie de tokens)
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// compactArg argument réservé de tools/call activant le mode compact
+const compactArg = "_compact"
+
+// Limites appliquées en mode compact
+const (
+	compactMaxString  = 500  // Caractères conservés d'une longue chaîne
+	compactMaxArray   = 20   // Éléments conservés d'un tableau
+	compactBinaryMinB = 1024 // Taille à partir de laquelle une chaîne base64 est omise
+)
+
+// base64Regex reconnaît une chaîne base64 (sans espaces)
+// Le champ "base64" des captures browser est omis quelle que soit sa taille
+var base64Regex = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+
+// popCompactArg retire _compact des arguments et indique s'il est actif
+func popCompactArg(args map[string]interface{}) bool {
+	raw, ok := args[compactArg]
+	if !ok {
+		return false
+	}
+	delete(args, compactArg)
+	enabled, _ := raw.(bool)
+	return enabled
+}
+
+// compactResult allège un résultat: binaires omis, chaînes et tableaux tronqués
+// ref pointe vers le résultat complet (table, hash, chemin) quand il est persisté
+func compactResult(result interface{}, ref map[string]interface{}) interface{} {
+	// Passer par JSON pour travailler sur une structure générique
+	data, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return result
+	}
+
+	omitted := 0
+	compacted := compactValue(generic, "", &omitted)
+	if omitted == 0 {
+		return result
+	}
+
+	info := map[string]interface{}{
+		"omitted_fields": omitted,
+		"full_size":      len(data),
+	}
+	if ref != nil {
+		info["full_result"] = ref
+	}
+
+	if m, ok := compacted.(map[string]interface{}); ok {
+		m["_compacted"] = info
+		return m
+	}
+	return map[string]interface{}{
+		"result":     compacted,
+		"_compacted": info,
+	}
+}
+
+// compactValue applique les limites récursivement; omitted compte les champs allégés
+func compactValue(v interface{}, key string, omitted *int) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = compactValue(child, k, omitted)
+		}
+		return val
+
+	case []interface{}:
+		if len(val) > compactMaxArray {
+			*omitted++
+			kept := make([]interface{}, 0, compactMaxArray+1)
+			for _, child := range val[:compactMaxArray] {
+				kept = append(kept, compactValue(child, "", omitted))
+			}
+			return append(kept, fmt.Sprintf("[%d more items omitted]", len(val)-compactMaxArray))
+		}
+		for i, child := range val {
+			val[i] = compactValue(child, "", omitted)
+		}
+		return val
+
+	case string:
+		if key == "base64" || (len(val) >= compactBinaryMinB && base64Regex.MatchString(val)) {
+			*omitted++
+			return fmt.Sprintf("[binary omitted, %d bytes]", len(val))
+		}
+		runes := []rune(val)
+		if len(runes) > compactMaxString {
+			*omitted++
+			return string(runes[:compactMaxString]) + fmt.Sprintf("...[%d chars truncated]", len(runes)-compactMaxString)
+		}
+		return val
+	}
+	return v
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContentItemsMultiRowSelect(t *testing.T) {
+	ts := newTestServer(t)
+	ts.addSQLTool(t, "multi_content", `
+		SELECT 'text' AS type, 'first' AS text, NULL AS resource
+		UNION ALL
+		SELECT 'text', 'second', NULL
+		UNION ALL
+		SELECT 'resource', NULL, json_object('uri', 'file:///notes.txt', 'text', 'notes')`)
+
+	content := ts.callTool(t, "multi_content", nil)
+	want := []interface{}{
+		map[string]interface{}{"type": "text", "text": "first"},
+		map[string]interface{}{"type": "text", "text": "second"},
+		map[string]interface{}{"type": "resource", "resource": map[string]interface{}{"uri": "file:///notes.txt", "text": "notes"}},
+	}
+	if !reflect.DeepEqual(content, want) {
+		t.Errorf("content = %v, want %v", content, want)
+	}
+}
+
+func TestContentItemsFallbackToText(t *testing.T) {
+	ts := newTestServer(t)
+	ts.addSQLTool(t, "plain_rows", `SELECT 1 AS id, 'a' AS name UNION ALL SELECT 2, 'b'`)
+
+	content := ts.callTool(t, "plain_rows", nil)
+	if len(content) != 1 {
+		t.Fatalf("content has %d items, want a single JSON text block: %v", len(content), content)
+	}
+	item, _ := content[0].(map[string]interface{})
+	if item["type"] != "text" {
+		t.Errorf("content[0] = %v, want a text block", item)
+	}
+}
+
+func TestContentItemsDataRowsWithTypeColumn(t *testing.T) {
+	ts := newTestServer(t)
+	ts.addSQLTool(t, "typed_rows", `
+		SELECT 1 AS id, 'text' AS type, 'hello' AS text
+		UNION ALL
+		SELECT 2, 'text', 'world'`)
+
+	content := ts.callTool(t, "typed_rows", nil)
+	if len(content) != 1 {
+		t.Fatalf("content has %d items, want a single JSON text block: %v", len(content), content)
+	}
+	item, _ := content[0].(map[string]interface{})
+	text, _ := item["text"].(string)
+	if !strings.Contains(text, `"id":1`) || !strings.Contains(text, `"id":2`) {
+		t.Errorf("content[0] = %v, want the rows as JSON with their id column", item)
+	}
+}
+
+func TestContentItems(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		want   int // Nombre de blocs, -1 = non reconnu
+	}{
+		{"bare array", []interface{}{map[string]interface{}{"type": "text", "text": "x"}}, 1},
+		{"content key", map[string]interface{}{"content": []interface{}{map[string]interface{}{"type": "image", "data": "AA==", "mimeType": "image/png"}}}, 1},
+		{"rows", []map[string]interface{}{{"type": "text", "text": "a", "data": nil}, {"type": "text", "text": "b"}}, 2},
+		{"row missing field", []map[string]interface{}{{"type": "image", "data": "AA=="}}, -1},
+		{"row with extra column", []map[string]interface{}{{"type": "text", "text": "a"}, {"type": "text", "text": "b", "id": int64(2)}}, -1},
+		{"unknown type", []interface{}{map[string]interface{}{"type": "audio", "data": "AA=="}}, -1},
+		{"empty rows", []map[string]interface{}{}, -1},
+		{"scalar", "text", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, ok := contentItems(tt.result)
+			if tt.want < 0 {
+				if ok {
+					t.Errorf("contentItems() = %v, want not recognized", items)
+				}
+				return
+			}
+			if !ok || len(items) != tt.want {
+				t.Errorf("contentItems() = %v, %v, want %d items", items, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package server - Codes d'erreur JSON-RPC spécifiques à HOLOW
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/circuit"
+)
+
+// Codes d'erreur serveur (plage réservée -32000 à -32099 de JSON-RPC)
+const (
+	ErrCodeToolFailed   = -32000 // Échec générique d'exécution
+	ErrCodeTimeout      = -32001 // Délai dépassé
+	ErrCodeRateLimited  = -32002 // Trop de requêtes
+	ErrCodeCircuitOpen  = -32003 // Circuit breaker ouvert
+	ErrCodeToolNotFound = -32004 // Tool inconnu
+	ErrCodeValidation   = -32005 // Paramètres ou validation SQL refusés
+	ErrCodeCancelled    = -32006 // Requête annulée (cancel_request, notifications/cancelled)
+)
+
+// Catégories d'échec d'un step
+const (
+	stepErrParams     = "params"
+	stepErrValidation = "validation"
+	stepErrExecution  = "execution"
+)
+
+// StepError décrit l'échec d'un step de tool SQL
+type StepError struct {
+	Step     string
+	StepType string
+	Kind     string
+	Rule     string // Template SQL de la règle de validation en échec
+	Err      error
+
+	AfterWrite bool // Un step précédent a déjà écrit: rejouer le tool dupliquerait ses effets
+}
+
+// Error conserve le format historique "<kind> failed at step <name>: <err>"
+func (e *StepError) Error() string {
+	switch e.Kind {
+	case stepErrParams:
+		return "parameter substitution failed at step " + e.Step + ": " + e.Err.Error()
+	case stepErrValidation:
+		return "validation failed at step " + e.Step + ": " + e.Err.Error()
+	default:
+		return "SQL execution failed at step " + e.Step + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap expose l'erreur sous-jacente
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// isTimeout détecte les erreurs de délai (contexte ou timeouts CDP/SQLite)
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out")
+}
+
+// Issue d'un tools/call pour le circuit breaker
+const (
+	breakerSuccess = "success"
+	breakerFailure = "failure"
+	breakerIgnored = "ignored"
+)
+
+// breakerOutcome classe le résultat d'un tools/call pour le circuit breaker:
+// timeouts et erreurs d'exécution sont des échecs du tool; paramètres ou validation refusés
+// (faute de l'appelant) et annulations (cancel_request, notifications/cancelled) ne comptent pas
+func breakerOutcome(err error) string {
+	if err == nil {
+		return breakerSuccess
+	}
+	var stepErr *StepError
+	if errors.As(err, &stepErr) && (stepErr.Kind == stepErrParams || stepErr.Kind == stepErrValidation) {
+		return breakerIgnored
+	}
+	if errors.Is(err, context.Canceled) {
+		return breakerIgnored
+	}
+	return breakerFailure
+}
+
+// toolError construit une RPCError avec un code précis et un contexte structuré
+func toolError(toolName, message string, err error) *RPCError {
+	code := ErrCodeToolFailed
+	data := map[string]interface{}{
+		"tool":  toolName,
+		"error": err.Error(),
+	}
+
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		data["step"] = stepErr.Step
+		data["step_type"] = stepErr.StepType
+		data["cause"] = stepErr.Err.Error()
+		if stepErr.Kind == stepErrParams || stepErr.Kind == stepErrValidation {
+			code = ErrCodeValidation
+		}
+		data["explain"] = explainStepError(stepErr)
+	}
+
+	if code == ErrCodeToolFailed && isTimeout(err) {
+		code = ErrCodeTimeout
+	}
+	if code == ErrCodeToolFailed && errors.Is(err, context.Canceled) {
+		code = ErrCodeCancelled
+	}
+
+	return &RPCError{Code: code, Message: message, Data: data}
+}
+
+// explainStepError détaille la règle ou le paramètre à l'origine d'un refus
+func explainStepError(e *StepError) map[string]interface{} {
+	explain := map[string]interface{}{
+		"reason": e.Kind,
+		"step":   e.Step,
+	}
+	switch e.Kind {
+	case stepErrValidation:
+		explain["rule"] = e.Rule
+		explain["detail"] = fmt.Sprintf("validation step %q rejected the arguments: %v", e.Step, e.Err)
+		explain["retry"] = "fix the arguments to satisfy the rule, retrying as-is will fail again"
+	case stepErrParams:
+		explain["detail"] = fmt.Sprintf("arguments could not be substituted into step %q: %v", e.Step, e.Err)
+		explain["retry"] = "fix the argument types or values, retrying as-is will fail again"
+	default:
+		explain["detail"] = fmt.Sprintf("step %q failed during execution: %v", e.Step, e.Err)
+	}
+	return explain
+}
+
+// circuitOpenError construit le refus d'un circuit breaker avec son état et le délai avant retry
+func circuitOpenError(toolName string, breaker *circuit.Breaker, err error) *RPCError {
+	retryAfter := breaker.RetryAfter()
+	explain := map[string]interface{}{
+		"reason":              "circuit_open",
+		"breaker":             breaker.Stats(),
+		"retry_after_seconds": int(math.Ceil(retryAfter.Seconds())),
+	}
+	if breaker.State() == circuit.StateOpen {
+		explain["retry_at"] = time.Now().Add(retryAfter).UTC().Format(time.RFC3339)
+		explain["detail"] = "too many consecutive failures, calls are refused until the cooldown elapses"
+	} else {
+		explain["detail"] = "half-open probe calls are in flight, retry once they complete"
+	}
+
+	return &RPCError{Code: ErrCodeCircuitOpen, Message: "Circuit breaker open", Data: map[string]interface{}{
+		"tool":    toolName,
+		"state":   breaker.State(),
+		"error":   err.Error(),
+		"explain": explain,
+	}}
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testServer serveur sur bases en mémoire, requêtes traitées de façon synchrone via handleRequest
+type testServer struct {
+	*Server
+	out *bytes.Buffer
+}
+
+// newTestServer ouvre un serveur sur les schémas du dépôt, sans découverte système
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	out := &bytes.Buffer{}
+	srv, err := NewServerWithOptions(t.TempDir(), Options{
+		Stdin:         strings.NewReader(""),
+		Stdout:        out,
+		InMemory:      true,
+		SchemasPath:   filepath.Join("..", "..", "schemas"),
+		SkipDiscovery: true,
+	})
+	if err != nil {
+		t.Fatalf("NewServerWithOptions: %v", err)
+	}
+	t.Cleanup(func() { srv.db.Close() })
+
+	// Chaque test rejoue librement les mêmes requêtes
+	srv.cfg.IdempotenceEnabled = false
+	if err := srv.tools.Load(); err != nil {
+		t.Fatalf("load tools: %v", err)
+	}
+	return &testServer{Server: srv, out: out}
+}
+
+// call envoie une requête JSON-RPC et décode la réponse écrite sur stdout
+func (ts *testServer) call(t *testing.T, method string, params interface{}) JSONRPCResponse {
+	t.Helper()
+	req := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method}
+	if params != nil {
+		req["params"] = params
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts.send(t, data)
+}
+
+// send traite une ligne brute et décode la réponse
+func (ts *testServer) send(t *testing.T, line []byte) JSONRPCResponse {
+	t.Helper()
+	ts.out.Reset()
+	ts.handleRequest(line)
+
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(ts.out.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", ts.out.String(), err)
+	}
+	return resp
+}
+
+// toolNames noms des tools d'une réponse tools/list
+func toolNames(t *testing.T, resp JSONRPCResponse) []string {
+	t.Helper()
+	result, _ := resp.Result.(map[string]interface{})
+	tools, _ := result["tools"].([]interface{})
+	var names []string
+	for _, tool := range tools {
+		if m, ok := tool.(map[string]interface{}); ok {
+			name, _ := m["name"].(string)
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestHandleRequestMethods(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name      string
+		line      string
+		wantCode  int    // 0 = succès attendu
+		wantField string // Clé attendue dans le résultat
+	}{
+		{"initialize", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, 0, "protocolVersion"},
+		{"tools/list", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, 0, "tools"},
+		{"resources/list", `{"jsonrpc":"2.0","id":3,"method":"resources/list"}`, 0, "resources"},
+		{"prompts/list", `{"jsonrpc":"2.0","id":4,"method":"prompts/list"}`, 0, "prompts"},
+		{"unknown method", `{"jsonrpc":"2.0","id":5,"method":"nope"}`, -32601, ""},
+		{"parse error", `{"jsonrpc":`, -32700, ""},
+		{"unknown tool", `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"nope","arguments":{}}}`, -32004, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ts.send(t, []byte(tt.line))
+			if tt.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.wantCode {
+					t.Fatalf("error = %+v, want code %d", resp.Error, tt.wantCode)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %+v", resp.Error)
+			}
+			result, ok := resp.Result.(map[string]interface{})
+			if !ok {
+				t.Fatalf("result = %#v, want object", resp.Result)
+			}
+			if _, ok := result[tt.wantField]; !ok {
+				t.Errorf("result lacks %q: %v", tt.wantField, result)
+			}
+		})
+	}
+}
+
+func TestMemoryServersAreIsolated(t *testing.T) {
+	a, b := newTestServer(t), newTestServer(t)
+
+	if _, err := a.db.LifecycleTools.Exec(`CREATE TABLE isolation_probe (id INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := b.db.LifecycleTools.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'isolation_probe'`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Error("table created in one in-memory server is visible in another")
+	}
+
+	// Une base en mémoire survit à la fermeture des connexions inactives du pool
+	a.db.LifecycleTools.SetMaxIdleConns(0)
+	a.db.LifecycleTools.SetMaxIdleConns(2)
+	if err := a.db.LifecycleTools.QueryRow(`SELECT COUNT(*) FROM isolation_probe`).Scan(&n); err != nil {
+		t.Errorf("in-memory database lost after idle connections closed: %v", err)
+	}
+}
+
+// addSQLTool enregistre un tool SQL à un step puis recharge le catalogue
+func (ts *testServer) addSQLTool(t *testing.T, name, sqlTemplate string) {
+	t.Helper()
+	if _, err := ts.db.LifecycleTools.Exec(`
+		INSERT INTO tool_definitions (name, description, input_schema, category, retry_policy, created_by)
+		VALUES (?, 'test tool', '{"type":"object","properties":{}}', 'data', 'none', 'user')`, name); err != nil {
+		t.Fatalf("insert tool %s: %v", name, err)
+	}
+	if _, err := ts.db.LifecycleTools.Exec(`
+		INSERT INTO tool_implementations (tool_name, step_order, step_name, step_type, sql_template)
+		VALUES (?, 1, 'main', 'sql', ?)`, name, sqlTemplate); err != nil {
+		t.Fatalf("insert step %s: %v", name, err)
+	}
+	if err := ts.tools.Load(); err != nil {
+		t.Fatalf("reload tools: %v", err)
+	}
+}
+
+// callTool appelle un tool via tools/call et retourne le tableau content de la réponse
+func (ts *testServer) callTool(t *testing.T, name string, args map[string]interface{}) []interface{} {
+	t.Helper()
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	resp := ts.call(t, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	if resp.Error != nil {
+		t.Fatalf("tools/call %s: %+v", name, resp.Error)
+	}
+	result, _ := resp.Result.(map[string]interface{})
+	content, ok := result["content"].([]interface{})
+	if !ok {
+		t.Fatalf("tools/call %s: result lacks content: %v", name, resp.Result)
+	}
+	return content
+}
@@ -0,0 +1,60 @@
+// Package server - Sonde de santé hors MCP (flag -healthcheck, probes Docker/k8s/systemd)
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/config"
+	"github.com/horos/holow-mcp/internal/database"
+)
+
+// heartbeatStaleFactor nombre d'intervalles heartbeat manqués avant de déclarer le serveur bloqué
+const heartbeatStaleFactor = 3
+
+// HealthCheck vérifie les 6 bases (ping, quick_check) et le heartbeat du serveur:
+// statut running, rafraîchi depuis moins de 3 intervalles heartbeat.interval_seconds
+// Retourne un résumé d'une ligne, ou une erreur décrivant le problème
+func HealthCheck(basePath string) (string, error) {
+	db, err := database.NewManager(basePath, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to open databases: %w", err)
+	}
+	defer db.Close()
+
+	if healthy, issues := db.QuickHealthCheck(); !healthy {
+		return "", fmt.Errorf("databases: %s", strings.Join(issues, ", "))
+	}
+
+	cfg, err := config.Load(db.LifecycleCore)
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	maxAge := int64(heartbeatStaleFactor * cfg.HeartbeatIntervalSecs)
+
+	var status string
+	var pid, lastBeat, processed, failed, toolsLoaded int64
+	err = db.Output.QueryRow(`
+		SELECT status, pid, last_heartbeat_at, requests_processed, requests_failed, tools_loaded
+		FROM heartbeat WHERE id = 1`).Scan(&status, &pid, &lastBeat, &processed, &failed, &toolsLoaded)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("no heartbeat (server never started)")
+	}
+	if err != nil {
+		return "", fmt.Errorf("heartbeat: %w", err)
+	}
+
+	now := time.Now().Unix()
+	age := now - lastBeat
+	if status != "running" {
+		return "", fmt.Errorf("server %s (pid %d, last heartbeat %ds ago)", status, pid, age)
+	}
+	if age > maxAge {
+		return "", fmt.Errorf("stale heartbeat: last %ds ago (max %ds, pid %d)", age, maxAge, pid)
+	}
+
+	return fmt.Sprintf("running (pid %d, heartbeat %ds ago, %d tools, %d requests, %d failed)",
+		pid, age, toolsLoaded, processed, failed), nil
+}
@@ -0,0 +1,66 @@
+// Package server - Arrêt après une période sans requête (server.idle_shutdown_seconds)
+package server
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// idleCheckInterval période de vérification de l'inactivité
+const idleCheckInterval = time.Second
+
+// SetIdleShutdown arrête le serveur après idle sans requête (0 = jamais)
+// À appeler avant Start; prioritaire sur server.idle_shutdown_seconds
+func (s *Server) SetIdleShutdown(idle time.Duration) {
+	if idle < 0 {
+		idle = 0
+	}
+	s.idleShutdown = idle
+}
+
+// IdleShutdown retourne le délai d'inactivité avant arrêt (0 = jamais)
+func (s *Server) IdleShutdown() time.Duration {
+	return s.idleShutdown
+}
+
+// beginRequest marque le début du traitement d'un message client
+func (s *Server) beginRequest() {
+	atomic.AddInt64(&s.activeRequests, 1)
+	atomic.StoreInt64(&s.lastRequestAt, time.Now().UnixNano())
+}
+
+// endRequest marque la fin du traitement: le délai d'inactivité court à partir de là
+func (s *Server) endRequest() {
+	atomic.StoreInt64(&s.lastRequestAt, time.Now().UnixNano())
+	atomic.AddInt64(&s.activeRequests, -1)
+}
+
+// idleFor retourne la durée écoulée depuis la dernière requête, 0 si une requête est en cours
+// Seuls les messages lus sur stdin comptent: heartbeat et boucles de fond ne sont pas de l'activité
+func (s *Server) idleFor(now time.Time) time.Duration {
+	if atomic.LoadInt64(&s.activeRequests) > 0 {
+		return 0
+	}
+	return now.Sub(time.Unix(0, atomic.LoadInt64(&s.lastRequestAt)))
+}
+
+// idleLoop déclenche un arrêt gracieux quand aucune requête n'a été reçue depuis idleShutdown
+func (s *Server) idleLoop() {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.shutdownChan:
+			return
+		case now := <-ticker.C:
+			if idle := s.idleFor(now); idle >= s.idleShutdown {
+				fmt.Fprintf(os.Stderr, "No request for %s (server.idle_shutdown_seconds) - shutting down\n", idle.Round(time.Second))
+				go s.Shutdown()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package server - Registre des requêtes JSON-RPC en cours
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// inflightRequest décrit une requête en cours de traitement
+type inflightRequest struct {
+	seq     uint64
+	id      string // ID JSON-RPC formaté
+	method  string
+	tool    string // Nom du tool pour tools/call
+	traceID string
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+// inflightRegistry suit les requêtes en cours pour list_inflight et cancel_request
+type inflightRegistry struct {
+	mu       sync.Mutex
+	nextSeq  uint64
+	requests map[uint64]*inflightRequest
+}
+
+// newInflightRegistry crée un registre vide
+func newInflightRegistry() *inflightRegistry {
+	return &inflightRegistry{requests: make(map[uint64]*inflightRequest)}
+}
+
+// add enregistre une requête et retourne la fonction à appeler à sa fin
+func (r *inflightRegistry) add(req *inflightRequest) func() {
+	r.mu.Lock()
+	r.nextSeq++
+	req.seq = r.nextSeq
+	r.requests[req.seq] = req
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.requests, req.seq)
+		r.mu.Unlock()
+	}
+}
+
+// list retourne les requêtes en cours, les plus anciennes d'abord
+func (r *inflightRegistry) list() []map[string]interface{} {
+	r.mu.Lock()
+	reqs := make([]*inflightRequest, 0, len(r.requests))
+	for _, req := range r.requests {
+		reqs = append(reqs, req)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].seq < reqs[j].seq })
+
+	result := make([]map[string]interface{}, 0, len(reqs))
+	for _, req := range reqs {
+		entry := map[string]interface{}{
+			"id":         req.id,
+			"method":     req.method,
+			"trace_id":   req.traceID,
+			"started_at": req.started.UTC().Format(time.RFC3339),
+			"elapsed_ms": time.Since(req.started).Milliseconds(),
+		}
+		if req.tool != "" {
+			entry["tool"] = req.tool
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// cancel annule le contexte des requêtes portant cet ID et retourne leur nombre
+func (r *inflightRegistry) cancel(id string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancelled := 0
+	for _, req := range r.requests {
+		if req.id == id {
+			req.cancel()
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+// formatRequestID normalise un ID JSON-RPC (nombre ou chaîne) pour le registre
+func formatRequestID(id interface{}) string {
+	if f, ok := id.(float64); ok && f == float64(int64(f)) {
+		return fmt.Sprintf("%d", int64(f))
+	}
+	return fmt.Sprintf("%v", id)
+}
+
+// toolNameOf extrait le nom du tool des paramètres d'un tools/call
+func toolNameOf(params json.RawMessage) string {
+	var p struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(params, &p)
+	return p.Name
+}
+
+// ListInflight expose les requêtes en cours (action brainloop list_inflight)
+func (s *Server) ListInflight() []map[string]interface{} {
+	return s.inflight.list()
+}
+
+// CancelRequest annule une requête en cours par son ID JSON-RPC (action brainloop cancel_request)
+func (s *Server) CancelRequest(id string) int {
+	return s.inflight.cancel(id)
+}
+
+// handleCancelled traite la notification MCP notifications/cancelled
+func (s *Server) handleCancelled(params json.RawMessage) {
+	var p struct {
+		RequestID interface{} `json:"requestId"`
+		Reason    string      `json:"reason"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.RequestID == nil {
+		return
+	}
+	id := formatRequestID(p.RequestID)
+	if n := s.inflight.cancel(id); n > 0 {
+		s.metrics.Log("info", "request cancelled", "server", "", map[string]interface{}{
+			"id":     id,
+			"reason": p.Reason,
+		})
+	}
+}
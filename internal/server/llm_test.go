@@ -0,0 +1,23 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/horos/holow-mcp/internal/config"
+)
+
+func TestLLMProviderOrder(t *testing.T) {
+	ts := newTestServer(t)
+
+	if got, want := ts.llmProviderOrder(), []string{"claude", "gemini", "cerebras"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default order = %q, want %q", got, want)
+	}
+
+	if err := config.Save(ts.db.LifecycleCore, configLLMProviderOrder, " cerebras, ,claude "); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ts.llmProviderOrder(), []string{"cerebras", "claude"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/horos/holow-mcp/internal/config"
+)
+
+func TestSubstituteParams(t *testing.T) {
+	ts := newTestServer(t)
+	if err := config.Save(ts.db.LifecycleCore, configEnvAllowlist, "HOLOW_TEST_ALLOWED"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOLOW_TEST_ALLOWED", "it's")
+	t.Setenv("HOLOW_TEST_DENIED", "secret")
+
+	tests := []struct {
+		name     string
+		template string
+		args     map[string]interface{}
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "unannotated quotes",
+			template: "SELECT '{{name}}'",
+			args:     map[string]interface{}{"name": "O'Brien"},
+			want:     "SELECT 'O''Brien'",
+		},
+		{
+			name:     "sql annotation",
+			template: "SELECT '{{name:sql}}'",
+			args:     map[string]interface{}{"name": "x'; DROP TABLE tools; --"},
+			want:     "SELECT 'x''; DROP TABLE tools; --'",
+		},
+		{
+			name:     "nul bytes stripped",
+			template: "SELECT '{{name}}'",
+			args:     map[string]interface{}{"name": "a\x00b"},
+			want:     "SELECT 'ab'",
+		},
+		{
+			name:     "js annotation",
+			template: "SELECT '{{q:js}}'",
+			args:     map[string]interface{}{"q": "say \"hi\"\nit's"},
+			want:     `SELECT 'say \"hi\"\nit''s'`,
+		},
+		{
+			name:     "js context guessed",
+			template: `SELECT json_object('expression', 'document.title = "{{t}}"')`,
+			args:     map[string]interface{}{"t": `a"b`},
+			want:     `SELECT json_object('expression', 'document.title = "a\"b"')`,
+		},
+		{
+			name:     "int from number",
+			template: "LIMIT {{n:int}}",
+			args:     map[string]interface{}{"n": float64(42)},
+			want:     "LIMIT 42",
+		},
+		{
+			name:     "int from string",
+			template: "LIMIT {{n:int}}",
+			args:     map[string]interface{}{"n": " 7 "},
+			want:     "LIMIT 7",
+		},
+		{
+			name:     "int rejects fraction",
+			template: "LIMIT {{n:int}}",
+			args:     map[string]interface{}{"n": 1.5},
+			wantErr:  "expected integer",
+		},
+		{
+			name:     "int rejects injection",
+			template: "LIMIT {{n:int}}",
+			args:     map[string]interface{}{"n": "1; DROP TABLE tools"},
+			wantErr:  "expected integer",
+		},
+		{
+			name:     "num",
+			template: "WHERE score > {{s:num}}",
+			args:     map[string]interface{}{"s": "2.5"},
+			want:     "WHERE score > 2.5",
+		},
+		{
+			name:     "num rejects text",
+			template: "WHERE score > {{s:num}}",
+			args:     map[string]interface{}{"s": "abc"},
+			wantErr:  "expected number",
+		},
+		{
+			name:     "bool true",
+			template: "WHERE active = {{b:bool}}",
+			args:     map[string]interface{}{"b": true},
+			want:     "WHERE active = 1",
+		},
+		{
+			name:     "bool from string",
+			template: "WHERE active = {{b:bool}}",
+			args:     map[string]interface{}{"b": "false"},
+			want:     "WHERE active = 0",
+		},
+		{
+			name:     "bool rejects text",
+			template: "WHERE active = {{b:bool}}",
+			args:     map[string]interface{}{"b": "yes"},
+			wantErr:  "expected boolean",
+		},
+		{
+			name:     "unknown annotation",
+			template: "SELECT {{x:raw}}",
+			args:     map[string]interface{}{"x": "1"},
+			wantErr:  `unknown type annotation "raw"`,
+		},
+		{
+			name:     "missing param becomes empty",
+			template: "SELECT '{{missing}}'",
+			args:     map[string]interface{}{},
+			want:     "SELECT ''",
+		},
+		{
+			name:     "env allowed",
+			template: "SELECT '{{env:HOLOW_TEST_ALLOWED}}'",
+			want:     "SELECT 'it''s'",
+		},
+		{
+			name:     "env not in allowlist",
+			template: "SELECT '{{env:HOLOW_TEST_DENIED}}'",
+			wantErr:  "not in " + configEnvAllowlist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ts.substituteParams(tt.template, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("substituteParams(%q) error = %v, want %q", tt.template, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("substituteParams(%q): %v", tt.template, err)
+			}
+			if got != tt.want {
+				t.Errorf("substituteParams(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeParamTruncatesLongValues(t *testing.T) {
+	got, err := escapeParam("v", strings.Repeat("a", maxParamValueLen+10), "", "SELECT '{{v}}'", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != maxParamValueLen {
+		t.Errorf("len = %d, want %d", len(got), maxParamValueLen)
+	}
+}
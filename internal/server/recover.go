@@ -0,0 +1,172 @@
+// Package server - Remise en service d'un tool en échec (recover_tool)
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/horos/holow-mcp/internal/tools"
+)
+
+// RecoverTool remet un tool en service après correction de la cause de ses échecs:
+// ferme son circuit breaker, relance (requeue) ou abandonne ses retries épuisés et ses entrées
+// dead_letter_queue non résolues, puis le réactive s'il était désactivé
+func (s *Server) RecoverTool(name string, requeue bool) (map[string]interface{}, error) {
+	var enabled int
+	err := s.db.LifecycleTools.QueryRow(`SELECT enabled FROM tool_definitions WHERE name = ?`, name).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous := s.circuits.Reset(name)
+
+	retried, err := s.recoverRetryQueue(name, requeue)
+	if err != nil {
+		return nil, fmt.Errorf("retry_queue: %w", err)
+	}
+	dlq, err := s.recoverDeadLetters(name, requeue)
+	if err != nil {
+		return nil, fmt.Errorf("dead_letter_queue: %w", err)
+	}
+
+	reEnabled := false
+	if enabled == 0 {
+		if err := s.reEnableTool(name); err != nil {
+			return nil, fmt.Errorf("re-enable tool: %w", err)
+		}
+		s.tools.ForceReload()
+		reEnabled = true
+	}
+
+	mode := "clear"
+	if requeue {
+		mode = "requeue"
+	}
+	return map[string]interface{}{
+		"success":                true,
+		"action":                 "recover_tool",
+		"name":                   name,
+		"mode":                   mode,
+		"circuit_previous_state": string(previous),
+		"retries":                retried,
+		"dead_letters":           dlq,
+		"re_enabled":             reEnabled,
+	}, nil
+}
+
+// reEnableTool réactive un tool et met à jour le snapshot de sa version courante
+func (s *Server) reEnableTool(name string) error {
+	tx, err := s.db.LifecycleTools.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE tool_definitions SET enabled = 1, updated_at = strftime('%s', 'now')
+		WHERE name = ?`, name); err != nil {
+		return err
+	}
+	if err := tools.SnapshotVersion(tx, name, "recover_tool", "recover"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// forgetTool nettoie l'état d'exécution d'un tool retiré par un hot reload (appelé par tools.Manager
+// après sa dernière exécution en cours). Un tool seulement désactivé garde son état pour recover_tool
+func (s *Server) forgetTool(name string) {
+	var exists int
+	if err := s.db.LifecycleTools.QueryRow(`SELECT COUNT(*) FROM tool_definitions WHERE name = ?`, name).Scan(&exists); err != nil || exists > 0 {
+		return
+	}
+
+	s.circuits.Remove(name)
+	if _, err := s.db.LifecycleExec.Exec(`
+		DELETE FROM retry_queue WHERE tool_name = ? AND status IN ('pending', 'exhausted')`, name); err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] forget tool %s: retry_queue: %v\n", name, err)
+	}
+}
+
+// recoverRetryQueue replanifie immédiatement les retries du tool (épuisés compris) ou supprime les épuisés
+func (s *Server) recoverRetryQueue(name string, requeue bool) (int64, error) {
+	var res sql.Result
+	var err error
+	if requeue {
+		res, err = s.db.LifecycleExec.Exec(`
+			UPDATE retry_queue
+			SET status = 'pending', attempt_number = 1, backoff_seconds = 2,
+			    next_retry_at = strftime('%s', 'now'), last_error = NULL
+			WHERE tool_name = ? AND status IN ('pending', 'exhausted')`, name)
+	} else {
+		res, err = s.db.LifecycleExec.Exec(`
+			DELETE FROM retry_queue WHERE tool_name = ? AND status = 'exhausted'`, name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// recoverDeadLetters résout les entrées dead_letter_queue du tool
+// Avec requeue, une entrée sans retry en cours pour la même requête est remise dans retry_queue
+func (s *Server) recoverDeadLetters(name string, requeue bool) (int, error) {
+	rows, err := s.db.Output.Query(`
+		SELECT id, request_id, params_json FROM dead_letter_queue
+		WHERE tool_name = ? AND resolved = 0`, name)
+	if err != nil {
+		return 0, err
+	}
+	type deadLetter struct {
+		id                    int64
+		requestID, paramsJSON string
+	}
+	var letters []deadLetter
+	for rows.Next() {
+		var dl deadLetter
+		if err := rows.Scan(&dl.id, &dl.requestID, &dl.paramsJSON); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		letters = append(letters, dl)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	note := "cleared by recover_tool"
+	if requeue {
+		note = "requeued by recover_tool"
+	}
+	for _, dl := range letters {
+		if requeue {
+			var pending int
+			if err := s.db.LifecycleExec.QueryRow(`
+				SELECT COUNT(*) FROM retry_queue WHERE tool_name = ? AND request_id = ?`,
+				name, dl.requestID).Scan(&pending); err != nil {
+				return 0, err
+			}
+			if pending == 0 {
+				if _, err := s.db.LifecycleExec.Exec(`
+					INSERT INTO retry_queue
+					(request_id, tool_name, params_json, max_attempts, next_retry_at, backoff_seconds)
+					VALUES (?, ?, ?, ?, strftime('%s', 'now'), 2)`,
+					dl.requestID, name, dl.paramsJSON, s.cfg.RetryMaxAttempts); err != nil {
+					return 0, err
+				}
+			}
+		}
+		if _, err := s.db.Output.Exec(`
+			UPDATE dead_letter_queue
+			SET resolved = 1, resolved_at = strftime('%s', 'now'), resolution_note = ?
+			WHERE id = ?`, note, dl.id); err != nil {
+			return 0, err
+		}
+	}
+	return len(letters), nil
+}
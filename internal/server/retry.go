@@ -0,0 +1,137 @@
+// Package server - Retries en processus des tools SQL (retry_policy, max_retries de tool_definitions)
+// Distincts de retry_queue: la requête attend ses retries avant de répondre
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/config"
+	"github.com/horos/holow-mcp/internal/tools"
+)
+
+// Politiques de retry acceptées dans tool_definitions.retry_policy
+const (
+	retryNone        = "none"
+	retryFixed       = "fixed"       // Délai constant entre tentatives
+	retryExponential = "exponential" // Délai doublé à chaque tentative (défaut du schéma)
+)
+
+// configRetryBaseDelay clé config du délai avant le premier retry en processus
+const configRetryBaseDelay = "retry.base_delay_ms"
+
+// defaultRetryBaseDelay délai appliqué si la clé config est absente
+const defaultRetryBaseDelay = 200 * time.Millisecond
+
+// maxRetryDelay plafond du délai exponentiel entre deux tentatives
+const maxRetryDelay = 5 * time.Second
+
+// transientErrors erreurs SQLite résolues en réessayant (base verrouillée par un autre écrivain)
+var transientErrors = []string{
+	"database is locked",
+	"database table is locked",
+	"sqlite_busy",
+	"sqlite_locked",
+}
+
+// retryPolicy politique de retry d'un tool
+type retryPolicy struct {
+	Kind       string
+	MaxRetries int // Tentatives supplémentaires après la première
+	BaseDelay  time.Duration
+}
+
+// parseRetryPolicy interprète retry_policy et max_retries ("" = none)
+func parseRetryPolicy(policy string, maxRetries int, baseDelay time.Duration) (retryPolicy, error) {
+	kind := strings.ToLower(strings.TrimSpace(policy))
+	switch kind {
+	case "":
+		kind = retryNone
+	case retryNone, retryFixed, retryExponential:
+	default:
+		return retryPolicy{Kind: retryNone}, fmt.Errorf("unknown retry policy: %s (expected none, fixed or exponential)", policy)
+	}
+	if kind == retryNone || maxRetries < 0 {
+		maxRetries = 0
+	}
+	return retryPolicy{Kind: kind, MaxRetries: maxRetries, BaseDelay: baseDelay}, nil
+}
+
+// delay attente avant le retry n (1 = premier retry)
+func (p retryPolicy) delay(n int) time.Duration {
+	if p.Kind != retryExponential {
+		return p.BaseDelay
+	}
+	d := p.BaseDelay
+	for i := 1; i < n && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// isTransient indique si un échec d'exécution peut réussir à l'identique plus tard
+// Paramètres et validations refusés, timeouts et annulations ne sont jamais réessayés,
+// ni un échec survenu après un step qui a déjà écrit (le rejeu dupliquerait l'écriture)
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var stepErr *StepError
+	if errors.As(err, &stepErr) && (stepErr.Kind != stepErrExecution || stepErr.AfterWrite) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range transientErrors {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// toolRetryPolicy politique du tool, délai de base lu dans la config
+func (s *Server) toolRetryPolicy(tool *tools.Tool) retryPolicy {
+	base := defaultRetryBaseDelay
+	if ms, err := config.GetInt(s.db.LifecycleCore, configRetryBaseDelay); err == nil && ms >= 0 {
+		base = time.Duration(ms) * time.Millisecond
+	}
+	policy, err := parseRetryPolicy(tool.RetryPolicy, tool.MaxRetries, base)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] tool %s: %v, retries disabled\n", tool.Name, err)
+	}
+	return policy
+}
+
+// executeWithRetry exécute le tool et le réessaie sur échec transitoire selon sa politique
+// Les steps sont rejoués depuis le début, seulement si aucun step n'a encore écrit;
+// retourne aussi le nombre de tentatives effectuées
+func (s *Server) executeWithRetry(ctx context.Context, tool *tools.Tool, args map[string]interface{}) (interface{}, int, error) {
+	policy := s.toolRetryPolicy(tool)
+	for attempt := 1; ; attempt++ {
+		result, err := s.executeTool(ctx, tool, args)
+		if err == nil || attempt > policy.MaxRetries || !isTransient(err) {
+			return result, attempt, err
+		}
+
+		wait := policy.delay(attempt)
+		s.logTrace(ctx, "warn", "tool retry", map[string]interface{}{
+			"tool":     tool.Name,
+			"attempt":  attempt,
+			"policy":   policy.Kind,
+			"delay_ms": wait.Milliseconds(),
+			"error":    err.Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return nil, attempt, err
+		case <-time.After(wait):
+		}
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,9 +26,11 @@ import (
 	"github.com/horos/holow-mcp/internal/brainloop"
 	"github.com/horos/holow-mcp/internal/chromium"
 	"github.com/horos/holow-mcp/internal/circuit"
+	"github.com/horos/holow-mcp/internal/config"
 	"github.com/horos/holow-mcp/internal/database"
 	"github.com/horos/holow-mcp/internal/discovery"
 	"github.com/horos/holow-mcp/internal/initcli"
+	"github.com/horos/holow-mcp/internal/llm"
 	"github.com/horos/holow-mcp/internal/observability"
 	"github.com/horos/holow-mcp/internal/tools"
 )
@@ -40,15 +46,28 @@ type Server struct {
 	browser    *chromium.ToolsManager
 	brainloop  *brainloop.ToolsManager
 	appConfig  *initcli.AppConfig
+	cfg        *config.Config // Configuration serveur (table config de lifecycle-core)
+	inflight   *inflightRegistry
 
-	stdin  io.Reader
-	stdout io.Writer
+	stdin     io.Reader
+	stdout    io.Writer
+	outMu     sync.Mutex // Sérialise réponses et notifications sur stdout
+	outBroken bool       // Écriture sur stdout en échec: client parti, plus rien n'est envoyé
 
 	basePath          string
 	requestsProcessed int64
 	requestsFailed    int64
+	lowDisk           int32 // 1 si l'espace disque est sous le seuil
+	safeMode          int32 // 1 si le tool browser et la génération LLM sont désactivés
+
+	// Arrêt après inactivité (idle.go)
+	idleShutdown   time.Duration // 0 = jamais
+	lastRequestAt  int64         // UnixNano de la dernière requête reçue ou terminée (atomique)
+	activeRequests int64         // Requêtes en cours de traitement (atomique)
 
 	shutdownChan chan struct{}
+	shutdownOnce sync.Once
+	stopped      chan struct{} // Fermé à la fin de Shutdown
 	wg           sync.WaitGroup
 }
 
@@ -75,8 +94,27 @@ type RPCError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// Options adapte la construction du serveur (tests, intégrations embarquées)
+type Options struct {
+	Stdin         io.Reader // Entrée JSON-RPC (défaut: os.Stdin)
+	Stdout        io.Writer // Sortie JSON-RPC (défaut: os.Stdout)
+	InMemory      bool      // Bases SQLite en mémoire au lieu de fichiers sous basePath
+	SchemasPath   string    // Répertoire des schémas (requis avec InMemory)
+	SkipDiscovery bool      // Ne pas détecter Chromium ni les outils système
+}
+
 // NewServer crée un nouveau serveur MCP
 func NewServer(basePath string) (*Server, error) {
+	return NewServerWithOptions(basePath, Options{})
+}
+
+// NewServerWithOptions crée un serveur MCP selon opts
+// Avec InMemory, les schémas de opts.SchemasPath sont appliqués à l'ouverture
+func NewServerWithOptions(basePath string, opts Options) (*Server, error) {
+	if opts.InMemory && opts.SchemasPath == "" {
+		return nil, fmt.Errorf("in-memory databases require a schemas path")
+	}
+
 	// Étape 1: Créer le CDPManager avec db = nil (sera configuré après)
 	cdpMgr := chromium.NewCDPManager(nil)
 
@@ -87,7 +125,13 @@ func NewServer(basePath string) (*Server, error) {
 	}
 
 	// Étape 3: Créer le database.Manager avec le callback
-	db, err := database.NewManager(basePath, cdpCallback)
+	var db *database.Manager
+	var err error
+	if opts.InMemory {
+		db, err = database.NewMemoryManager(cdpCallback)
+	} else {
+		db, err = database.NewManager(basePath, cdpCallback)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create database manager: %w", err)
 	}
@@ -96,22 +140,53 @@ func NewServer(basePath string) (*Server, error) {
 	cdpMgr.SetDB(db.LifecycleTools)
 
 	// Étape 5: Récupération et migrations au boot
-	schemasPath := filepath.Join(basePath, "schemas")
-	if _, err := os.Stat(schemasPath); os.IsNotExist(err) {
-		// Fallback: chercher dans le répertoire de l'exécutable
-		if execPath, err := os.Executable(); err == nil {
-			schemasPath = filepath.Join(filepath.Dir(execPath), "..", "..", "schemas")
+	schemasPath := opts.SchemasPath
+	if schemasPath == "" {
+		schemasPath = filepath.Join(basePath, "schemas")
+		if _, err := os.Stat(schemasPath); os.IsNotExist(err) {
+			// Fallback: chercher dans le répertoire de l'exécutable
+			if execPath, err := os.Executable(); err == nil {
+				schemasPath = filepath.Join(filepath.Dir(execPath), "..", "..", "schemas")
+			}
 		}
 	}
-	if err := db.RecoverAndMigrate(schemasPath); err != nil {
+	if opts.InMemory {
+		// Bases vierges: appliquer les schémas complets
+		if err := db.InitSchemas(schemasPath); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize in-memory schemas: %w", err)
+		}
+	} else if err := db.RecoverAndMigrate(schemasPath); err != nil {
 		fmt.Fprintf(os.Stderr, "[warn] recovery/migration: %v\n", err)
 	}
 
+	// Clés serveur garanties présentes, puis chargées une fois pour le cycle de vie
+	if err := config.EnsureDefaults(db.LifecycleCore); err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] config defaults: %v\n", err)
+	}
+	cfg, err := config.Load(db.LifecycleCore)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] config load: %v\n", err)
+	}
+
+	// Surcharges de pragmas par base (busy_timeout, wal_autocheckpoint)
+	pragmas, _ := config.Get(db.LifecycleCore, "db.pragmas")
+	overrides, err := database.ParsePragmaOverrides(pragmas)
+	if err == nil {
+		err = db.ApplyPragmas(overrides)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] %v\n", err)
+	}
+
 	// Découverte système au démarrage
 	disco := discovery.New(db.LifecycleCore)
-	if err := disco.Run(); err != nil {
-		// Log mais ne bloque pas - chromium sera indisponible
-		fmt.Fprintf(os.Stderr, "discovery warning: %v\n", err)
+	if !opts.SkipDiscovery {
+		disco.SetBasePath(basePath)
+		if err := disco.Run(); err != nil {
+			// Log mais ne bloque pas - chromium sera indisponible
+			fmt.Fprintf(os.Stderr, "discovery warning: %v\n", err)
+		}
 	}
 
 	// Configuration Chromium depuis Discovery
@@ -125,21 +200,72 @@ func NewServer(basePath string) (*Server, error) {
 	brainloopMgr := brainloop.NewToolsManager()
 	brainloopMgr.SetToolsDB(db.LifecycleTools)
 	brainloopMgr.SetExecDB(db.LifecycleExec)
+	brainloopMgr.SetCoreDB(db.LifecycleCore)
+	brainloopMgr.SetAttachWhitelist(db)
+	brainloopMgr.SetOutputDB(db.Output)
+	brainloopMgr.SetDatabases(db.Named())
+	brainloopMgr.SetMigrationsPath(schemasPath)
+
+	metrics := observability.NewCollector(db.LifecycleCore, db.Metadata, db.Output)
+	if level, err := config.Get(db.LifecycleCore, configTelemetryLogLevel); err == nil && level != "" {
+		if err := metrics.SetLogLevel(level); err != nil {
+			fmt.Fprintf(os.Stderr, "[warn] %s: %v\n", configTelemetryLogLevel, err)
+		}
+	}
+	brainloopMgr.SetMetrics(metrics)
+
+	stdin, stdout := opts.Stdin, opts.Stdout
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
 
-	return &Server{
+	srv := &Server{
 		db:           db,
 		cdpManager:   cdpMgr,
 		tools:        tools.NewManager(db.LifecycleTools),
 		circuits:     circuit.NewManager(db.LifecycleExec),
-		metrics:      observability.NewCollector(db.LifecycleCore, db.Metadata, db.Output),
+		cfg:          cfg,
+		inflight:     newInflightRegistry(),
+		metrics:      metrics,
 		alerts:       observability.NewAlertChecker(db.Metadata, db.Output),
 		browser:      chromium.NewToolsManager(browserCfg),
 		brainloop:    brainloopMgr,
 		basePath:     basePath,
-		stdin:        os.Stdin,
-		stdout:       os.Stdout,
+		stdin:        stdin,
+		stdout:       stdout,
 		shutdownChan: make(chan struct{}),
-	}, nil
+		stopped:      make(chan struct{}),
+	}
+	srv.circuits.SetFailureThreshold(cfg.CircuitBreakerThreshold)
+
+	// Commandes CDP tracées avec le trace ID du tools/call qui les a émises (cf. bindCDP)
+	cdpMgr.SetCallObserver(srv.logCDPCall)
+
+	// connect sans port relit le dernier port utilisé
+	srv.browser.SetSessionDB(db.LifecycleTools)
+
+	// list_inflight / cancel_request opèrent sur le registre du serveur
+	brainloopMgr.SetRequestRegistry(srv)
+
+	// recover_tool agit sur les circuit breakers, files de retry et tools du serveur
+	brainloopMgr.SetToolRecoverer(srv)
+
+	// Un tool supprimé ne laisse ni circuit breaker ni retry en attente
+	srv.tools.OnRemove(srv.forgetTool)
+
+	// validate_tool compile les steps avec la substitution et les bases du serveur
+	brainloopMgr.SetToolValidator(srv)
+
+	// export_tools_schema expose le même catalogue que tools/list
+	brainloopMgr.SetToolCatalog(srv)
+
+	srv.SetSafeMode(cfg.SafeMode)
+	srv.SetIdleShutdown(time.Duration(cfg.IdleShutdownSecs) * time.Second)
+
+	return srv, nil
 }
 
 // NewServerWithConfig crée un nouveau serveur MCP avec une configuration
@@ -152,13 +278,56 @@ func NewServerWithConfig(basePath string, appConfig *initcli.AppConfig) (*Server
 	srv.appConfig = appConfig
 	srv.basePath = basePath
 
+	if appConfig != nil {
+		maxAge := time.Duration(appConfig.ScreenshotMaxAgeHours) * time.Hour
+		if err := srv.browser.SetScreenshotPolicy(appConfig.ScreenshotDir, appConfig.ScreenshotMaxFiles, maxAge); err != nil {
+			fmt.Fprintf(os.Stderr, "[warn] screenshot policy: %v\n", err)
+		}
+
+		// Génération brainloop: providers LLM configurés, dans l'ordre de llm.provider_order
+		if appConfig.CredentialsAvailable() {
+			if client, err := llm.Configured(basePath, appConfig.CredentialsDB, srv.llmProviderOrder()...); err == nil {
+				srv.brainloop.SetLLM(llm.WithUsage(client, srv.db.Output, srv.llmPrices()))
+			}
+		}
+	}
+
 	return srv, nil
 }
 
+// configLLMProviderOrder clé config de l'ordre de repli des providers LLM (séparés par des virgules)
+const configLLMProviderOrder = "llm.provider_order"
+
+// llmProviderOrder lit llm.provider_order (vide = ordre par défaut du package llm)
+func (s *Server) llmProviderOrder() []string {
+	value, err := config.Get(s.db.LifecycleCore, configLLMProviderOrder)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// llmPrices lit la table de tarifs llm.prices (tarifs par défaut si absente ou invalide)
+func (s *Server) llmPrices() llm.Prices {
+	value, _ := config.Get(s.db.LifecycleCore, "llm.prices")
+	prices, err := llm.ParsePrices(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] %v\n", err)
+	}
+	return prices
+}
+
 // Start démarre le serveur MCP
 func (s *Server) Start(ctx context.Context) error {
 	// Démarrer les composants
-	if err := s.tools.Start(2 * time.Second); err != nil {
+	pollInterval := time.Duration(s.cfg.PollingIntervalMs) * time.Millisecond
+	if err := s.tools.Start(pollInterval); err != nil {
 		return fmt.Errorf("failed to start tools manager: %w", err)
 	}
 
@@ -181,19 +350,40 @@ func (s *Server) Start(ctx context.Context) error {
 	go s.poisonPillLoop()
 
 	// Goroutine traitement commandes CDP en arrière-plan
+	s.applyCDPResultLimit()
+	s.applyCDPQueueLimit()
 	go s.cdpProcessLoop()
 
+	// Goroutine arrêt après inactivité (hébergement à la demande)
+	if s.idleShutdown > 0 {
+		atomic.StoreInt64(&s.lastRequestAt, time.Now().UnixNano())
+		go s.idleLoop()
+	}
+
 	// Gestion signaux
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
+	// Pipe stdout fermé: l'écriture retourne EPIPE (géré par write) au lieu de tuer le processus
+	signal.Ignore(syscall.SIGPIPE)
+
 	go func() {
 		<-sigChan
 		s.Shutdown()
 	}()
 
-	// Boucle principale stdin
-	return s.readLoop(ctx)
+	// Boucle principale stdin; un Shutdown (signal, poison pill, client parti) y met fin
+	// même si stdin reste ouvert
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- s.readLoop(ctx)
+	}()
+	select {
+	case err := <-readErr:
+		return err
+	case <-s.stopped:
+		return nil
+	}
 }
 
 // readLoop lit les requêtes JSON-RPC depuis stdin
@@ -216,8 +406,10 @@ func (s *Server) readLoop(ctx context.Context) error {
 		}
 
 		s.wg.Add(1)
+		s.beginRequest()
 		go func(data []byte) {
 			defer s.wg.Done()
+			defer s.endRequest()
 			s.handleRequest(data)
 		}(line)
 	}
@@ -232,9 +424,19 @@ func (s *Server) readLoop(ctx context.Context) error {
 func (s *Server) handleRequest(data []byte) {
 	start := time.Now()
 
+	// Trace ID propagé aux steps SQL, actions browser et telemetry_logs
+	traceID := newTraceID()
+	ctx := withTraceID(context.Background(), traceID)
+
 	var req JSONRPCRequest
 	if err := json.Unmarshal(data, &req); err != nil {
-		s.sendError(nil, -32700, "Parse error", err.Error())
+		s.sendError(nil, -32700, "Parse error", withTraceData(err.Error(), traceID))
+		return
+	}
+
+	// Notification d'annulation: pas de réponse ni d'idempotence
+	if req.Method == "notifications/cancelled" {
+		s.handleCancelled(req.Params)
 		return
 	}
 
@@ -251,10 +453,12 @@ func (s *Server) handleRequest(data []byte) {
 	hash := s.hashRequest(req.Method, req.Params)
 
 	// Vérifier idempotence uniquement pour tools/call et autres méthodes mutatives
-	if !skipIdempotence[req.Method] {
+	// idempotence.enabled = false: chaque appel est réexécuté (toujours tracé)
+	dedup := s.cfg.IdempotenceEnabled
+	if dedup && !skipIdempotence[req.Method] {
 		processed, err := s.db.CheckProcessed(hash)
 		if err != nil {
-			s.sendError(req.ID, -32603, "Internal error", err.Error())
+			s.sendError(req.ID, -32603, "Internal error", withTraceData(err.Error(), traceID))
 			return
 		}
 
@@ -268,6 +472,21 @@ func (s *Server) handleRequest(data []byte) {
 		}
 	}
 
+	// Enregistrer la requête pour list_inflight / cancel_request
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	inflight := &inflightRequest{
+		id:      formatRequestID(req.ID),
+		method:  req.Method,
+		traceID: traceID,
+		started: start,
+		cancel:  cancel,
+	}
+	if req.Method == "tools/call" {
+		inflight.tool = toolNameOf(req.Params)
+	}
+	defer s.inflight.add(inflight)()
+
 	// Router la requête
 	var result interface{}
 	var rpcErr *RPCError
@@ -278,7 +497,7 @@ func (s *Server) handleRequest(data []byte) {
 	case "tools/list":
 		result, rpcErr = s.handleToolsList()
 	case "tools/call":
-		result, rpcErr = s.handleToolsCall(req.Params, hash)
+		result, rpcErr = s.handleToolsCall(ctx, req.Params, hash)
 	case "resources/list":
 		result, rpcErr = s.handleResourcesList()
 	case "prompts/list":
@@ -293,8 +512,15 @@ func (s *Server) handleRequest(data []byte) {
 
 	if rpcErr != nil {
 		atomic.AddInt64(&s.requestsFailed, 1)
-		s.sendError(req.ID, rpcErr.Code, rpcErr.Message, rpcErr.Data)
-		s.db.MarkProcessed(hash, fmt.Sprintf("%v", req.ID), req.Method, "failed", "", int64(latencyMs))
+		s.logTrace(ctx, "error", rpcErr.Message, map[string]interface{}{
+			"method":     req.Method,
+			"code":       rpcErr.Code,
+			"latency_ms": latencyMs,
+		})
+		s.sendError(req.ID, rpcErr.Code, rpcErr.Message, withTraceData(rpcErr.Data, traceID))
+		if dedup {
+			s.db.MarkProcessed(hash, fmt.Sprintf("%v", req.ID), req.Method, "failed", "", int64(latencyMs))
+		}
 		return
 	}
 
@@ -306,7 +532,13 @@ func (s *Server) handleRequest(data []byte) {
 	resultHashStr := hex.EncodeToString(resultHash[:])
 
 	// Marquer comme traité
-	s.db.MarkProcessed(hash, fmt.Sprintf("%v", req.ID), req.Method, "success", resultHashStr, int64(latencyMs))
+	if dedup {
+		s.db.MarkProcessed(hash, fmt.Sprintf("%v", req.ID), req.Method, "success", resultHashStr, int64(latencyMs))
+	}
+	s.logTrace(ctx, "info", "request completed", map[string]interface{}{
+		"method":     req.Method,
+		"latency_ms": latencyMs,
+	})
 
 	s.sendResult(req.ID, result)
 }
@@ -327,8 +559,8 @@ func (s *Server) handleInitialize(params json.RawMessage) (interface{}, *RPCErro
 	return map[string]interface{}{
 		"protocolVersion": "2024-11-05",
 		"serverInfo": map[string]interface{}{
-			"name":    "holow-mcp",
-			"version": "1.0.0",
+			"name":    s.cfg.ServerName,
+			"version": s.cfg.ServerVersion,
 		},
 		"capabilities": map[string]interface{}{
 			"tools":     map[string]interface{}{"listChanged": true},
@@ -340,62 +572,169 @@ func (s *Server) handleInitialize(params json.RawMessage) (interface{}, *RPCErro
 
 // handleToolsList retourne la liste des tools
 func (s *Server) handleToolsList() (interface{}, *RPCError) {
-	// Combiner les tools codés en dur + les tools SQL dynamiques
+	return map[string]interface{}{"tools": s.ToolCatalog()}, nil
+}
+
+// ToolCatalog retourne les schémas MCP de tous les tools exposés
+// En mode restreint le tool browser est omis
+func (s *Server) ToolCatalog() []map[string]interface{} {
+	browser := s.browser
+	if s.SafeMode() {
+		browser = nil
+	}
+	return buildToolCatalog(browser, s.brainloop, s.tools)
+}
+
+// SetSafeMode active le mode restreint: tool browser et actions de génération LLM
+// retirés de tools/list et refusés à l'appel; les tools SQL et de lecture restent disponibles,
+// sans accès à Chromium (cdp_call() et la file cdp_commands sont refusés)
+func (s *Server) SetSafeMode(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&s.safeMode, v)
+	s.brainloop.SetSafeMode(enabled)
+	s.cdpManager.SetDisabled(enabled)
+}
+
+// SafeMode indique si le mode restreint est actif
+func (s *Server) SafeMode() bool {
+	return atomic.LoadInt32(&s.safeMode) == 1
+}
+
+// buildToolCatalog combine les tools codés en dur et les tools SQL dynamiques
+// browser nil omet le tool browser (mode restreint)
+func buildToolCatalog(browser *chromium.ToolsManager, brain *brainloop.ToolsManager, sqlTools *tools.Manager) []map[string]interface{} {
 	allTools := make([]map[string]interface{}, 0, 20)
 
-	// Tool Browser (16 actions hardcodées)
-	browserTools := s.browser.ToolDefinitions()
-	allTools = append(allTools, browserTools...)
+	// Tool Browser (actions hardcodées)
+	if browser != nil {
+		allTools = append(allTools, browser.ToolDefinitions()...)
+	}
+
+	// Tool Brainloop (actions incluant système)
+	allTools = append(allTools, brain.ToolDefinitions()...)
 
-	// Tool Brainloop (18 actions incluant système)
-	brainloopTools := s.brainloop.ToolDefinitions()
-	allTools = append(allTools, brainloopTools...)
+	builtin := make(map[string]bool, len(allTools))
+	for _, tool := range allTools {
+		if name, ok := tool["name"].(string); ok {
+			builtin[name] = true
+		}
+	}
 
 	// Tools SQL dynamiques (depuis tool_definitions table)
-	sqlTools := s.tools.GetAllToolDefinitions()
-	for _, tool := range sqlTools {
+	// Un nom déjà pris par un tool intégré serait inappelable: il est omis
+	for _, tool := range sqlTools.GetAllToolDefinitions() {
+		if builtin[tool.Name] {
+			fmt.Fprintf(os.Stderr, "[warn] tools/list: SQL tool %q shadowed by built-in tool, omitted\n", tool.Name)
+			continue
+		}
 		allTools = append(allTools, tool.ToMCPSchema())
 	}
 
-	return map[string]interface{}{"tools": allTools}, nil
+	return allTools
+}
+
+// ExportToolsSchema écrit le catalogue JSON des tools sans démarrer le serveur
+// Le mode restreint (safeMode, server.safe_mode ou HOLOW_MCP_SAFE_MODE) omet les mêmes tools que tools/list
+func ExportToolsSchema(basePath string, safeMode bool, w io.Writer) error {
+	db, err := database.NewManager(basePath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open databases: %w", err)
+	}
+	defer db.Close()
+
+	sqlTools := tools.NewManager(db.LifecycleTools)
+	if err := sqlTools.Load(); err != nil {
+		return fmt.Errorf("failed to load tools: %w", err)
+	}
+
+	if cfg, _ := config.Load(db.LifecycleCore); cfg.SafeMode {
+		safeMode = true
+	}
+	browser := chromium.NewToolsManager(nil)
+	if safeMode {
+		browser = nil
+	}
+	brain := brainloop.NewToolsManager()
+	brain.SetSafeMode(safeMode)
+
+	catalog := buildToolCatalog(browser, brain, sqlTools)
+	data, err := json.MarshalIndent(map[string]interface{}{"tools": catalog}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
 }
 
 // handleToolsCall exécute un tool
-func (s *Server) handleToolsCall(params json.RawMessage, requestHash string) (interface{}, *RPCError) {
+func (s *Server) handleToolsCall(parent context.Context, params json.RawMessage, requestHash string) (interface{}, *RPCError) {
 	var callParams struct {
 		Name      string                 `json:"name"`
 		Arguments map[string]interface{} `json:"arguments"`
+		Meta      struct {
+			ProgressToken interface{} `json:"progressToken"`
+		} `json:"_meta"`
 	}
 
 	if err := json.Unmarshal(params, &callParams); err != nil {
 		return nil, &RPCError{Code: -32602, Message: "Invalid params", Data: err.Error()}
 	}
 
+	// Mode compact: résultat allégé pour économiser les tokens
+	compact := popCompactArg(callParams.Arguments)
+
+	// Budget global: steps, attentes CDP et retries compris
+	ctx, cancel := s.toolCallContext(parent)
+	defer cancel()
+
+	s.logTrace(ctx, "debug", "tool call", map[string]interface{}{"tool": callParams.Name})
+
 	// Vérifier si c'est un tool browser
 	if chromium.IsBrowserTool(callParams.Name) {
-		result, err := s.browser.Execute(callParams.Name, callParams.Arguments)
+		if s.SafeMode() {
+			return nil, &RPCError{Code: ErrCodeValidation, Message: "Tool disabled in safe mode", Data: map[string]interface{}{
+				"tool": callParams.Name,
+			}}
+		}
+		action, _ := callParams.Arguments["action"].(string)
+		s.logTrace(ctx, "debug", "browser action", map[string]interface{}{"action": action})
+		result, err := s.browser.ExecuteContext(ctx, callParams.Name, callParams.Arguments)
 		if err != nil {
-			return nil, &RPCError{Code: -32000, Message: "Browser tool failed", Data: err.Error()}
+			return nil, toolError(callParams.Name, "Browser tool failed", err)
 		}
 
-		resultJSON, _ := json.Marshal(result)
-		return map[string]interface{}{
-			"content": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": string(resultJSON),
+		if compact {
+			// Pas de bloc image: la capture reste accessible via son chemin si sauvegardée
+			resultJSON, _ := json.Marshal(compactResult(result, nil))
+			return map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": string(resultJSON),
+					},
 				},
-			},
-		}, nil
+			}, nil
+		}
+		return browserContent(result), nil
 	}
 
 	// Vérifier si c'est un tool brainloop
 	if brainloop.IsBrainloopTool(callParams.Name) {
-		result, err := s.brainloop.Execute(callParams.Name, callParams.Arguments)
+		blCtx := ctx
+		if token := callParams.Meta.ProgressToken; token != nil {
+			blCtx = brainloop.WithProgress(ctx, s.progressReporter(ctx, token))
+		}
+		result, err := s.brainloop.ExecuteContext(blCtx, callParams.Name, callParams.Arguments)
 		if err != nil {
-			return nil, &RPCError{Code: -32000, Message: "Brainloop tool failed", Data: err.Error()}
+			return nil, toolError(callParams.Name, "Brainloop tool failed", err)
 		}
 
+		if compact {
+			result = compactResult(result, nil)
+		}
 		resultJSON, _ := json.Marshal(result)
 		return map[string]interface{}{
 			"content": []map[string]interface{}{
@@ -407,27 +746,71 @@ func (s *Server) handleToolsCall(params json.RawMessage, requestHash string) (in
 		}, nil
 	}
 
-	// Récupérer le tool personnalisé
-	tool, ok := s.tools.Get(callParams.Name)
+	// Récupérer le tool personnalisé (suivi jusqu'à la fin de l'exécution)
+	tool, release, ok := s.tools.Acquire(callParams.Name)
 	if !ok {
-		return nil, &RPCError{Code: -32602, Message: "Tool not found", Data: callParams.Name}
+		return nil, &RPCError{Code: ErrCodeToolNotFound, Message: "Tool not found", Data: map[string]interface{}{
+			"tool": callParams.Name,
+		}}
+	}
+	defer release()
+
+	// Version épinglée: _version sélectionne un snapshot de tool_versioning
+	if raw, pinned := callParams.Arguments[versionArg]; pinned {
+		version, ok := parseVersionArg(raw)
+		if !ok {
+			return nil, &RPCError{Code: ErrCodeValidation, Message: "Invalid tool version", Data: map[string]interface{}{
+				"tool":     callParams.Name,
+				versionArg: raw,
+			}}
+		}
+		versioned, err := s.tools.GetVersion(callParams.Name, version)
+		if err != nil {
+			return nil, &RPCError{Code: ErrCodeToolNotFound, Message: "Tool version not found", Data: map[string]interface{}{
+				"tool":     callParams.Name,
+				versionArg: version,
+				"error":    err.Error(),
+			}}
+		}
+		tool = versioned
+
+		args := make(map[string]interface{}, len(callParams.Arguments)-1)
+		for k, v := range callParams.Arguments {
+			if k != versionArg {
+				args[k] = v
+			}
+		}
+		callParams.Arguments = args
 	}
 
 	// Vérifier circuit breaker
 	breaker := s.circuits.Get(callParams.Name)
 	if canExec, err := breaker.CanExecute(); !canExec {
 		s.metrics.RecordSecurityEvent("circuit_open", "warning", "", "", err.Error())
-		return nil, &RPCError{Code: -32000, Message: "Circuit breaker open", Data: err.Error()}
+		return nil, circuitOpenError(callParams.Name, breaker, err)
 	}
 
-	// Exécuter le tool
-	result, err := s.executeTool(tool, callParams.Arguments)
-	if err != nil {
+	// Exécuter le tool (retries transitoires selon retry_policy avant de compter un échec)
+	result, attempts, err := s.executeWithRetry(ctx, tool, callParams.Arguments)
+	outcome := breakerOutcome(err)
+	switch outcome {
+	case breakerSuccess:
+		breaker.RecordSuccess(s.db.LifecycleExec)
+	case breakerFailure:
 		breaker.RecordFailure(s.db.LifecycleExec)
-		return nil, &RPCError{Code: -32000, Message: "Tool execution failed", Data: err.Error()}
+	default:
+		breaker.RecordIgnored()
+	}
+	if err != nil {
+		rpcErr := toolError(callParams.Name, "Tool execution failed", err)
+		if data, ok := rpcErr.Data.(map[string]interface{}); ok {
+			data["breaker"] = outcome
+			if attempts > 1 {
+				data["attempts"] = attempts
+			}
+		}
+		return nil, rpcErr
 	}
-
-	breaker.RecordSuccess(s.db.LifecycleExec)
 
 	// Persister résultat
 	resultJSON, _ := json.Marshal(result)
@@ -439,6 +822,17 @@ func (s *Server) handleToolsCall(params json.RawMessage, requestHash string) (in
 		VALUES (?, ?, ?, ?, 'success')`,
 		resultHashStr, requestHash, callParams.Name, string(resultJSON))
 
+	if compact {
+		// Le résultat complet reste consultable dans output.tool_results
+		resultJSON, _ = json.Marshal(compactResult(result, map[string]interface{}{
+			"table": "tool_results",
+			"db":    "output",
+			"hash":  resultHashStr,
+		}))
+	} else if content, ok := contentItems(result); ok {
+		return map[string]interface{}{"content": content}, nil
+	}
+
 	return map[string]interface{}{
 		"content": []map[string]interface{}{
 			{
@@ -449,8 +843,190 @@ func (s *Server) handleToolsCall(params json.RawMessage, requestHash string) (in
 	}, nil
 }
 
+// browserContent construit le contenu MCP d'un résultat browser
+// Les captures (mimeType + base64) sont renvoyées comme bloc image, les métadonnées en texte
+func browserContent(result interface{}) map[string]interface{} {
+	var image map[string]interface{}
+
+	if m, ok := result.(map[string]interface{}); ok {
+		mimeType, hasMime := m["mimeType"].(string)
+		data, hasData := m["base64"].(string)
+		if hasMime && hasData {
+			meta := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				if k != "base64" {
+					meta[k] = v
+				}
+			}
+			image = map[string]interface{}{
+				"type":     "image",
+				"data":     data,
+				"mimeType": mimeType,
+			}
+			result = meta
+		}
+	}
+
+	resultJSON, _ := json.Marshal(result)
+	content := []map[string]interface{}{
+		{
+			"type": "text",
+			"text": string(resultJSON),
+		},
+	}
+	if image != nil {
+		content = append(content, image)
+	}
+
+	return map[string]interface{}{"content": content}
+}
+
+// contentItems reconnaît un résultat déjà au format contenu MCP: tableau de descripteurs typés,
+// nu ou sous une clé unique "content" ({"type":"text","text"}, {"type":"image","data","mimeType"},
+// {"type":"resource","resource":{"uri",...}}). Tout autre résultat reste un bloc JSON unique
+// Les lignes d'un SELECT multi-lignes sont des descripteurs (une ligne par bloc, cf. contentRow)
+// si elles n'ont aucune autre colonne non NULL que celles du descripteur de leur type
+func contentItems(result interface{}) ([]map[string]interface{}, bool) {
+	if m, ok := result.(map[string]interface{}); ok && len(m) == 1 {
+		result = m["content"]
+	}
+	var items []interface{}
+	switch v := result.(type) {
+	case []interface{}:
+		items = v
+	case []map[string]interface{}:
+		items = make([]interface{}, len(v))
+		for i, row := range v {
+			item := contentRow(row)
+			if !hasOnlyContentKeys(item) {
+				return nil, false
+			}
+			items[i] = item
+		}
+	}
+	if len(items) == 0 {
+		return nil, false
+	}
+
+	content := make([]map[string]interface{}, 0, len(items))
+	for _, raw := range items {
+		item, ok := raw.(map[string]interface{})
+		if !ok || !validContentItem(item) {
+			return nil, false
+		}
+		content = append(content, item)
+	}
+	return content, true
+}
+
+// contentRow adapte une ligne SQL en descripteur: colonnes NULL omises (UNION de types différents),
+// colonne resource en texte JSON décodée
+func contentRow(row map[string]interface{}) map[string]interface{} {
+	item := make(map[string]interface{}, len(row))
+	for col, value := range row {
+		if value == nil {
+			continue
+		}
+		if col == "resource" {
+			if str, ok := value.(string); ok {
+				var resource map[string]interface{}
+				if json.Unmarshal([]byte(str), &resource) == nil {
+					value = resource
+				}
+			}
+		}
+		item[col] = value
+	}
+	return item
+}
+
+// contentKeys colonnes d'un descripteur de contenu par type
+var contentKeys = map[string][]string{
+	"text":     {"type", "text"},
+	"image":    {"type", "data", "mimeType"},
+	"resource": {"type", "resource"},
+}
+
+// hasOnlyContentKeys indique si une ligne a exactement les colonnes du descripteur de son type
+// (une ligne de données ordinaire avec une colonne type = 'text' reste un résultat JSON)
+func hasOnlyContentKeys(item map[string]interface{}) bool {
+	typ, _ := item["type"].(string)
+	keys, ok := contentKeys[typ]
+	if !ok || len(item) != len(keys) {
+		return false
+	}
+	for _, key := range keys {
+		if _, ok := item[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// validContentItem vérifie les champs requis d'un descripteur de contenu selon son type
+func validContentItem(item map[string]interface{}) bool {
+	switch item["type"] {
+	case "text":
+		_, ok := item["text"].(string)
+		return ok
+	case "image":
+		_, hasData := item["data"].(string)
+		_, hasMime := item["mimeType"].(string)
+		return hasData && hasMime
+	case "resource":
+		resource, ok := item["resource"].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		_, hasURI := resource["uri"].(string)
+		_, hasText := resource["text"].(string)
+		_, hasBlob := resource["blob"].(string)
+		return hasURI && (hasText || hasBlob)
+	}
+	return false
+}
+
+// configMaxToolWallTime clé config du budget global d'un tools/call (0 = illimité)
+const configMaxToolWallTime = "server.max_tool_wall_time_seconds"
+
+// defaultMaxToolWallTime budget appliqué si la clé config est absente
+const defaultMaxToolWallTime = 120 * time.Second
+
+// toolCallContext dérive de parent le contexte borné par server.max_tool_wall_time_seconds
+func (s *Server) toolCallContext(parent context.Context) (context.Context, context.CancelFunc) {
+	budget := defaultMaxToolWallTime
+	if secs, err := config.GetInt(s.db.LifecycleCore, configMaxToolWallTime); err == nil {
+		budget = time.Duration(secs) * time.Second
+	}
+	if budget <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, budget)
+}
+
+// versionArg argument réservé de tools/call pour cibler une version de tool
+const versionArg = "_version"
+
+// parseVersionArg accepte un numéro de version JSON (nombre entier ou chaîne)
+func parseVersionArg(raw interface{}) (int, bool) {
+	switch v := raw.(type) {
+	case float64:
+		if v < 1 || v != math.Trunc(v) {
+			return 0, false
+		}
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil && n >= 1
+	}
+	return 0, false
+}
+
 // executeTool exécute les steps d'un tool
-func (s *Server) executeTool(tool *tools.Tool, args map[string]interface{}) (interface{}, error) {
+// ctx borne l'exécution: les requêtes SQL en cours sont interrompues à son expiration
+// Chaque step est validé séparément (pas de transaction): une StepError indique AfterWrite
+// si un step précédent a déjà écrit
+func (s *Server) executeTool(ctx context.Context, tool *tools.Tool, args map[string]interface{}) (_ interface{}, err error) {
 	if len(tool.Steps) == 0 {
 		return map[string]interface{}{
 			"message": "Tool executed (no steps defined)",
@@ -459,47 +1035,107 @@ func (s *Server) executeTool(tool *tools.Tool, args map[string]interface{}) (int
 		}, nil
 	}
 
+	wrote := false
+	defer func() {
+		var stepErr *StepError
+		if wrote && errors.As(err, &stepErr) {
+			stepErr.AfterWrite = true
+		}
+	}()
+
 	// Exécuter chaque step
 	var lastResult interface{}
 	for _, step := range tool.Steps {
+		if err := ctx.Err(); err != nil {
+			return nil, &StepError{Step: step.Name, StepType: step.StepType, Kind: stepErrExecution, Err: err}
+		}
+
 		// Substituer les paramètres dans le template SQL
-		sql := s.substituteParams(step.SQLTemplate, args)
+		sql, err := s.substituteParams(step.SQLTemplate, args)
+		if err != nil {
+			return nil, &StepError{Step: step.Name, StepType: step.StepType, Kind: stepErrParams, Err: err}
+		}
+		s.logTrace(ctx, "debug", "tool step", map[string]interface{}{
+			"tool":      tool.Name,
+			"step":      step.Name,
+			"step_type": step.StepType,
+		})
+
+		// cdp_call() du step bornés par ctx (les fonctions SQL n'ont pas de contexte Go)
+		release, err := s.bindCDP(ctx, sql)
+		if err != nil {
+			return nil, &StepError{Step: step.Name, StepType: step.StepType, Kind: stepErrExecution, Err: err}
+		}
+		result, err := s.executeStep(ctx, step, sql)
+		release()
+		if err != nil {
+			return nil, err
+		}
+
+		lastResult = result
+		if !isReadOnlyStep(step.StepType, sql) {
+			wrote = true
+		}
+	}
 
-		var err error
-		var result interface{}
+	return lastResult, nil
+}
 
-		switch step.StepType {
-		case "validate":
-			// Les validations utilisent RAISE pour échouer
-			_, err = s.db.LifecycleTools.Exec(sql)
-			if err != nil {
-				return nil, fmt.Errorf("validation failed at step %s: %w", step.Name, err)
-			}
-			result = map[string]interface{}{"validated": true}
+// bindCDP lie ctx aux cdp_call() d'un step (release sans effet si le step n'appelle pas CDP)
+func (s *Server) bindCDP(ctx context.Context, sql string) (release func(), err error) {
+	if !chromium.CallsCDP(sql) {
+		return func() {}, nil
+	}
+	return s.cdpManager.BindContext(ctx)
+}
 
-		case "sql":
-			// Exécuter et récupérer résultat
-			result, err = s.executeSQL(sql)
-			if err != nil {
-				return nil, fmt.Errorf("SQL execution failed at step %s: %w", step.Name, err)
+// executeStep exécute un step dont le template est déjà substitué
+func (s *Server) executeStep(ctx context.Context, step tools.ToolStep, sql string) (interface{}, error) {
+	switch step.StepType {
+	case "validate":
+		// Les validations utilisent RAISE pour échouer
+		_, err := s.db.LifecycleTools.ExecContext(ctx, sql)
+		if err != nil {
+			kind := stepErrValidation
+			if ctx.Err() != nil {
+				kind, err = stepErrExecution, ctx.Err()
 			}
+			return nil, &StepError{Step: step.Name, StepType: step.StepType, Kind: kind, Rule: step.SQLTemplate, Err: err}
+		}
+		return map[string]interface{}{"validated": true}, nil
 
-		case "attach":
-			// ATTACH temporaire
-			result = map[string]interface{}{"attached": true}
+	case "sql":
+		// Exécuter et récupérer résultat
+		result, err := s.executeSQL(ctx, step, sql)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			return nil, &StepError{Step: step.Name, StepType: step.StepType, Kind: stepErrExecution, Err: err}
+		}
+		return result, nil
 
-		case "transform":
-			// Transformation de données
-			result = map[string]interface{}{"transformed": true}
+	case "attach":
+		// ATTACH temporaire
+		return map[string]interface{}{"attached": true}, nil
 
-		default:
-			return nil, fmt.Errorf("unknown step type: %s", step.StepType)
-		}
+	case "transform":
+		// Transformation de données
+		return map[string]interface{}{"transformed": true}, nil
 
-		lastResult = result
+	default:
+		return nil, fmt.Errorf("unknown step type: %s", step.StepType)
 	}
+}
 
-	return lastResult, nil
+// isReadOnlyStep indique si un step réussi n'a rien pu écrire (SELECT, ou step sans SQL exécuté)
+// Un SELECT qui appelle cdp_*() agit sur le browser (navigation, clic...) et compte comme écriture
+func isReadOnlyStep(stepType, sql string) bool {
+	switch stepType {
+	case "sql", "validate":
+		return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "SELECT") && !chromium.CallsCDP(sql)
+	}
+	return true
 }
 
 // sanitizeSQLValue échappe une valeur pour insertion sécurisée dans SQL
@@ -581,9 +1217,9 @@ func validateParamKey(key string) bool {
 
 // isInJavaScriptContext vérifie si un placeholder est dans un contexte JavaScript/JSON
 // (par ex. inside json_object('expression', '...{{param}}...'))
-func isInJavaScriptContext(template, placeholder string) bool {
-	idx := strings.Index(template, placeholder)
-	if idx == -1 {
+// idx est la position du placeholder dans le template
+func isInJavaScriptContext(template string, idx int) bool {
+	if idx < 0 || idx > len(template) {
 		return false
 	}
 
@@ -617,61 +1253,240 @@ func isInJavaScriptContext(template, placeholder string) bool {
 	return false
 }
 
-// substituteParams remplace les {{param}} par leurs valeurs de façon sécurisée
-func (s *Server) substituteParams(template string, args map[string]interface{}) string {
-	result := template
-	for key, value := range args {
-		// Valider le nom du paramètre
-		if !validateParamKey(key) {
-			continue
+// placeholderRegex capture les placeholders {{env:NAME}}, {{param}} et {{param:type}}
+// Groupes: 1 = variable d'environnement, 2 = paramètre, 3 = annotation de type
+var placeholderRegex = regexp.MustCompile(`\{\{(?:env:([A-Za-z_][A-Za-z0-9_]*)|([A-Za-z_][A-Za-z0-9_]*)(?::([A-Za-z]+))?)\}\}`)
+
+// configEnvAllowlist clé config listant les variables d'environnement utilisables
+// dans les templates via {{env:NAME}} (séparées par des virgules)
+const configEnvAllowlist = "templates.env_allowlist"
+
+// envAllowlist charge la liste des variables d'environnement autorisées
+func (s *Server) envAllowlist() map[string]bool {
+	allowed := make(map[string]bool)
+	value, err := config.Get(s.db.LifecycleCore, configEnvAllowlist)
+	if err != nil {
+		return allowed
+	}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed[name] = true
 		}
+	}
+	return allowed
+}
 
-		placeholder := "{{" + key + "}}"
-		var strValue string
-		switch v := value.(type) {
-		case string:
-			strValue = v
-		case float64:
-			strValue = fmt.Sprintf("%v", v)
-		case int:
-			strValue = fmt.Sprintf("%d", v)
-		case int64:
-			strValue = fmt.Sprintf("%d", v)
-		case bool:
-			if v {
-				strValue = "1"
-			} else {
-				strValue = "0"
+// Annotations de type supportées dans les placeholders ({{param:type}})
+// Sans annotation, le contexte JS/SQL est deviné par isInJavaScriptContext
+const (
+	paramTypeSQL  = "sql"  // Chaîne SQL: échappement des guillemets simples
+	paramTypeJS   = "js"   // Chaîne dans du JS/JSON: échappement JSON puis SQL
+	paramTypeInt  = "int"  // Entier validé, inséré tel quel
+	paramTypeNum  = "num"  // Nombre (entier ou décimal) validé, inséré tel quel
+	paramTypeBool = "bool" // Booléen converti en 1/0
+)
+
+// maxParamValueLen limite la longueur des valeurs pour éviter les attaques DoS
+const maxParamValueLen = 65536 // 64KB max par valeur
+
+// paramToString convertit une valeur d'argument JSON en chaîne
+// Retourne false si la valeur n'est pas sérialisable
+func paramToString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return fmt.Sprintf("%v", v), true
+	case int:
+		return fmt.Sprintf("%d", v), true
+	case int64:
+		return fmt.Sprintf("%d", v), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	case nil:
+		return "", true
+	default:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(jsonBytes), true
+	}
+}
+
+// formatIntParam valide et formate une valeur annotée :int
+func formatIntParam(key string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", fmt.Errorf("parameter %s: expected integer, got %v", key, v)
+		}
+		return strconv.FormatInt(int64(v), 10), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("parameter %s: expected integer, got %q", key, v)
+		}
+		return strconv.FormatInt(n, 10), nil
+	}
+	return "", fmt.Errorf("parameter %s: expected integer, got %T", key, value)
+}
+
+// formatNumParam valide et formate une valeur annotée :num
+func formatNumParam(key string, value interface{}) (string, error) {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return "", fmt.Errorf("parameter %s: expected number, got %q", key, v)
+		}
+		f = parsed
+	default:
+		return "", fmt.Errorf("parameter %s: expected number, got %T", key, value)
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("parameter %s: number must be finite", key)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// formatBoolParam valide et formate une valeur annotée :bool en 1/0
+func formatBoolParam(key string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case float64:
+		if v == 0 || v == 1 {
+			return strconv.Itoa(int(v)), nil
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1":
+			return "1", nil
+		case "false", "0":
+			return "0", nil
+		}
+	}
+	return "", fmt.Errorf("parameter %s: expected boolean, got %v", key, value)
+}
+
+// escapeParam convertit et échappe une valeur selon l'annotation du placeholder
+// template et idx servent au fallback heuristique pour les placeholders non annotés
+func escapeParam(key string, value interface{}, annotation, template string, idx int) (string, error) {
+	switch annotation {
+	case paramTypeInt:
+		return formatIntParam(key, value)
+	case paramTypeNum:
+		return formatNumParam(key, value)
+	case paramTypeBool:
+		return formatBoolParam(key, value)
+	case "", paramTypeSQL, paramTypeJS:
+	default:
+		return "", fmt.Errorf("parameter %s: unknown type annotation %q", key, annotation)
+	}
+
+	strValue, ok := paramToString(value)
+	if !ok {
+		return "", nil // Ignorer les valeurs non sérialisables
+	}
+
+	if len(strValue) > maxParamValueLen {
+		strValue = strValue[:maxParamValueLen]
+	}
+
+	// Déterminer le type d'échappement nécessaire
+	if annotation == paramTypeJS || (annotation == "" && isInJavaScriptContext(template, idx)) {
+		// Contexte JavaScript: échapper pour JS d'abord, puis SQL
+		strValue = escapeJSONValue(strValue)
+	}
+
+	// Toujours appliquer l'échappement SQL (guillemets simples)
+	return sanitizeSQLValue(strValue), nil
+}
+
+// substituteParams remplace les {{param}} par leurs valeurs de façon sécurisée
+// Un placeholder peut être annoté pour rendre l'échappement déterministe:
+// {{param:sql}}, {{param:js}}, {{param:int}}, {{param:num}}, {{param:bool}}
+// {{env:NAME}} lit une variable d'environnement présente dans templates.env_allowlist
+func (s *Server) substituteParams(template string, args map[string]interface{}) (string, error) {
+	return s.substitute(template, args, nil)
+}
+
+// substitute implémente substituteParams; les paramètres et variables d'environnement
+// dont le nom satisfait secret sont validés puis remplacés par redactedValue
+func (s *Server) substitute(template string, args map[string]interface{}, secret func(name string) bool) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(template))
+
+	var allowedEnv map[string]bool
+
+	last := 0
+	for _, m := range placeholderRegex.FindAllStringSubmatchIndex(template, -1) {
+		sb.WriteString(template[last:m[0]])
+		last = m[1]
+
+		// Variable d'environnement: mêmes règles d'échappement qu'un paramètre non annoté
+		if m[2] != -1 {
+			name := template[m[2]:m[3]]
+			if allowedEnv == nil {
+				allowedEnv = s.envAllowlist()
 			}
-		case nil:
-			strValue = ""
-		default:
-			jsonBytes, err := json.Marshal(v)
+			if !allowedEnv[name] {
+				return "", fmt.Errorf("environment variable %s is not in %s", name, configEnvAllowlist)
+			}
+			escaped, err := escapeParam(name, os.Getenv(name), "", template, m[0])
 			if err != nil {
-				continue // Ignorer les valeurs non sérialisables
+				return "", err
+			}
+			if secret != nil && secret(name) {
+				escaped = redactedValue
 			}
-			strValue = string(jsonBytes)
+			sb.WriteString(escaped)
+			continue
 		}
 
-		// Limiter la longueur des valeurs pour éviter les attaques DoS
-		const maxValueLen = 65536 // 64KB max par valeur
-		if len(strValue) > maxValueLen {
-			strValue = strValue[:maxValueLen]
+		key := template[m[4]:m[5]]
+		annotation := ""
+		if m[6] != -1 {
+			annotation = strings.ToLower(template[m[6]:m[7]])
 		}
 
-		// Déterminer le type d'échappement nécessaire
-		if isInJavaScriptContext(result, placeholder) {
-			// Contexte JavaScript: échapper pour JS d'abord, puis SQL
-			strValue = escapeJSONValue(strValue)
+		// Valider le nom du paramètre; les placeholders non fournis deviennent vides
+		value, ok := args[key]
+		if !ok || !validateParamKey(key) {
+			continue
 		}
 
-		// Toujours appliquer l'échappement SQL (guillemets simples)
-		strValue = sanitizeSQLValue(strValue)
-
-		result = strings.ReplaceAll(result, placeholder, strValue)
+		escaped, err := escapeParam(key, value, annotation, template, m[0])
+		if err != nil {
+			return "", err
+		}
+		if secret != nil && secret(key) {
+			escaped = redactedValue
+		}
+		sb.WriteString(escaped)
 	}
+	sb.WriteString(template[last:])
+	result := sb.String()
 
-	// Remplacer les placeholders non fournis par des chaînes vides
+	// Remplacer les placeholders restants (syntaxe invalide) par des chaînes vides
 	for {
 		start := strings.Index(result, "{{")
 		if start == -1 {
@@ -684,16 +1499,32 @@ func (s *Server) substituteParams(template string, args map[string]interface{})
 		result = result[:start] + result[start+end+2:]
 	}
 
-	return result
+	return result, nil
 }
 
-// executeSQL exécute une requête SQL et retourne le résultat
-func (s *Server) executeSQL(sql string) (interface{}, error) {
+// executeSQL exécute la requête SQL substituée d'un step et retourne le résultat
+// Seul le template du step est tracé: la requête substituée porte les arguments et les {{env:NAME}}
+func (s *Server) executeSQL(ctx context.Context, step tools.ToolStep, sql string) (_ interface{}, err error) {
+	start := time.Now()
+	defer func() {
+		fields := map[string]interface{}{
+			"step":         step.Name,
+			"sql_template": truncateForLog(step.SQLTemplate),
+			"duration_ms":  time.Since(start).Milliseconds(),
+		}
+		level := "debug"
+		if err != nil {
+			level = "error"
+			fields["error"] = err.Error()
+		}
+		s.logTrace(ctx, level, "sql executed", fields)
+	}()
+
 	trimmed := strings.TrimSpace(sql)
 	isSelect := strings.HasPrefix(strings.ToUpper(trimmed), "SELECT")
 
 	if isSelect {
-		rows, err := s.db.LifecycleTools.Query(sql)
+		rows, err := s.db.LifecycleTools.QueryContext(ctx, sql)
 		if err != nil {
 			return nil, err
 		}
@@ -718,12 +1549,7 @@ func (s *Server) executeSQL(sql string) (interface{}, error) {
 
 			row := make(map[string]interface{})
 			for i, col := range columns {
-				val := values[i]
-				if b, ok := val.([]byte); ok {
-					row[col] = string(b)
-				} else {
-					row[col] = val
-				}
+				row[col] = database.JSONValue(values[i])
 			}
 			results = append(results, row)
 		}
@@ -744,7 +1570,7 @@ func (s *Server) executeSQL(sql string) (interface{}, error) {
 	}
 
 	// Exécution (INSERT, UPDATE, DELETE)
-	result, err := s.db.LifecycleTools.Exec(sql)
+	result, err := s.db.LifecycleTools.ExecContext(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -794,16 +1620,64 @@ func (s *Server) sendError(id interface{}, code int, message string, data interf
 
 // send envoie une réponse JSON-RPC
 func (s *Server) send(resp JSONRPCResponse) {
-	data, err := json.Marshal(resp)
+	s.write(resp)
+}
+
+// JSONRPCNotification représente une notification JSON-RPC (sans ID)
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// sendNotification envoie une notification au client
+func (s *Server) sendNotification(method string, params interface{}) {
+	s.write(JSONRPCNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// write sérialise un message sur une ligne de stdout
+// Un échec d'écriture signifie que le client est parti: arrêt gracieux, messages suivants ignorés
+func (s *Server) write(msg interface{}) {
+	data, err := json.Marshal(msg)
 	if err != nil {
 		return
 	}
-	fmt.Fprintln(s.stdout, string(data))
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	if s.outBroken {
+		return
+	}
+	if _, err := fmt.Fprintln(s.stdout, string(data)); err != nil {
+		s.outBroken = true
+		fmt.Fprintf(os.Stderr, "stdout write failed, client gone: %v - shutting down\n", err)
+		go s.Shutdown() // Hors de la requête en cours, que Shutdown attend
+	}
+}
+
+// progressReporter émet chaque contenu partiel en notifications/progress pour token
+// Silencieux une fois ctx terminé (la réponse a déjà été envoyée)
+func (s *Server) progressReporter(ctx context.Context, token interface{}) brainloop.ProgressFunc {
+	var mu sync.Mutex
+	progress := 0
+	return func(message string) {
+		if ctx.Err() != nil {
+			return
+		}
+		mu.Lock()
+		progress++
+		current := progress
+		mu.Unlock()
+		s.sendNotification("notifications/progress", map[string]interface{}{
+			"progressToken": token,
+			"progress":      current,
+			"message":       message,
+		})
+	}
 }
 
-// heartbeatLoop envoie un heartbeat toutes les 15 secondes
+// heartbeatLoop envoie un heartbeat toutes les heartbeat.interval_seconds (15s par défaut)
 func (s *Server) heartbeatLoop() {
-	ticker := time.NewTicker(15 * time.Second)
+	ticker := time.NewTicker(time.Duration(s.cfg.HeartbeatIntervalSecs) * time.Second)
 	defer ticker.Stop()
 
 	for {
@@ -815,10 +1689,54 @@ func (s *Server) heartbeatLoop() {
 				int(atomic.LoadInt64(&s.requestsProcessed)),
 				int(atomic.LoadInt64(&s.requestsFailed)),
 				s.tools.Count())
+			s.checkDiskSpace()
 		}
 	}
 }
 
+// Clés config de la surveillance d'espace disque
+const (
+	configDiskMinFreeMB    = "disk.min_free_mb"
+	configDiskPoisonOnLow  = "disk.poison_pill_on_low"
+	defaultDiskMinFreeMB   = 500
+	diskSpaceMetricName    = "disk_free_mb"
+	diskSpaceSecurityEvent = "low_disk_space"
+	diskSpacePoisonTrigger = "disk_monitor"
+)
+
+// checkDiskSpace mesure l'espace libre du répertoire des bases
+// Sous le seuil: alerte (une fois par franchissement) et poison pill si configuré
+func (s *Server) checkDiskSpace() {
+	freeMB, err := discovery.FreeDiskSpaceMB(s.basePath)
+	if err != nil {
+		return
+	}
+
+	config.Save(s.db.LifecycleCore, discovery.KeyDiskFreeMB, strconv.FormatUint(freeMB, 10))
+	s.metrics.RecordMetric(diskSpaceMetricName, "gauge", float64(freeMB), nil)
+
+	threshold := defaultDiskMinFreeMB
+	if v, err := config.GetInt(s.db.LifecycleCore, configDiskMinFreeMB); err == nil {
+		threshold = v
+	}
+
+	if freeMB >= uint64(threshold) {
+		atomic.StoreInt32(&s.lowDisk, 0)
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.lowDisk, 0, 1) {
+		return // Déjà signalé
+	}
+
+	details := fmt.Sprintf("free disk space %d MB below threshold %d MB on %s", freeMB, threshold, s.basePath)
+	fmt.Fprintf(os.Stderr, "[warn] %s\n", details)
+	s.metrics.RecordSecurityEvent(diskSpaceSecurityEvent, "warning", "", "", details)
+
+	if v, err := config.Get(s.db.LifecycleCore, configDiskPoisonOnLow); err == nil && v == "true" {
+		s.metrics.TriggerPoisonPill(details, diskSpacePoisonTrigger)
+	}
+}
+
 // poisonPillLoop vérifie la table poisonpill
 func (s *Server) poisonPillLoop() {
 	ticker := time.NewTicker(5 * time.Second)
@@ -838,35 +1756,166 @@ func (s *Server) poisonPillLoop() {
 	}
 }
 
+// configCDPRetention clé config de rétention des cdp_commands traitées (0 = illimité)
+const configCDPRetention = "cdp.commands_retention_seconds"
+
+// Clés config de bornage des résultats cdp_call (0 = illimité)
+const (
+	configCDPMaxResult = "cdp.max_result_bytes"
+	configCDPSpill     = "cdp.spill_large_results"
+)
+
+// configCDPMaxPending clé config du nombre max de cdp_commands en attente (0 = illimité)
+const configCDPMaxPending = "cdp.max_pending_commands"
+
+const (
+	defaultCDPRetention = time.Hour
+	cdpCleanupInterval  = time.Minute
+)
+
+// cdpSpillDir sous-dossier du chemin de base recevant les résultats CDP complets
+// (privé à l'installation, contrairement à un nom fixe dans le dossier temporaire partagé)
+const cdpSpillDir = "cdp-results"
+
+// applyCDPResultLimit configure la taille max des résultats CDP et la copie des résultats complets
+// dans basePath/cdp-results (purgée avec les cdp_commands)
+func (s *Server) applyCDPResultLimit() {
+	maxBytes := chromium.DefaultMaxResultBytes
+	if n, err := config.GetInt(s.db.LifecycleCore, configCDPMaxResult); err == nil {
+		maxBytes = n
+	}
+	spillDir := filepath.Join(s.basePath, cdpSpillDir)
+	if spill, err := config.Get(s.db.LifecycleCore, configCDPSpill); err == nil && spill == "false" {
+		spillDir = ""
+	}
+	s.cdpManager.SetResultLimit(maxBytes, spillDir)
+}
+
+// applyCDPQueueLimit borne l'arriéré de cdp_commands: au-delà, les insertions sont refusées
+func (s *Server) applyCDPQueueLimit() {
+	maxPending := chromium.DefaultMaxPendingCommands
+	if n, err := config.GetInt(s.db.LifecycleCore, configCDPMaxPending); err == nil {
+		maxPending = n
+	}
+	if err := s.cdpManager.SetQueueLimit(maxPending); err != nil {
+		fmt.Fprintf(os.Stderr, "[warn] CDP queue limit not applied: %v\n", err)
+	}
+}
+
 // cdpProcessLoop traite les commandes CDP en attente toutes les 100ms
+// et purge périodiquement les commandes traitées, processed_log et telemetry_logs
 func (s *Server) cdpProcessLoop() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
+	cleanupTicker := time.NewTicker(cdpCleanupInterval)
+	defer cleanupTicker.Stop()
+
 	for {
 		select {
 		case <-s.shutdownChan:
 			return
 		case <-ticker.C:
+			if s.SafeMode() {
+				continue // Commandes laissées en attente: pas d'accès à Chromium en mode restreint
+			}
 			if err := s.cdpManager.ProcessPendingCommands(); err != nil {
 				// Log l'erreur mais continue (ne fait pas tomber le serveur)
 				fmt.Fprintf(os.Stderr, "CDP process error: %v\n", err)
 			}
+		case <-cleanupTicker.C:
+			s.cleanupCDPCommands()
+			s.pruneProcessedLog()
+			s.pruneTelemetryLogs()
+		}
+	}
+}
+
+// cleanupCDPCommands supprime les cdp_commands traitées et les résultats copiés au-delà de la rétention
+func (s *Server) cleanupCDPCommands() {
+	retention := defaultCDPRetention
+	if secs, err := config.GetInt(s.db.LifecycleCore, configCDPRetention); err == nil {
+		if secs <= 0 {
+			return // Rétention illimitée
 		}
+		retention = time.Duration(secs) * time.Second
+	}
+
+	if _, err := s.cdpManager.CleanupProcessed(retention); err != nil {
+		fmt.Fprintf(os.Stderr, "CDP cleanup error: %v\n", err)
+	}
+	if _, err := s.cdpManager.CleanupSpilled(retention); err != nil {
+		fmt.Fprintf(os.Stderr, "CDP cleanup error: %v\n", err)
+	}
+}
+
+// Clés config de rétention de processed_log (0 = illimité)
+const (
+	configIdempotenceRetention = "idempotence.retention_seconds"
+	configIdempotenceMaxRows   = "idempotence.max_rows"
+)
+
+const (
+	defaultIdempotenceRetention = 7 * 24 * time.Hour
+	defaultIdempotenceMaxRows   = 100000
+)
+
+// pruneProcessedLog borne processed_log en âge et en nombre pour garder CheckProcessed rapide
+func (s *Server) pruneProcessedLog() {
+	retention := defaultIdempotenceRetention
+	if secs, err := config.GetInt(s.db.LifecycleCore, configIdempotenceRetention); err == nil {
+		retention = time.Duration(secs) * time.Second
+	}
+	maxRows := defaultIdempotenceMaxRows
+	if n, err := config.GetInt(s.db.LifecycleCore, configIdempotenceMaxRows); err == nil {
+		maxRows = n
+	}
+
+	if _, err := s.db.PruneProcessed(retention, maxRows); err != nil {
+		fmt.Fprintf(os.Stderr, "processed_log prune error: %v\n", err)
+	}
+}
+
+// Clés config de telemetry_logs
+const (
+	configTelemetryLogLevel  = "telemetry.log_level"
+	configTelemetryRetention = "telemetry.logs_retention_seconds"
+)
+
+const defaultTelemetryRetention = 7 * 24 * time.Hour
+
+// pruneTelemetryLogs supprime les telemetry_logs au-delà de la rétention (0 = illimité)
+func (s *Server) pruneTelemetryLogs() {
+	retention := defaultTelemetryRetention
+	if secs, err := config.GetInt(s.db.LifecycleCore, configTelemetryRetention); err == nil {
+		retention = time.Duration(secs) * time.Second
+	}
+
+	if _, err := s.metrics.PruneLogs(retention); err != nil {
+		fmt.Fprintf(os.Stderr, "telemetry_logs prune error: %v\n", err)
 	}
 }
 
-// Shutdown arrête gracieusement le serveur
+// Shutdown arrête gracieusement le serveur; les appels suivants attendent la fin du premier
 func (s *Server) Shutdown() {
+	s.shutdownOnce.Do(s.shutdown)
+}
+
+// shutdown attend les requêtes en cours, arrête les composants et ferme les bases
+func (s *Server) shutdown() {
+	defer close(s.stopped)
 	close(s.shutdownChan)
 
+	// Budget global du shutdown (requêtes en cours + drain CDP)
+	deadline := time.Now().Add(time.Duration(s.cfg.ShutdownTimeoutSecs) * time.Second)
+
 	// Mettre à jour heartbeat
 	s.metrics.UpdateHeartbeat("shutting_down",
 		int(atomic.LoadInt64(&s.requestsProcessed)),
 		int(atomic.LoadInt64(&s.requestsFailed)),
 		s.tools.Count())
 
-	// Attendre les requêtes en cours (max 60s)
+	// Attendre les requêtes en cours (dans le budget)
 	done := make(chan struct{})
 	go func() {
 		s.wg.Wait()
@@ -876,7 +1925,7 @@ func (s *Server) Shutdown() {
 	select {
 	case <-done:
 		// Toutes les requêtes terminées
-	case <-time.After(60 * time.Second):
+	case <-time.After(time.Until(deadline)):
 		fmt.Fprintln(os.Stderr, "Shutdown timeout exceeded, forcing shutdown")
 		// La goroutine reste bloquée mais on continue le shutdown
 		// Elle sera terminée avec le process
@@ -886,6 +1935,9 @@ func (s *Server) Shutdown() {
 	s.tools.Stop()
 	s.metrics.Stop()
 
+	// Laisser les commandes CDP en attente se terminer avant de déconnecter
+	s.drainCDPCommands(deadline)
+
 	// Déconnecter le browser CDP
 	if err := s.cdpManager.Disconnect(); err != nil {
 		fmt.Fprintf(os.Stderr, "CDP disconnect error: %v\n", err)
@@ -915,6 +1967,24 @@ func (s *Server) Shutdown() {
 	s.db.Close()
 }
 
+// drainCDPCommands traite les commandes cdp_commands restantes jusqu'à deadline
+// Celles qui restent ensuite sont marquées 'aborted' pour ne pas rester pending
+func (s *Server) drainCDPCommands(deadline time.Time) {
+	for time.Now().Before(deadline) {
+		pending, err := s.cdpManager.PendingCount()
+		if err != nil || pending == 0 {
+			return
+		}
+		if err := s.cdpManager.ProcessPendingCommands(); err != nil {
+			break
+		}
+	}
+
+	if aborted, err := s.cdpManager.AbortPending("aborted: server shutdown"); err == nil && aborted > 0 {
+		fmt.Fprintf(os.Stderr, "Aborted %d pending CDP commands\n", aborted)
+	}
+}
+
 // GetCredential récupère une clé API depuis la configuration
 func (s *Server) GetCredential(provider string) (string, error) {
 	if s.appConfig == nil {
@@ -961,7 +2031,7 @@ func (s *Server) ProcessRetryQueue() error {
 		s.db.LifecycleExec.Exec(`UPDATE retry_queue SET status = 'processing' WHERE id = ?`, id)
 
 		// Récupérer tool et exécuter
-		tool, ok := s.tools.Get(toolName)
+		tool, release, ok := s.tools.Acquire(toolName)
 		if !ok {
 			s.db.LifecycleExec.Exec(`
 				UPDATE retry_queue SET status = 'exhausted', last_error = 'Tool not found'
@@ -972,7 +2042,11 @@ func (s *Server) ProcessRetryQueue() error {
 		var params map[string]interface{}
 		json.Unmarshal([]byte(paramsJSON), &params)
 
-		_, err := s.executeTool(tool, params)
+		// Chaque tentative reçoit son propre trace ID
+		ctx, cancel := s.toolCallContext(withTraceID(context.Background(), newTraceID()))
+		_, err := s.executeTool(ctx, tool, params)
+		cancel()
+		release()
 		if err != nil {
 			// Échec
 			if attempt >= maxAttempts {
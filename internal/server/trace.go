@@ -0,0 +1,73 @@
+// Package server - Identifiants de trace par requête JSON-RPC
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// traceKey clé de contexte portant le trace ID d'une requête
+type traceKey struct{}
+
+// newTraceID génère un identifiant de trace aléatoire (16 octets hex)
+func newTraceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// withTraceID attache un trace ID au contexte
+func withTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceID)
+}
+
+// traceIDFrom retourne le trace ID du contexte ("" si absent)
+func traceIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(traceKey{}).(string)
+	return id
+}
+
+// logTrace écrit dans telemetry_logs avec le trace ID de la requête en cours
+func (s *Server) logTrace(ctx context.Context, level, message string, fields map[string]interface{}) {
+	s.metrics.Log(level, message, "server", traceIDFrom(ctx), fields)
+}
+
+// logCDPCall trace une commande CDP (cdp_call() d'un step SQL ou file cdp_commands)
+func (s *Server) logCDPCall(ctx context.Context, method string, elapsed time.Duration, err error) {
+	fields := map[string]interface{}{"method": method, "elapsed_ms": elapsed.Milliseconds()}
+	if err != nil {
+		fields["error"] = err.Error()
+		s.logTrace(ctx, "warn", "cdp call failed", fields)
+		return
+	}
+	s.logTrace(ctx, "debug", "cdp call", fields)
+}
+
+// maxLoggedSQL longueur max d'une requête SQL recopiée dans les logs
+const maxLoggedSQL = 500
+
+// truncateForLog tronque un template SQL pour telemetry_logs
+func truncateForLog(sql string) string {
+	if len(sql) <= maxLoggedSQL {
+		return sql
+	}
+	return sql[:maxLoggedSQL] + "..."
+}
+
+// withTraceData ajoute le trace ID aux données d'une erreur JSON-RPC
+// Les données non structurées sont conservées sous "detail"
+func withTraceData(data interface{}, traceID string) interface{} {
+	switch d := data.(type) {
+	case nil:
+		return map[string]interface{}{"trace_id": traceID}
+	case map[string]interface{}:
+		d["trace_id"] = traceID
+		return d
+	default:
+		return map[string]interface{}{"detail": d, "trace_id": traceID}
+	}
+}
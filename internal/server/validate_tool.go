@@ -0,0 +1,175 @@
+// Package server - Compilation des steps SQL d'un tool sans exécution (validate_tool)
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/horos/holow-mcp/internal/database"
+	"github.com/horos/holow-mcp/internal/tools"
+)
+
+// stepTargetDB base sur laquelle executeTool exécute chaque type de step SQL
+// Les steps attach et transform n'exécutent pas leur SQL
+var stepTargetDB = map[string]string{
+	"sql":      "lifecycle-tools",
+	"validate": "lifecycle-tools",
+}
+
+// validateStepTimeout borne la compilation de l'ensemble des steps
+const validateStepTimeout = 10 * time.Second
+
+// redactedValue remplace la valeur d'un argument sensible dans le SQL et les erreurs retournés
+const redactedValue = "[REDACTED]"
+
+// ValidateToolSteps compile chaque step (SQL substitué avec args) sur sa base cible et sur les 6 bases,
+// sans l'exécuter: chaque instruction passe par EXPLAIN. steps vide valide les steps du tool name
+// Une instruction utilisant un objet créé plus haut dans le même step est signalée invalide
+// Chaque step retourne son template et le SQL substitué, où les arguments et variables d'environnement
+// dont le nom contient un des secretPatterns sont masqués (comme dans les erreurs)
+func (s *Server) ValidateToolSteps(name string, steps []tools.StepDef, args map[string]interface{}, secretPatterns []string) (map[string]interface{}, error) {
+	if len(steps) == 0 {
+		tool, ok := s.tools.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("tool not found: %s", name)
+		}
+		for _, step := range tool.Steps {
+			steps = append(steps, tools.StepDef{Name: step.Name, StepType: step.StepType, SQLTemplate: step.SQLTemplate})
+		}
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	secret := func(name string) bool { return matchesSecretPattern(name, secretPatterns) }
+	redact := secretRedactor(args, secret)
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateStepTimeout)
+	defer cancel()
+
+	dbs := s.db.Named()
+	valid := true
+	results := make([]map[string]interface{}, 0, len(steps))
+	for i, step := range steps {
+		result := map[string]interface{}{
+			"step":         i + 1,
+			"name":         step.Name,
+			"type":         step.StepType,
+			"sql_template": step.SQLTemplate,
+		}
+		results = append(results, result)
+
+		target, executes := stepTargetDB[step.StepType]
+		if !executes {
+			if step.StepType != "attach" && step.StepType != "transform" {
+				result["valid"], valid = false, false
+				result["error"] = fmt.Sprintf("unknown step type: %s", step.StepType)
+				continue
+			}
+			result["valid"] = true
+			result["skipped"] = "SQL not executed for this step type"
+			continue
+		}
+		result["target_db"] = target
+
+		query, err := s.substituteParams(step.SQLTemplate, args)
+		if err != nil {
+			result["valid"], valid = false, false
+			result["error"] = redact(err.Error())
+			continue
+		}
+		// Même substitution, valeurs sensibles masquées: le SQL réel n'est jamais retourné
+		if display, err := s.substitute(step.SQLTemplate, args, secret); err == nil {
+			result["sql"] = redact(display)
+		}
+		statements := database.SplitStatements(query)
+		result["statements"] = len(statements)
+		if len(statements) == 0 {
+			result["valid"], valid = false, false
+			result["error"] = "step has no SQL statement"
+			continue
+		}
+
+		validIn := []string{}
+		for _, named := range dbs {
+			err := compileStatements(ctx, named.DB, statements)
+			if err == nil {
+				validIn = append(validIn, named.Name)
+			}
+			if named.Name == target {
+				result["valid"] = err == nil
+				if err != nil {
+					valid = false
+					result["error"] = redact(err.Error())
+				}
+			}
+		}
+		result["valid_in"] = validIn
+	}
+
+	redacted := []string{}
+	for key := range args {
+		if secret(key) {
+			redacted = append(redacted, key)
+		}
+	}
+	sort.Strings(redacted)
+
+	return map[string]interface{}{
+		"success":  true,
+		"action":   "validate_tool",
+		"name":     name,
+		"valid":    valid,
+		"steps":    results,
+		"redacted": redacted,
+	}, nil
+}
+
+// matchesSecretPattern indique si un nom d'argument contient un des motifs (casse ignorée)
+func matchesSecretPattern(name string, patterns []string) bool {
+	lower := strings.ToLower(name)
+	for _, p := range patterns {
+		if p != "" && strings.Contains(lower, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretRedactor retourne une fonction masquant, dans un texte, les valeurs des arguments sensibles
+// (telles quelles et échappées pour SQL), par exemple dans un message d'erreur SQLite
+func secretRedactor(args map[string]interface{}, secret func(name string) bool) func(string) string {
+	var values []string
+	for key, value := range args {
+		if !secret(key) {
+			continue
+		}
+		if str, ok := paramToString(value); ok && str != "" {
+			values = append(values, str, sanitizeSQLValue(str))
+		}
+	}
+	// Les plus longues d'abord: une valeur contenue dans une autre ne laisse pas de reste
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return func(text string) string {
+		for _, v := range values {
+			text = strings.ReplaceAll(text, v, redactedValue)
+		}
+		return text
+	}
+}
+
+// compileStatements compile les instructions dans l'ordre et s'arrête à la première erreur
+func compileStatements(ctx context.Context, db *sql.DB, statements []string) error {
+	for i, stmt := range statements {
+		if err := database.CompileStatement(ctx, db, stmt); err != nil {
+			if len(statements) > 1 {
+				return fmt.Errorf("statement %d: %w", i+1, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersionPinningOfCreatedTool(t *testing.T) {
+	ts := newTestServer(t)
+
+	ts.callTool(t, "brainloop", map[string]interface{}{
+		"action": "create_tool", "name": "pinned", "tool_description": "v1", "sql": "SELECT 'first' AS v",
+	})
+	ts.callTool(t, "brainloop", map[string]interface{}{
+		"action": "upsert_tool", "name": "pinned", "tool_description": "v2", "sql": "SELECT 'second' AS v",
+	})
+	if err := ts.tools.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{}, "second"},
+		{map[string]interface{}{"_version": 1}, "first"},
+		{map[string]interface{}{"_version": "2"}, "second"},
+	}
+	for _, tt := range tests {
+		content := ts.callTool(t, "pinned", tt.args)
+		text, _ := content[0].(map[string]interface{})["text"].(string)
+		if !strings.Contains(text, tt.want) {
+			t.Errorf("args %v: result %s, want %q", tt.args, text, tt.want)
+		}
+	}
+}
@@ -8,8 +8,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/horos/holow-mcp/internal/database"
 	_ "modernc.org/sqlite"
 )
 
@@ -19,14 +22,72 @@ type Shell struct {
 	db       *sql.DB
 	dbName   string
 	out      io.Writer
+	readOnly bool // Rejette les écritures (activé par défaut)
+	mode     string
+	maxWidth int
 }
 
-// New crée un nouveau shell SQL
+// Modes d'affichage des résultats
+const (
+	modeList   = "list"   // Valeurs séparées par " | "
+	modeColumn = "column" // Colonnes alignées avec troncature
+)
+
+// defaultMaxWidth largeur maximale d'une colonne en mode column
+const defaultMaxWidth = 40
+
+// New crée un nouveau shell SQL en mode lecture seule
 func New(basePath string) *Shell {
 	return &Shell{
 		basePath: basePath,
 		out:      os.Stdout,
+		readOnly: true,
+		mode:     modeList,
+		maxWidth: defaultMaxWidth,
+	}
+}
+
+// SetReadOnly active ou désactive le mode lecture seule
+// Appliqué immédiatement à la base ouverte via PRAGMA query_only
+func (s *Shell) SetReadOnly(readOnly bool) error {
+	s.readOnly = readOnly
+	if s.db == nil {
+		return nil
+	}
+	return s.applyReadOnly()
+}
+
+// applyReadOnly synchronise PRAGMA query_only avec le mode courant
+func (s *Shell) applyReadOnly() error {
+	value := "OFF"
+	if s.readOnly {
+		value = "ON"
+	}
+	_, err := s.db.Exec("PRAGMA query_only = " + value)
+	return err
+}
+
+// readOnlyKeywords premiers mots-clés autorisés en mode lecture seule
+var readOnlyKeywords = map[string]bool{
+	"SELECT":  true,
+	"PRAGMA":  true,
+	"EXPLAIN": true,
+	"WITH":    true,
+	"VALUES":  true,
+}
+
+// checkReadOnly rejette les requêtes qui ne sont pas des lectures
+// PRAGMA query_only reste le garde-fou final (ex: WITH ... DELETE, PRAGMA en écriture)
+func checkReadOnly(query string) error {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return nil
+	}
+	keyword := strings.ToUpper(strings.TrimRight(fields[0], ";("))
+	if !readOnlyKeywords[keyword] {
+		return fmt.Errorf("read-only mode: %s statements are not allowed (use .readonly off)", keyword)
 	}
+	return nil
 }
 
 // Run exécute une requête unique et affiche le résultat
@@ -43,6 +104,9 @@ func (s *Shell) Run(dbName, query string) error {
 func (s *Shell) Interactive() error {
 	fmt.Fprintln(s.out, "HOLOW-MCP SQL Shell (modernc.org/sqlite)")
 	fmt.Fprintln(s.out, "Type .help for commands, .quit to exit")
+	if s.readOnly {
+		fmt.Fprintln(s.out, "Read-only mode is on (.readonly off to allow writes)")
+	}
 	fmt.Fprintln(s.out, "")
 
 	// Lister les bases disponibles
@@ -114,8 +178,11 @@ func (s *Shell) handleCommand(cmd string) bool {
 		fmt.Fprintln(s.out, "Commands:")
 		fmt.Fprintln(s.out, "  .open <db>    Open database (e.g., .open lifecycle-tools)")
 		fmt.Fprintln(s.out, "  .tables       List tables in current database")
-		fmt.Fprintln(s.out, "  .schema [t]   Show schema (optionally for table t)")
+		fmt.Fprintln(s.out, "  .schema [t]   Show full DDL incl. indexes/triggers/views (optionally for table t)")
 		fmt.Fprintln(s.out, "  .databases    List available databases")
+		fmt.Fprintln(s.out, "  .readonly [on|off]  Show or toggle read-only mode")
+		fmt.Fprintln(s.out, "  .mode [list|column] Show or set output mode")
+		fmt.Fprintln(s.out, "  .width <n>    Max column width in column mode (0 = unlimited)")
 		fmt.Fprintln(s.out, "  .quit         Exit shell")
 
 	case ".open":
@@ -141,15 +208,63 @@ func (s *Shell) handleCommand(cmd string) bool {
 			fmt.Fprintln(s.out, "No database open")
 			return true
 		}
+		table := ""
 		if len(parts) > 1 {
-			s.execAndPrint(fmt.Sprintf("SELECT sql FROM sqlite_master WHERE name='%s';", parts[1]))
-		} else {
-			s.execAndPrint("SELECT sql FROM sqlite_master WHERE type='table' ORDER BY name;")
+			table = parts[1]
+		}
+		if err := s.printSchema(table); err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
 		}
 
 	case ".databases", ".dbs":
 		s.listDatabases()
 
+	case ".mode":
+		if len(parts) > 1 {
+			switch parts[1] {
+			case modeList, modeColumn:
+				s.mode = parts[1]
+			default:
+				fmt.Fprintln(s.out, "Usage: .mode [list|column]")
+				return true
+			}
+		}
+		fmt.Fprintf(s.out, "Output mode: %s\n", s.mode)
+
+	case ".width":
+		if len(parts) < 2 {
+			fmt.Fprintf(s.out, "Max column width: %d\n", s.maxWidth)
+			return true
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 0 {
+			fmt.Fprintln(s.out, "Usage: .width <n> (0 = unlimited)")
+			return true
+		}
+		s.maxWidth = n
+
+	case ".readonly":
+		if len(parts) > 1 {
+			switch strings.ToLower(parts[1]) {
+			case "on":
+				if err := s.SetReadOnly(true); err != nil {
+					fmt.Fprintf(s.out, "Error: %v\n", err)
+				}
+			case "off":
+				if err := s.SetReadOnly(false); err != nil {
+					fmt.Fprintf(s.out, "Error: %v\n", err)
+				}
+			default:
+				fmt.Fprintln(s.out, "Usage: .readonly [on|off]")
+				return true
+			}
+		}
+		if s.readOnly {
+			fmt.Fprintln(s.out, "Read-only mode: on")
+		} else {
+			fmt.Fprintln(s.out, "Read-only mode: off (writes allowed)")
+		}
+
 	default:
 		fmt.Fprintf(s.out, "Unknown command: %s\n", parts[0])
 	}
@@ -157,6 +272,47 @@ func (s *Shell) handleCommand(cmd string) bool {
 	return true
 }
 
+// printSchema affiche le DDL complet (tables, vues, index, triggers)
+// Chaque table est suivie de ses index puis de ses triggers, comme sqlite3 .schema
+func (s *Shell) printSchema(table string) error {
+	query := `
+		SELECT sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'`
+	args := []interface{}{}
+	if table != "" {
+		query += ` AND tbl_name = ?`
+		args = append(args, table)
+	}
+	query += `
+		ORDER BY tbl_name,
+			CASE type WHEN 'table' THEN 0 WHEN 'view' THEN 1 WHEN 'index' THEN 2 ELSE 3 END,
+			name`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var ddl string
+		if err := rows.Scan(&ddl); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "%s;\n", strings.TrimSpace(ddl))
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if count == 0 && table != "" {
+		fmt.Fprintf(s.out, "No schema found for %s\n", table)
+	}
+	return nil
+}
+
 func (s *Shell) listDatabases() {
 	fmt.Fprintln(s.out, "Available databases:")
 	dbs := []string{
@@ -200,6 +356,9 @@ func (s *Shell) openDB(name string) error {
 		return fmt.Errorf("failed to open: %w", err)
 	}
 
+	// Une seule connexion: les PRAGMA s'appliquent à toute la session
+	db.SetMaxOpenConns(1)
+
 	// Appliquer les pragmas HOROS
 	pragmas := []string{
 		"PRAGMA journal_mode = WAL",
@@ -211,6 +370,11 @@ func (s *Shell) openDB(name string) error {
 
 	s.db = db
 	s.dbName = name
+
+	if err := s.applyReadOnly(); err != nil {
+		s.closeDB()
+		return fmt.Errorf("failed to set read-only mode: %w", err)
+	}
 	return nil
 }
 
@@ -223,6 +387,12 @@ func (s *Shell) closeDB() {
 }
 
 func (s *Shell) execAndPrint(query string) error {
+	if s.readOnly {
+		if err := checkReadOnly(query); err != nil {
+			return err
+		}
+	}
+
 	rows, err := s.db.Query(query)
 	if err != nil {
 		return err
@@ -240,10 +410,6 @@ func (s *Shell) execAndPrint(query string) error {
 		return nil
 	}
 
-	// Header
-	fmt.Fprintln(s.out, strings.Join(cols, " | "))
-	fmt.Fprintln(s.out, strings.Repeat("-", len(strings.Join(cols, " | "))))
-
 	// Rows
 	values := make([]interface{}, len(cols))
 	valuePtrs := make([]interface{}, len(cols))
@@ -251,7 +417,7 @@ func (s *Shell) execAndPrint(query string) error {
 		valuePtrs[i] = &values[i]
 	}
 
-	count := 0
+	var data [][]string
 	for rows.Next() {
 		if err := rows.Scan(valuePtrs...); err != nil {
 			return err
@@ -259,16 +425,82 @@ func (s *Shell) execAndPrint(query string) error {
 
 		var row []string
 		for _, v := range values {
-			if v == nil {
-				row = append(row, "NULL")
-			} else {
-				row = append(row, fmt.Sprintf("%v", v))
-			}
+			row = append(row, database.TextValue(v))
 		}
-		fmt.Fprintln(s.out, strings.Join(row, " | "))
-		count++
+		data = append(data, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	fmt.Fprintf(s.out, "(%d rows)\n", count)
+	if s.mode == modeColumn {
+		s.printColumns(cols, data)
+	} else {
+		s.printList(cols, data)
+	}
+
+	fmt.Fprintf(s.out, "(%d rows)\n", len(data))
 	return nil
 }
+
+// printList affiche les résultats séparés par " | " (mode list)
+func (s *Shell) printList(cols []string, data [][]string) {
+	header := strings.Join(cols, " | ")
+	fmt.Fprintln(s.out, header)
+	fmt.Fprintln(s.out, strings.Repeat("-", len(header)))
+	for _, row := range data {
+		fmt.Fprintln(s.out, strings.Join(row, " | "))
+	}
+}
+
+// printColumns affiche les résultats en colonnes alignées (mode column)
+// Les valeurs plus larges que maxWidth sont tronquées
+func (s *Shell) printColumns(cols []string, data [][]string) {
+	widths := make([]int, len(cols))
+	for i, c := range cols {
+		widths[i] = s.cellWidth(c)
+	}
+	for _, row := range data {
+		for i, v := range row {
+			if w := s.cellWidth(v); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	printRow := func(row []string) {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			v = s.truncateCell(v)
+			cells[i] = v + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v))
+		}
+		fmt.Fprintln(s.out, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	printRow(cols)
+	seps := make([]string, len(cols))
+	for i, w := range widths {
+		seps[i] = strings.Repeat("-", w)
+	}
+	fmt.Fprintln(s.out, strings.Join(seps, "  "))
+	for _, row := range data {
+		printRow(row)
+	}
+}
+
+// cellWidth retourne la largeur affichée d'une valeur après troncature
+func (s *Shell) cellWidth(v string) int {
+	return utf8.RuneCountInString(s.truncateCell(v))
+}
+
+// truncateCell aplatit les retours à la ligne et tronque à maxWidth
+func (s *Shell) truncateCell(v string) string {
+	v = strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ").Replace(v)
+	if s.maxWidth <= 0 || utf8.RuneCountInString(v) <= s.maxWidth {
+		return v
+	}
+	if s.maxWidth == 1 {
+		return "…"
+	}
+	return string([]rune(v)[:s.maxWidth-1]) + "…"
+}
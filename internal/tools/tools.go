@@ -6,6 +6,8 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"os"
 	"sync"
 	"time"
 )
@@ -26,21 +28,42 @@ type Tool struct {
 
 // ToolStep représente une étape d'exécution d'un tool
 type ToolStep struct {
-	Order        int
-	Name         string
-	StepType     string
-	SQLTemplate  string
-	ErrorHandler string
-	Condition    string
+	Order        int    `json:"order"`
+	Name         string `json:"name"`
+	StepType     string `json:"type"`
+	SQLTemplate  string `json:"sql"`
+	ErrorHandler string `json:"error_handler,omitempty"`
+	Condition    string `json:"condition,omitempty"`
 }
 
 // Manager gère le hot reload des tools
+// Un reload remplace la map entière: chaque *Tool est un snapshot immuable, une exécution
+// en cours garde les steps lus à son démarrage même si le tool est modifié ou supprimé entre-temps
 type Manager struct {
-	db          *sql.DB
-	tools       map[string]*Tool
-	mu          sync.RWMutex
-	stopChan    chan struct{}
-	reloadChan  chan struct{}
+	db         *sql.DB
+	tools      map[string]*Tool
+	mu         sync.RWMutex
+	stopChan   chan struct{}
+	reloadChan chan struct{}
+
+	inflight map[string]int  // Exécutions en cours par tool (Acquire/release)
+	removed  map[string]bool // Tools supprimés attendant la fin de leurs exécutions
+	onRemove func(name string)
+}
+
+// ReservedNames noms des tools intégrés (browser, brainloop), prioritaires au dispatch
+// Un tool SQL portant l'un de ces noms serait masqué et jamais appelable
+var ReservedNames = map[string]bool{
+	"browser":   true,
+	"brainloop": true,
+}
+
+// ValidateName refuse les noms de tools réservés aux tools intégrés
+func ValidateName(name string) error {
+	if ReservedNames[name] {
+		return fmt.Errorf("tool name %q is reserved for the built-in %s tool", name, name)
+	}
+	return nil
 }
 
 // NewManager crée un nouveau gestionnaire de tools
@@ -50,9 +73,19 @@ func NewManager(db *sql.DB) *Manager {
 		tools:      make(map[string]*Tool),
 		stopChan:   make(chan struct{}),
 		reloadChan: make(chan struct{}, 1),
+		inflight:   make(map[string]int),
+		removed:    make(map[string]bool),
 	}
 }
 
+// OnRemove enregistre fn, appelée pour chaque tool supprimé ou désactivé par un reload
+// une fois sa dernière exécution en cours terminée (nettoyage du circuit breaker, etc.)
+func (m *Manager) OnRemove(fn func(name string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onRemove = fn
+}
+
 // Start démarre le hot reload des tools
 func (m *Manager) Start(pollInterval time.Duration) error {
 	// Chargement initial
@@ -121,6 +154,11 @@ func (m *Manager) reload() error {
 		if err != nil {
 			return err
 		}
+		if err := ValidateName(t.Name); err != nil {
+			// Insertion directe en base: ignorer plutôt que masquer le tool intégré
+			fmt.Fprintf(os.Stderr, "[warn] tools: SQL tool ignored: %v\n", err)
+			continue
+		}
 		t.InputSchema = json.RawMessage(inputSchemaStr)
 		t.Enabled = enabled == 1
 
@@ -134,11 +172,31 @@ func (m *Manager) reload() error {
 		newTools[t.Name] = &t
 	}
 
-	// Swap atomique
+	// Swap atomique: aucun Acquire ne peut démarrer un tool absent de newTools
 	m.mu.Lock()
+	var gone []string
+	for name := range m.tools {
+		if _, kept := newTools[name]; kept {
+			continue
+		}
+		if m.inflight[name] > 0 {
+			m.removed[name] = true // Notifié au dernier release
+		} else {
+			gone = append(gone, name)
+		}
+	}
+	for name := range newTools {
+		delete(m.removed, name) // Recréé avant la fin de ses exécutions
+	}
 	m.tools = newTools
+	onRemove := m.onRemove
 	m.mu.Unlock()
 
+	if onRemove != nil {
+		for _, name := range gone {
+			onRemove(name)
+		}
+	}
 	return nil
 }
 
@@ -167,7 +225,13 @@ func (m *Manager) loadSteps(toolName string) ([]ToolStep, error) {
 	return steps, nil
 }
 
-// Get retourne un tool par son nom
+// Load charge les tools une fois, sans démarrer le hot reload (usage CLI)
+func (m *Manager) Load() error {
+	return m.reload()
+}
+
+// Get retourne un tool par son nom (snapshot, voir Manager)
+// Pour l'exécuter, préférer Acquire qui suit les exécutions en cours
 func (m *Manager) Get(name string) (*Tool, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -176,6 +240,41 @@ func (m *Manager) Get(name string) (*Tool, bool) {
 	return t, ok
 }
 
+// Acquire retourne le snapshot courant du tool name et le marque en cours d'exécution
+// release doit être appelé à la fin de l'exécution; un tool retiré par un reload n'est plus acquis
+func (m *Manager) Acquire(name string) (tool *Tool, release func(), ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tool, ok = m.tools[name]
+	if !ok {
+		return nil, nil, false
+	}
+	m.inflight[name]++
+
+	var once sync.Once
+	return tool, func() { once.Do(func() { m.release(name) }) }, true
+}
+
+// release termine une exécution; notifie la suppression si c'était la dernière d'un tool retiré
+func (m *Manager) release(name string) {
+	m.mu.Lock()
+	m.inflight[name]--
+	if m.inflight[name] > 0 {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.inflight, name)
+	notify := m.removed[name]
+	delete(m.removed, name)
+	onRemove := m.onRemove
+	m.mu.Unlock()
+
+	if notify && onRemove != nil {
+		onRemove(name)
+	}
+}
+
 // List retourne la liste de tous les tools
 func (m *Manager) List() []*Tool {
 	m.mu.RLock()
@@ -236,24 +335,228 @@ func HashParams(toolName string, params map[string]interface{}) string {
 
 // CreateTool crée un nouveau tool dans la base (pour LLM)
 func (m *Manager) CreateTool(name, description string, inputSchema json.RawMessage, category string) error {
-	_, err := m.db.Exec(`
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
 		INSERT INTO tool_definitions
 		(name, description, input_schema, category, created_by, created_at, updated_at)
 		VALUES (?, ?, ?, ?, 'llm', strftime('%s', 'now'), strftime('%s', 'now'))`,
-		name, description, string(inputSchema), category)
-	return err
+		name, description, string(inputSchema), category); err != nil {
+		return err
+	}
+	if err := SnapshotVersion(tx, name, "llm", "create"); err != nil {
+		return fmt.Errorf("snapshot version: %w", err)
+	}
+	return tx.Commit()
 }
 
-// AddToolStep ajoute une étape à un tool
+// AddToolStep ajoute une étape à un tool (le snapshot de sa version courante est mis à jour)
 func (m *Manager) AddToolStep(toolName string, stepOrder int, stepName, stepType, sqlTemplate string) error {
-	_, err := m.db.Exec(`
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
 		INSERT INTO tool_implementations
 		(tool_name, step_order, step_name, step_type, sql_template)
 		VALUES (?, ?, ?, ?, ?)`,
-		toolName, stepOrder, stepName, stepType, sqlTemplate)
+		toolName, stepOrder, stepName, stepType, sqlTemplate); err != nil {
+		return err
+	}
+	if err := SnapshotVersion(tx, toolName, "llm", "add_step"); err != nil {
+		return fmt.Errorf("snapshot version: %w", err)
+	}
+	return tx.Commit()
+}
+
+// StepDef décrit une étape fournie à UpsertTool (l'ordre suit la position dans la liste)
+type StepDef struct {
+	Name        string `json:"name"`
+	StepType    string `json:"type"`
+	SQLTemplate string `json:"sql"`
+}
+
+// UpsertTool crée ou remplace un tool et ses étapes de façon atomique
+// Les étapes existantes sont supprimées puis réinsérées; le reload est déclenché immédiatement
+func (m *Manager) UpsertTool(name, description string, inputSchema json.RawMessage, category, createdBy string, steps []StepDef) (bool, error) {
+	created, err := UpsertTool(m.db, name, description, inputSchema, category, createdBy, steps)
+	if err != nil {
+		return false, err
+	}
+	m.ForceReload()
+	return created, nil
+}
+
+// UpsertTool crée ou remplace un tool directement sur une base lifecycle-tools
+// Retourne true si le tool n'existait pas. Les triggers marquent hot_reload_flag
+func UpsertTool(db *sql.DB, name, description string, inputSchema json.RawMessage, category, createdBy string, steps []StepDef) (bool, error) {
+	if name == "" || description == "" {
+		return false, fmt.Errorf("name and description are required")
+	}
+	if err := ValidateName(name); err != nil {
+		return false, err
+	}
+	if len(inputSchema) == 0 {
+		inputSchema = json.RawMessage(`{}`)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM tool_definitions WHERE name = ?`, name).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	// Upsert sans DELETE: created_at est conservé, la version incrémentée
+	_, err = tx.Exec(`
+		INSERT INTO tool_definitions
+		(name, description, input_schema, category, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now'))
+		ON CONFLICT(name) DO UPDATE SET
+			description = excluded.description,
+			input_schema = excluded.input_schema,
+			category = excluded.category,
+			version = version + 1,
+			updated_at = excluded.updated_at`,
+		name, description, string(inputSchema), category, createdBy)
+	if err != nil {
+		return false, fmt.Errorf("upsert tool definition: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM tool_implementations WHERE tool_name = ?`, name); err != nil {
+		return false, fmt.Errorf("delete old steps: %w", err)
+	}
+
+	for i, step := range steps {
+		stepName := step.Name
+		if stepName == "" {
+			stepName = fmt.Sprintf("step_%d", i+1)
+		}
+		stepType := step.StepType
+		if stepType == "" {
+			stepType = "sql"
+		}
+		_, err := tx.Exec(`
+			INSERT INTO tool_implementations
+			(tool_name, step_order, step_name, step_type, sql_template)
+			VALUES (?, ?, ?, ?, ?)`,
+			name, i+1, stepName, stepType, step.SQLTemplate)
+		if err != nil {
+			return false, fmt.Errorf("insert step %d: %w", i+1, err)
+		}
+	}
+
+	// Historiser la version écrite pour permettre les appels épinglés (_version)
+	if err := SnapshotVersion(tx, name, createdBy, "upsert"); err != nil {
+		return false, fmt.Errorf("snapshot version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return exists == 0, nil
+}
+
+// SnapshotVersion enregistre la définition et les étapes courantes dans tool_versioning
+// À appeler dans la transaction de chaque écriture d'un tool: la version courante est remplacée,
+// ce qui permet d'épingler (_version) toute version même après un upsert ultérieur
+func SnapshotVersion(tx *sql.Tx, name, changedBy, reason string) error {
+	var t Tool
+	var enabled int
+	var inputSchemaStr string
+	err := tx.QueryRow(`
+		SELECT name, description, input_schema, COALESCE(category, ''), version,
+		       enabled, timeout_seconds, COALESCE(retry_policy, ''), COALESCE(max_retries, 0)
+		FROM tool_definitions
+		WHERE name = ?`, name).Scan(
+		&t.Name, &t.Description, &inputSchemaStr, &t.Category,
+		&t.Version, &enabled, &t.TimeoutSecs, &t.RetryPolicy, &t.MaxRetries)
+	if err != nil {
+		return err
+	}
+	t.InputSchema = json.RawMessage(inputSchemaStr)
+	t.Enabled = enabled == 1
+
+	rows, err := tx.Query(`
+		SELECT step_order, step_name, step_type, sql_template,
+		       COALESCE(error_handler, ''), COALESCE(condition, '')
+		FROM tool_implementations
+		WHERE tool_name = ?
+		ORDER BY step_order`, name)
+	if err != nil {
+		return err
+	}
+	steps := []ToolStep{}
+	for rows.Next() {
+		var s ToolStep
+		if err := rows.Scan(&s.Order, &s.Name, &s.StepType, &s.SQLTemplate, &s.ErrorHandler, &s.Condition); err != nil {
+			rows.Close()
+			return err
+		}
+		steps = append(steps, s)
+	}
+	rows.Close()
+
+	definition, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	implementation, err := json.Marshal(steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO tool_versioning
+		(tool_name, version, definition_snapshot, implementation_snapshot, change_reason, changed_by)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		name, t.Version, string(definition), string(implementation), reason, changedBy)
 	return err
 }
 
+// GetVersion retourne un tool tel qu'il était à une version donnée
+// La version courante est servie depuis le cache, les autres depuis tool_versioning
+func (m *Manager) GetVersion(name string, version int) (*Tool, error) {
+	if current, ok := m.Get(name); ok && current.Version == version {
+		return current, nil
+	}
+
+	var definition, implementation string
+	err := m.db.QueryRow(`
+		SELECT definition_snapshot, implementation_snapshot
+		FROM tool_versioning
+		WHERE tool_name = ? AND version = ?`, name, version).Scan(&definition, &implementation)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tool %s has no recorded version %d", name, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t Tool
+	if err := json.Unmarshal([]byte(definition), &t); err != nil {
+		return nil, fmt.Errorf("invalid definition snapshot: %w", err)
+	}
+	if err := json.Unmarshal([]byte(implementation), &t.Steps); err != nil {
+		return nil, fmt.Errorf("invalid implementation snapshot: %w", err)
+	}
+	t.Version = version
+	return &t, nil
+}
+
 // DetectPatterns détecte les patterns d'action répétitifs
 func (m *Manager) DetectPatterns(db *sql.DB) error {
 	// Query de détection avec window function
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestManager ouvre une base lifecycle-tools en mémoire avec le schéma du dépôt
+func newTestManager(t *testing.T) (*Manager, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=memory&cache=shared", filepath.Base(t.Name())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// La base en mémoire disparaît avec sa dernière connexion: en garder une ouverte
+	pin, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pin.Close() })
+
+	schema, err := os.ReadFile(filepath.Join("..", "..", "schemas", "lifecycle-tools.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	return NewManager(db), db
+}
+
+// addTool crée un tool à un step
+func addTool(t *testing.T, m *Manager, name, sqlTemplate string) {
+	t.Helper()
+	if err := m.CreateTool(name, "test tool", []byte(`{"type":"object"}`), "data"); err != nil {
+		t.Fatalf("create %s: %v", name, err)
+	}
+	if err := m.AddToolStep(name, 1, "main", "sql", sqlTemplate); err != nil {
+		t.Fatalf("step %s: %v", name, err)
+	}
+}
+
+// removeRecorder compte les appels OnRemove par tool
+type removeRecorder struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func recordRemovals(m *Manager) *removeRecorder {
+	r := &removeRecorder{calls: map[string]int{}}
+	m.OnRemove(func(name string) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls[name]++
+	})
+	return r
+}
+
+func (r *removeRecorder) count(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[name]
+}
+
+func TestRemoveDeferredUntilRelease(t *testing.T) {
+	m, db := newTestManager(t)
+	removals := recordRemovals(m)
+	addTool(t, m, "held", "SELECT 1")
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tool, release, ok := m.Acquire("held")
+	if !ok {
+		t.Fatal("Acquire(held) failed")
+	}
+
+	if _, err := db.Exec(`DELETE FROM tool_definitions WHERE name = 'held'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := removals.count("held"); n != 0 {
+		t.Fatalf("OnRemove called %d times while the tool is held, want 0", n)
+	}
+	if _, _, ok := m.Acquire("held"); ok {
+		t.Error("removed tool can still be acquired")
+	}
+	if len(tool.Steps) != 1 || tool.Steps[0].SQLTemplate != "SELECT 1" {
+		t.Errorf("held snapshot steps = %+v, want the steps read at Acquire", tool.Steps)
+	}
+
+	release()
+	if n := removals.count("held"); n != 1 {
+		t.Fatalf("OnRemove called %d times after release, want 1", n)
+	}
+	release() // Idempotent
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if n := removals.count("held"); n != 1 {
+		t.Errorf("OnRemove called %d times after a second release and reload, want 1", n)
+	}
+}
+
+func TestRemoveWaitsForLastRelease(t *testing.T) {
+	m, db := newTestManager(t)
+	removals := recordRemovals(m)
+	addTool(t, m, "shared", "SELECT 1")
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, releaseA, _ := m.Acquire("shared")
+	_, releaseB, _ := m.Acquire("shared")
+	if _, err := db.Exec(`UPDATE tool_definitions SET enabled = 0 WHERE name = 'shared'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	releaseA()
+	if n := removals.count("shared"); n != 0 {
+		t.Fatalf("OnRemove called %d times with one call still running, want 0", n)
+	}
+	releaseB()
+	if n := removals.count("shared"); n != 1 {
+		t.Errorf("OnRemove called %d times after the last release, want 1", n)
+	}
+}
+
+func TestReloadWhileHeld(t *testing.T) {
+	m, db := newTestManager(t)
+	removals := recordRemovals(m)
+	addTool(t, m, "edited", "SELECT 1")
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tool, release, _ := m.Acquire("edited")
+	if _, err := db.Exec(`UPDATE tool_implementations SET sql_template = 'SELECT 2' WHERE tool_name = 'edited'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if tool.Steps[0].SQLTemplate != "SELECT 1" {
+		t.Errorf("held snapshot changed by reload: %q", tool.Steps[0].SQLTemplate)
+	}
+	if current, ok := m.Get("edited"); !ok || current.Steps[0].SQLTemplate != "SELECT 2" {
+		t.Errorf("reloaded tool = %+v, want the new step", current)
+	}
+	release()
+	if n := removals.count("edited"); n != 0 {
+		t.Errorf("OnRemove called %d times for a tool that still exists, want 0", n)
+	}
+}
+
+func TestRemoveRecreatedBeforeRelease(t *testing.T) {
+	m, db := newTestManager(t)
+	removals := recordRemovals(m)
+	addTool(t, m, "phoenix", "SELECT 1")
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, release, _ := m.Acquire("phoenix")
+	if _, err := db.Exec(`DELETE FROM tool_definitions WHERE name = 'phoenix'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	addTool(t, m, "phoenix", "SELECT 2")
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	release()
+	if n := removals.count("phoenix"); n != 0 {
+		t.Errorf("OnRemove called %d times for a tool recreated before release, want 0", n)
+	}
+}
+
+func TestRemoveWithoutInflightNotifiesImmediately(t *testing.T) {
+	m, db := newTestManager(t)
+	removals := recordRemovals(m)
+	addTool(t, m, "idle", "SELECT 1")
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM tool_definitions WHERE name = 'idle'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if n := removals.count("idle"); n != 1 {
+		t.Errorf("OnRemove called %d times, want 1", n)
+	}
+}
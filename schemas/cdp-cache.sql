@@ -61,6 +61,23 @@ CREATE TABLE IF NOT EXISTS cdp_session_state (
     updated_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
 );
 
+-- Table: cdp_commands
+-- File de commandes CDP insérées depuis SQL et traitées par cdpProcessLoop
+CREATE TABLE IF NOT EXISTS cdp_commands (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    method TEXT NOT NULL,                   -- Méthode CDP (ex: Page.navigate)
+    params TEXT DEFAULT '{}',               -- JSON des paramètres
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK(status IN ('pending', 'success', 'error', 'aborted')),
+    result TEXT,                            -- JSON du résultat
+    error TEXT,                             -- Message d'erreur
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+    processed_at INTEGER                    -- Timestamp de traitement
+);
+
+CREATE INDEX IF NOT EXISTS idx_cdp_commands_status_id ON cdp_commands(status, id);
+CREATE INDEX IF NOT EXISTS idx_cdp_commands_processed ON cdp_commands(processed_at);
+
 -- Initialiser avec une ligne par défaut
 INSERT OR IGNORE INTO cdp_session_state (id, connected) VALUES (1, 0);
 
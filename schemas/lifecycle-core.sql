@@ -31,9 +31,29 @@ INSERT OR IGNORE INTO config (key, value, value_type, description) VALUES
     ('polling.interval_ms', '2000', 'number', 'Intervalle hot reload tools'),
     ('heartbeat.interval_seconds', '15', 'number', 'Intervalle heartbeat'),
     ('shutdown.timeout_seconds', '60', 'number', 'Timeout graceful shutdown'),
+    ('server.safe_mode', 'false', 'boolean', 'Mode restreint: tool browser et génération LLM désactivés (surchargeable par HOLOW_MCP_SAFE_MODE)'),
+    ('server.idle_shutdown_seconds', '0', 'number', 'Arrêt gracieux après ce délai sans requête MCP, pour les hébergements à la demande (0 = jamais)'),
+    ('server.max_tool_wall_time_seconds', '120', 'number', 'Durée max d''un tools/call complet (0 = illimité)'),
+    ('cdp.commands_retention_seconds', '3600', 'number', 'Durée de conservation des cdp_commands traitées (0 = illimité)'),
+    ('cdp.max_result_bytes', '1048576', 'number', 'Taille max d''un résultat cdp_call; au-delà, enveloppe {truncated, size, path, preview} (0 = illimité)'),
+    ('cdp.max_pending_commands', '1000', 'number', 'Nombre max de cdp_commands en attente; au-delà, l''INSERT échoue (0 = illimité)'),
+    ('cdp.spill_large_results', 'true', 'boolean', 'Copier les résultats cdp_call tronqués en entier dans cdp-results/ du chemin de base (chemin dans path)'),
+    ('idempotence.enabled', 'true', 'boolean', 'Déduplication des requêtes déjà traitées (surchargeable par HOLOW_MCP_IDEMPOTENCE)'),
+    ('idempotence.retention_seconds', '604800', 'number', 'Durée de conservation de processed_log (0 = illimité)'),
+    ('idempotence.max_rows', '100000', 'number', 'Nombre max d''entrées processed_log conservées (0 = illimité)'),
+    ('telemetry.log_level', 'info', 'string', 'Niveau minimal écrit dans telemetry_logs (debug, info, warn, error)'),
+    ('telemetry.logs_retention_seconds', '604800', 'number', 'Durée de conservation de telemetry_logs (0 = illimité)'),
     ('cache.default_ttl_seconds', '3600', 'number', 'TTL cache par défaut'),
     ('retry.max_attempts', '3', 'number', 'Nombre max retries'),
-    ('circuit_breaker.failure_threshold', '5', 'number', 'Seuil échecs circuit breaker');
+    ('retry.base_delay_ms', '200', 'number', 'Délai avant le premier retry en processus d''un tool (fixed: constant, exponential: doublé, plafonné à 5 s)'),
+    ('circuit_breaker.failure_threshold', '5', 'number', 'Seuil échecs circuit breaker'),
+    ('db.pragmas', '', 'json', 'Surcharges de pragmas par base {input|lifecycle-tools|lifecycle-execution|lifecycle-core|output|metadata: {busy_timeout, wal_autocheckpoint}} (vide = 5000 ms et 10000 pages partout)'),
+    ('disk.min_free_mb', '500', 'number', 'Seuil d''alerte espace disque libre (Mo)'),
+    ('disk.poison_pill_on_low', 'false', 'boolean', 'Arrêt gracieux si espace disque sous le seuil'),
+    ('brainloop.secret_patterns', '', 'string', 'Motifs de secrets additionnels pour read_config (séparés par des virgules)'),
+    ('llm.provider_order', 'claude,gemini,cerebras', 'string', 'Ordre de repli des providers LLM (séparés par des virgules)'),
+    ('llm.prices', '', 'json', 'Tarifs LLM en USD par million de tokens {provider: {prompt, completion}} (vide = tarifs par défaut)'),
+    ('templates.env_allowlist', '', 'string', 'Variables d''environnement autorisées dans {{env:NAME}} (séparées par des virgules)');
 
 -- ============================================================================
 -- Table 2: ego_index - 15 dimensions documentées ⭐
@@ -26,6 +26,8 @@ CREATE TABLE IF NOT EXISTS processed_log (
 
 CREATE INDEX idx_processed_log_tool ON processed_log(tool_name, created_at DESC);
 CREATE INDEX idx_processed_log_request ON processed_log(request_id);
+-- Lookup CheckProcessed: index de la clé primaire (hash); purge par ancienneté: index created_at
+CREATE INDEX IF NOT EXISTS idx_processed_log_created ON processed_log(created_at);
 
 -- ============================================================================
 -- Table 2: retry_queue - Queue retry avec backoff exponentiel
@@ -0,0 +1,2 @@
+-- Purge de processed_log par ancienneté (idempotence.retention_seconds)
+CREATE INDEX IF NOT EXISTS idx_processed_log_created ON processed_log(created_at);
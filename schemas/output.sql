@@ -1,5 +1,5 @@
 -- ============================================================================
--- HOLOW-MCP: output.db Schema (10 tables)
+-- HOLOW-MCP: output.db Schema (11 tables)
 -- Résultats, heartbeat, métriques, audit
 -- ============================================================================
 
@@ -215,3 +215,19 @@ CREATE TABLE IF NOT EXISTS export_queue (
 );
 
 CREATE INDEX idx_export_queue_status ON export_queue(status, created_at);
+
+-- ============================================================================
+-- Table 11: llm_usage - Tokens et coût estimé des appels LLM
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,                 -- Provider ayant servi l'appel
+    model TEXT,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0,       -- Estimation (table llm.prices de config)
+    duration_ms INTEGER,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_created ON llm_usage(created_at, provider);